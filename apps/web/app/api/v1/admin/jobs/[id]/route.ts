@@ -75,7 +75,16 @@ export async function PATCH(request: NextRequest, { params }: { params: Promise<
     };
 
     if (status) {
-      const validStatuses = ['pending', 'running', 'completed', 'failed', 'cancelled'];
+      const validStatuses = [
+        'pending',
+        'running',
+        'completed',
+        'partial',
+        'rate_limited',
+        'upstream_unavailable',
+        'failed',
+        'cancelled',
+      ];
       if (!validStatuses.includes(status)) {
         return NextResponse.json(
           { error: `Invalid status. Must be one of: ${validStatuses.join(', ')}` },
@@ -88,7 +97,7 @@ export async function PATCH(request: NextRequest, { params }: { params: Promise<
       if (status === 'running' && !existingJob.startedAt) {
         updateData.startedAt = new Date();
       }
-      if (['completed', 'failed', 'cancelled'].includes(status) && !existingJob.completedAt) {
+      if (status !== 'pending' && status !== 'running' && !existingJob.completedAt) {
         updateData.completedAt = new Date();
       }
     }
@@ -1,5 +1,5 @@
 import { getDb } from '@dealforge/database';
-import { jobs } from '@dealforge/database/schema';
+import { type JobStatus, jobs } from '@dealforge/database/schema';
 import { desc, eq } from 'drizzle-orm';
 import { type NextRequest, NextResponse } from 'next/server';
 
@@ -33,7 +33,7 @@ export async function GET(request: NextRequest) {
     // Apply filters
     if (status) {
       query = query.where(
-        eq(jobs.status, status as 'pending' | 'running' | 'completed' | 'failed' | 'cancelled')
+        eq(jobs.status, status as JobStatus)
       ) as typeof query;
     }
     if (type) {
@@ -46,6 +46,9 @@ export async function GET(request: NextRequest) {
             | 'discover_parks'
             | 'calculate_distress'
             | 'csv_import'
+            | 'hud_sync'
+            | 'census_sync'
+            | 'bls_sync'
         )
       ) as typeof query;
     }
@@ -92,6 +95,9 @@ export async function POST(request: NextRequest) {
       'discover_parks',
       'calculate_distress',
       'csv_import',
+      'hud_sync',
+      'census_sync',
+      'bls_sync',
     ];
     if (!validTypes.includes(type)) {
       return NextResponse.json(
@@ -109,7 +115,10 @@ export async function POST(request: NextRequest) {
           | 'tdhca_liens_sync'
           | 'discover_parks'
           | 'calculate_distress'
-          | 'csv_import',
+          | 'csv_import'
+          | 'hud_sync'
+          | 'census_sync'
+          | 'bls_sync',
         status: 'pending',
         parameters: parameters || null,
         createdBy: session.user.id,
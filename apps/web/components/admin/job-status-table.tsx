@@ -16,7 +16,15 @@ import {
 interface Job {
   id: string;
   type: string;
-  status: 'pending' | 'running' | 'completed' | 'failed' | 'cancelled';
+  status:
+    | 'pending'
+    | 'running'
+    | 'completed'
+    | 'partial'
+    | 'rate_limited'
+    | 'upstream_unavailable'
+    | 'failed'
+    | 'cancelled';
   parameters: Record<string, unknown> | null;
   result: Record<string, unknown> | null;
   errorMessage: string | null;
@@ -45,6 +53,21 @@ const statusConfig = {
     variant: 'default' as const,
     icon: CheckCircle2,
   },
+  partial: {
+    label: 'Partial',
+    variant: 'secondary' as const,
+    icon: AlertCircle,
+  },
+  rate_limited: {
+    label: 'Rate Limited',
+    variant: 'secondary' as const,
+    icon: AlertCircle,
+  },
+  upstream_unavailable: {
+    label: 'Upstream Unavailable',
+    variant: 'secondary' as const,
+    icon: AlertCircle,
+  },
   failed: {
     label: 'Failed',
     variant: 'destructive' as const,
@@ -63,6 +86,9 @@ const typeLabels: Record<string, string> = {
   discover_parks: 'Discover Parks',
   calculate_distress: 'Calculate Distress',
   csv_import: 'CSV Import',
+  hud_sync: 'HUD FMR Sync',
+  census_sync: 'Census ACS Sync',
+  bls_sync: 'BLS LAUS Sync',
 };
 
 function formatDate(dateString: string | null): string {
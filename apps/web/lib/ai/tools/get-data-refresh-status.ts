@@ -42,6 +42,9 @@ const statusDescriptions: Record<string, string> = {
   pending: 'Waiting to be processed',
   running: 'Currently being processed',
   completed: 'Successfully completed',
+  partial: 'Finished, but some areas failed to sync',
+  rate_limited: 'Stopped early by the upstream API quota',
+  upstream_unavailable: 'Stopped early by an upstream API outage',
   failed: 'Failed with an error',
   cancelled: 'Cancelled by admin',
 };
@@ -0,0 +1,22 @@
+-- Give Small Area FMR rows their own uniqueness key
+-- Entity-level rows (metro/county) store an empty zip_code, ZIP-level rows store the ZIP,
+-- so (entity_code, zip_code, fiscal_year) identifies both without ZIP rows overwriting each other
+
+-- Normalize legacy entity-level rows so the unique index covers them
+UPDATE "hud_fair_market_rents" SET "zip_code" = '' WHERE "zip_code" IS NULL;
+
+--> statement-breakpoint
+
+ALTER TABLE "hud_fair_market_rents" ALTER COLUMN "zip_code" SET DEFAULT '';
+
+--> statement-breakpoint
+
+ALTER TABLE "hud_fair_market_rents" ALTER COLUMN "zip_code" SET NOT NULL;
+
+--> statement-breakpoint
+
+DROP INDEX IF EXISTS "hfr_entity_fiscal_year_idx";
+
+--> statement-breakpoint
+
+CREATE UNIQUE INDEX IF NOT EXISTS "hfr_entity_zip_fiscal_year_idx" ON "hud_fair_market_rents" USING btree ("entity_code", "zip_code", "fiscal_year");
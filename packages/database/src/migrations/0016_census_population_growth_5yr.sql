@@ -0,0 +1,2 @@
+-- Add 5-year population growth (CAGR) alongside the year-over-year rate
+ALTER TABLE "census_demographics" ADD COLUMN IF NOT EXISTS "population_growth_rate_5yr" real;
@@ -0,0 +1,9 @@
+-- Include geo_type in the census_demographics uniqueness key
+-- ZCTA codes overlap county GEOIDs (e.g., ZCTA 48201 vs Harris County, TX 48201),
+-- so tract and ZCTA rows must not conflict with county rows
+
+DROP INDEX IF EXISTS "cen_geo_survey_year_idx";
+
+--> statement-breakpoint
+
+CREATE UNIQUE INDEX IF NOT EXISTS "cen_geo_type_geo_survey_year_idx" ON "census_demographics" USING btree ("geo_type", "geo_id", "survey_year");
@@ -0,0 +1,4 @@
+-- Job types consumed by the data-sync worker (services/data-sync)
+ALTER TYPE "public"."job_type" ADD VALUE IF NOT EXISTS 'hud_sync';--> statement-breakpoint
+ALTER TYPE "public"."job_type" ADD VALUE IF NOT EXISTS 'census_sync';--> statement-breakpoint
+ALTER TYPE "public"."job_type" ADD VALUE IF NOT EXISTS 'bls_sync';
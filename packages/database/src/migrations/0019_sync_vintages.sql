@@ -0,0 +1,10 @@
+-- Latest upstream vintage synced per source and set of states, used by the
+-- data-sync scheduler to skip runs when nothing has changed upstream
+CREATE TABLE IF NOT EXISTS "sync_vintages" (
+	"source" text NOT NULL,
+	"scope" text NOT NULL,
+	"vintage" text NOT NULL,
+	"synced_at" timestamp with time zone DEFAULT now() NOT NULL
+);
+--> statement-breakpoint
+CREATE UNIQUE INDEX IF NOT EXISTS "sync_vintage_source_scope_idx" ON "sync_vintages" USING btree ("source", "scope");
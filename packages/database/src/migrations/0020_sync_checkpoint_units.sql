@@ -0,0 +1,15 @@
+-- Per-unit status of each sync session, so resumes process exactly the
+-- pending and failed units even when units finish out of order
+CREATE TABLE IF NOT EXISTS "sync_checkpoint_units" (
+	"id" text PRIMARY KEY NOT NULL,
+	"sync_session_id" text NOT NULL,
+	"unit_key" text NOT NULL,
+	"status" text DEFAULT 'pending' NOT NULL,
+	"attempts" integer DEFAULT 0 NOT NULL,
+	"last_error" text,
+	"record_count" integer DEFAULT 0 NOT NULL,
+	"updated_at" timestamp with time zone DEFAULT now() NOT NULL
+);
+--> statement-breakpoint
+CREATE UNIQUE INDEX IF NOT EXISTS "sync_cku_session_unit_idx" ON "sync_checkpoint_units" USING btree ("sync_session_id", "unit_key");--> statement-breakpoint
+CREATE INDEX IF NOT EXISTS "sync_cku_session_status_idx" ON "sync_checkpoint_units" USING btree ("sync_session_id", "status");
@@ -0,0 +1,4 @@
+-- Year range each sync session was started with, so a session is never
+-- resumed with a different one
+ALTER TABLE "sync_checkpoints" ADD COLUMN IF NOT EXISTS "start_year" integer;--> statement-breakpoint
+ALTER TABLE "sync_checkpoints" ADD COLUMN IF NOT EXISTS "end_year" integer;
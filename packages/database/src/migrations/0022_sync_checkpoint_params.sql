@@ -0,0 +1,2 @@
+-- Run parameters each sync session was started with, restored on resume
+ALTER TABLE "sync_checkpoints" ADD COLUMN IF NOT EXISTS "params" jsonb;
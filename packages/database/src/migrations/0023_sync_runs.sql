@@ -0,0 +1,20 @@
+-- Ledger of data-sync runs with their parameters, counts, errors and
+-- outcome, the authoritative record of when each dataset last refreshed
+CREATE TABLE IF NOT EXISTS "sync_runs" (
+	"id" text PRIMARY KEY NOT NULL,
+	"source" text NOT NULL,
+	"sync_session_id" text,
+	"status" text NOT NULL,
+	"params" jsonb,
+	"vintage" text,
+	"successful" integer DEFAULT 0 NOT NULL,
+	"failed" integer DEFAULT 0 NOT NULL,
+	"skipped" integer DEFAULT 0 NOT NULL,
+	"errors" jsonb,
+	"error_message" text,
+	"started_at" timestamp with time zone NOT NULL,
+	"finished_at" timestamp with time zone NOT NULL
+);
+--> statement-breakpoint
+CREATE INDEX IF NOT EXISTS "sync_runs_source_finished_idx" ON "sync_runs" USING btree ("source", "finished_at");--> statement-breakpoint
+CREATE INDEX IF NOT EXISTS "sync_runs_status_idx" ON "sync_runs" USING btree ("status");
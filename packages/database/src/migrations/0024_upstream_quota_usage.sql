@@ -0,0 +1,10 @@
+-- Requests made against each upstream API's daily quota, so that every run
+-- on the same day shares one budget
+CREATE TABLE IF NOT EXISTS "upstream_quota_usage" (
+	"source" text NOT NULL,
+	"day" date NOT NULL,
+	"requests" integer DEFAULT 0 NOT NULL,
+	"updated_at" timestamp with time zone DEFAULT now() NOT NULL
+);
+--> statement-breakpoint
+CREATE UNIQUE INDEX IF NOT EXISTS "upstream_quota_source_day_idx" ON "upstream_quota_usage" USING btree ("source", "day");
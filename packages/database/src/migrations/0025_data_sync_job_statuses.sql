@@ -0,0 +1,5 @@
+-- Outcomes of data-sync jobs (services/data-sync) that finished without
+-- syncing every unit, matching the sync_runs ledger statuses
+ALTER TYPE "public"."job_status" ADD VALUE IF NOT EXISTS 'partial';--> statement-breakpoint
+ALTER TYPE "public"."job_status" ADD VALUE IF NOT EXISTS 'rate_limited';--> statement-breakpoint
+ALTER TYPE "public"."job_status" ADD VALUE IF NOT EXISTS 'upstream_unavailable';
@@ -10,6 +10,9 @@ export const jobStatusEnum = pgEnum('job_status', [
   'pending',
   'running',
   'completed',
+  'partial', // Data-sync jobs: finished, but some units failed
+  'rate_limited', // Data-sync jobs: stopped early by the upstream quota
+  'upstream_unavailable', // Data-sync jobs: stopped early by an upstream outage
   'failed',
   'cancelled',
 ]);
@@ -23,6 +26,9 @@ export const jobTypeEnum = pgEnum('job_type', [
   'discover_parks',
   'calculate_distress',
   'csv_import',
+  'hud_sync',
+  'census_sync',
+  'bls_sync',
 ]);
 
 /**
@@ -1,4 +1,4 @@
-import { index, integer, pgTable, real, text, timestamp, uniqueIndex } from 'drizzle-orm/pg-core';
+import { date, index, integer, jsonb, pgTable, real, text, timestamp, uniqueIndex } from 'drizzle-orm/pg-core';
 import { createId } from '@paralleldrive/cuid2';
 
 /**
@@ -20,8 +20,8 @@ export const hudFairMarketRents = pgTable(
       .$defaultFn(() => `hfr_${createId()}`),
     // Entity code from HUD (e.g., 'METRO10180M10180', 'COUNTY48001')
     entityCode: text('entity_code'),
-    // ZIP code (only populated for Small Area FMR records)
-    zipCode: text('zip_code'),
+    // ZIP code (empty for entity-level records, populated for Small Area FMR records)
+    zipCode: text('zip_code').notNull().default(''),
     countyName: text('county_name'),
     metroName: text('metro_name'),
     stateName: text('state_name'),
@@ -41,8 +41,12 @@ export const hudFairMarketRents = pgTable(
     updatedAt: timestamp('updated_at', { withTimezone: true }).notNull().defaultNow(),
   },
   (table) => [
-    // Entity-level records (metro/county without ZIP)
-    uniqueIndex('hfr_entity_fiscal_year_idx').on(table.entityCode, table.fiscalYear),
+    // One row per entity and year, plus one per ZIP for Small Area FMR metros
+    uniqueIndex('hfr_entity_zip_fiscal_year_idx').on(
+      table.entityCode,
+      table.zipCode,
+      table.fiscalYear
+    ),
     // ZIP-level records (Small Area FMRs)
     index('hfr_zip_fiscal_year_idx').on(table.zipCode, table.fiscalYear),
     index('hfr_county_name_idx').on(table.countyName),
@@ -55,7 +59,8 @@ export const hudFairMarketRents = pgTable(
  * Census Demographics table
  *
  * Stores American Community Survey (ACS) 5-year estimates.
- * County-level demographic, income, and housing data for market analysis.
+ * County-level demographic, income, and housing data for market analysis,
+ * plus tract and ZCTA rows for the areas around specific parks.
  */
 export const censusDemographics = pgTable(
   'census_demographics',
@@ -64,7 +69,8 @@ export const censusDemographics = pgTable(
       .primaryKey()
       .$defaultFn(() => `cen_${createId()}`),
     // Geography identifiers
-    geoId: text('geo_id').notNull(), // FIPS code (e.g., "48029" for Bexar County, TX)
+    // GEOID: "48029" for Bexar County, TX; 11 digits for tracts; the ZCTA for ZCTAs
+    geoId: text('geo_id').notNull(),
     geoType: text('geo_type').notNull(), // 'county', 'tract', 'zcta'
     geoName: text('geo_name').notNull(), // "Bexar County, Texas"
     stateCode: text('state_code'), // "48"
@@ -74,6 +80,7 @@ export const censusDemographics = pgTable(
     // Population metrics
     totalPopulation: integer('total_population'),
     populationGrowthRate: real('population_growth_rate'), // YoY % change
+    populationGrowthRate5yr: real('population_growth_rate_5yr'), // 5-year CAGR %
     medianAge: real('median_age'),
     // Income metrics
     medianHouseholdIncome: integer('median_household_income'),
@@ -99,7 +106,11 @@ export const censusDemographics = pgTable(
     updatedAt: timestamp('updated_at', { withTimezone: true }).notNull().defaultNow(),
   },
   (table) => [
-    uniqueIndex('cen_geo_survey_year_idx').on(table.geoId, table.surveyYear),
+    uniqueIndex('cen_geo_type_geo_survey_year_idx').on(
+      table.geoType,
+      table.geoId,
+      table.surveyYear
+    ),
     index('cen_county_code_idx').on(table.countyCode),
     index('cen_geo_type_idx').on(table.geoType),
     index('cen_state_code_idx').on(table.stateCode),
@@ -170,7 +181,12 @@ export const syncCheckpoints = pgTable(
     // Total records synced in this session
     totalRecordsSynced: integer('total_records_synced').notNull().default(0),
     // Session status
-    status: text('status').notNull().default('in_progress'), // 'in_progress', 'completed', 'rate_limited', 'failed'
+    status: text('status').notNull().default('in_progress'), // 'in_progress', 'completed', 'rate_limited', 'upstream_unavailable', 'failed'
+    // Year range of time-series sources (e.g., BLS); a session is only resumed with the same range
+    startYear: integer('start_year'),
+    endYear: integer('end_year'),
+    // Run parameters (states, ZIPs, years, ...) the session was started with; restored on resume
+    params: jsonb('params'),
     // Timestamps
     startedAt: timestamp('started_at', { withTimezone: true }).notNull().defaultNow(),
     lastUpdatedAt: timestamp('last_updated_at', { withTimezone: true }).notNull().defaultNow(),
@@ -182,6 +198,113 @@ export const syncCheckpoints = pgTable(
   ]
 );
 
+/**
+ * Sync Checkpoint Units table
+ *
+ * Tracks each work unit (state, county, entity, ZIP batch, ...) of a sync
+ * session, so a resumed session processes exactly the units that are still
+ * pending or failed, even when units finish out of order.
+ */
+export const syncCheckpointUnits = pgTable(
+  'sync_checkpoint_units',
+  {
+    id: text('id')
+      .primaryKey()
+      .$defaultFn(() => `cku_${createId()}`),
+    syncSessionId: text('sync_session_id').notNull(),
+    // Work unit key (e.g., county GEOID, HUD entity code, state code)
+    unitKey: text('unit_key').notNull(),
+    status: text('status').notNull().default('pending'), // 'pending', 'completed', 'failed', 'deferred'
+    attempts: integer('attempts').notNull().default(0),
+    lastError: text('last_error'),
+    recordCount: integer('record_count').notNull().default(0),
+    updatedAt: timestamp('updated_at', { withTimezone: true }).notNull().defaultNow(),
+  },
+  (table) => [
+    uniqueIndex('sync_cku_session_unit_idx').on(table.syncSessionId, table.unitKey),
+    index('sync_cku_session_status_idx').on(table.syncSessionId, table.status),
+  ]
+);
+
+/**
+ * Sync Vintages table
+ *
+ * Records the latest upstream dataset vintage (e.g., 'FY2025', 'ACS5-2023',
+ * '2024-08') synced for each source and set of states, so scheduled syncs
+ * can be skipped when nothing has changed upstream.
+ */
+export const syncVintages = pgTable(
+  'sync_vintages',
+  {
+    // Data source ('bls', 'census', 'hud')
+    source: text('source').notNull(),
+    // Comma-separated state codes the vintage was synced for (e.g., 'TX,OK')
+    scope: text('scope').notNull(),
+    vintage: text('vintage').notNull(),
+    syncedAt: timestamp('synced_at', { withTimezone: true }).notNull().defaultNow(),
+  },
+  (table) => [uniqueIndex('sync_vintage_source_scope_idx').on(table.source, table.scope)]
+);
+
+/**
+ * Sync Runs table
+ *
+ * Ledger of data-sync runs: when each run started and finished, the
+ * parameters it used, how many records it synced, its errors, the upstream
+ * vintage and how it ended. The latest completed run of a source is when its
+ * dataset was last refreshed.
+ */
+export const syncRuns = pgTable(
+  'sync_runs',
+  {
+    id: text('id')
+      .primaryKey()
+      .$defaultFn(() => `run_${createId()}`),
+    // Data source ('bls', 'census', 'hud')
+    source: text('source').notNull(),
+    // Checkpoint session the run belongs to
+    syncSessionId: text('sync_session_id'),
+    status: text('status').notNull(), // 'completed', 'partial', 'rate_limited', 'upstream_unavailable', 'failed', 'cancelled'
+    // Run parameters (states, ZIPs, years, ...)
+    params: jsonb('params'),
+    // Upstream vintage synced (e.g., 'FY2025', 'ACS5-2023', '2024-08'), if known
+    vintage: text('vintage'),
+    // Records persisted, units failed and units skipped
+    successful: integer('successful').notNull().default(0),
+    failed: integer('failed').notNull().default(0),
+    skipped: integer('skipped').notNull().default(0),
+    // Per-unit error messages, and why a failed run stopped
+    errors: jsonb('errors').$type<string[]>(),
+    errorMessage: text('error_message'),
+    startedAt: timestamp('started_at', { withTimezone: true }).notNull(),
+    finishedAt: timestamp('finished_at', { withTimezone: true }).notNull(),
+  },
+  (table) => [
+    index('sync_runs_source_finished_idx').on(table.source, table.finishedAt),
+    index('sync_runs_status_idx').on(table.status),
+  ]
+);
+
+/**
+ * Upstream Quota Usage table
+ *
+ * Requests made against each upstream API's daily quota (e.g., the BLS
+ * limit of 500 queries a day), so that a second run on the same day knows
+ * its remaining budget up front. Days follow US Eastern time, when the
+ * government APIs reset their quotas.
+ */
+export const upstreamQuotaUsage = pgTable(
+  'upstream_quota_usage',
+  {
+    // Data source ('bls', 'census', 'hud')
+    source: text('source').notNull(),
+    day: date('day').notNull(),
+    requests: integer('requests').notNull().default(0),
+    updatedAt: timestamp('updated_at', { withTimezone: true }).notNull().defaultNow(),
+  },
+  (table) => [uniqueIndex('upstream_quota_source_day_idx').on(table.source, table.day)]
+);
+
 // Type exports
 export type HudFairMarketRent = typeof hudFairMarketRents.$inferSelect;
 export type NewHudFairMarketRent = typeof hudFairMarketRents.$inferInsert;
@@ -191,3 +314,11 @@ export type BlsEmployment = typeof blsEmployment.$inferSelect;
 export type NewBlsEmployment = typeof blsEmployment.$inferInsert;
 export type SyncCheckpoint = typeof syncCheckpoints.$inferSelect;
 export type NewSyncCheckpoint = typeof syncCheckpoints.$inferInsert;
+export type SyncCheckpointUnit = typeof syncCheckpointUnits.$inferSelect;
+export type NewSyncCheckpointUnit = typeof syncCheckpointUnits.$inferInsert;
+export type SyncVintage = typeof syncVintages.$inferSelect;
+export type NewSyncVintage = typeof syncVintages.$inferInsert;
+export type SyncRun = typeof syncRuns.$inferSelect;
+export type NewSyncRun = typeof syncRuns.$inferInsert;
+export type UpstreamQuotaUsage = typeof upstreamQuotaUsage.$inferSelect;
+export type NewUpstreamQuotaUsage = typeof upstreamQuotaUsage.$inferInsert;
@@ -164,7 +164,7 @@ async function exportSeedData() {
   console.log('Exporting census_demographics...');
   const censusData = await sql`
     SELECT id, geo_id, geo_type, geo_name, state_code, county_code,
-           survey_year, total_population, population_growth_rate, median_age,
+           survey_year, total_population, population_growth_rate, population_growth_rate_5yr, median_age,
            median_household_income, per_capita_income, poverty_rate,
            total_housing_units, occupied_housing_units, vacancy_rate,
            owner_occupied_rate, renter_occupied_rate, median_home_value, median_gross_rent,
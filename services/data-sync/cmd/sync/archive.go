@@ -0,0 +1,63 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+
+	"github.com/dealforge/data-sync/internal/archive"
+	"github.com/dealforge/data-sync/internal/config"
+)
+
+// setupArchive archives upstream responses in SYNC_ARCHIVE, if it is set.
+// With offline set (for reparse), requests are served from the archive
+// instead, so the archive is required.
+func setupArchive(cfg *config.Config, offline bool) {
+	if cfg.ArchiveURL == "" {
+		if offline {
+			slog.Error("SYNC_ARCHIVE is required to reparse archived responses")
+			os.Exit(1)
+		}
+		return
+	}
+
+	store, err := archive.Open(cfg.ArchiveURL, cfg.ArchiveS3Endpoint)
+	if err != nil {
+		slog.Error("failed to open response archive", "archive", cfg.ArchiveURL, "error", err)
+		os.Exit(1)
+	}
+
+	mode := archive.Record
+	if offline {
+		mode = archive.Offline
+	}
+	archive.Use(archive.New(store), mode)
+	slog.Info("using upstream response archive", "archive", cfg.ArchiveURL, "offline", offline)
+}
+
+// setupFixtures records every upstream exchange as a fixture in recordDir,
+// or serves every request from the fixtures in replayDir, in place of any
+// SYNC_ARCHIVE. At most one of them may be set.
+func setupFixtures(recordDir, replayDir string) {
+	dir, mode := recordDir, archive.RecordAll
+	if replayDir != "" {
+		dir, mode = replayDir, archive.Offline
+	}
+	if dir == "" {
+		return
+	}
+
+	if mode == archive.Offline {
+		if _, err := os.Stat(replayDir); err != nil {
+			slog.Error("failed to open --replay fixtures", "dir", replayDir, "error", err)
+			os.Exit(1)
+		}
+	}
+
+	store, err := archive.NewDirStore(dir)
+	if err != nil {
+		slog.Error("failed to open fixture directory", "dir", dir, "error", err)
+		os.Exit(1)
+	}
+	archive.Use(archive.New(store), mode)
+	slog.Info("using upstream fixtures", "dir", dir, "replay", mode == archive.Offline)
+}
@@ -6,6 +6,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log/slog"
@@ -16,169 +17,404 @@ import (
 
 	"github.com/dealforge/data-sync/internal/config"
 	"github.com/dealforge/data-sync/internal/db"
+	"github.com/dealforge/data-sync/internal/geography"
+	"github.com/dealforge/data-sync/internal/metrics"
+	"github.com/dealforge/data-sync/internal/ratelimit"
+	"github.com/dealforge/data-sync/internal/sources"
+	_ "github.com/dealforge/data-sync/internal/sources/all"
+	"github.com/dealforge/data-sync/internal/summary"
 	"github.com/dealforge/data-sync/internal/sync"
 )
 
 func main() {
-	// Parse command line flags
-	stateCode := flag.String("state", "TX", "State code for HUD FMR data (default: TX)")
-	sources := flag.String("sources", "all", "Comma-separated list of sources to sync (hud,census,bls,all)")
-	censusYear := flag.Int("census-year", 0, "Census ACS survey year (default: previous year)")
-	blsStartYear := flag.Int("bls-start-year", 0, "BLS data start year (default: 3 years ago)")
-	blsEndYear := flag.Int("bls-end-year", 0, "BLS data end year (default: current year)")
-	resumeSession := flag.String("resume", "", "Resume from a previous checkpoint session ID")
-	dryRun := flag.Bool("dry-run", false, "Don't write to database, just log what would happen")
-	flag.Parse()
+	os.Exit(run())
+}
 
+// run dispatches to the requested command and returns the process exit
+// code, so deferred cleanup has run by the time main exits.
+func run() int {
 	// Set up structured logging
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
 		Level: slog.LevelInfo,
 	}))
 	slog.SetDefault(logger)
 
+	args := os.Args[1:]
+	if len(args) > 0 {
+		switch args[0] {
+		case "serve":
+			return runServe(args[1:])
+		case "worker":
+			return runWorker(args[1:])
+		case "schedule":
+			return runSchedule(args[1:])
+		case "status":
+			return runStatus(args[1:])
+		case "reparse":
+			return runSync(args[1:], true)
+		}
+	}
+	return runSync(args, false)
+}
+
+// runSync performs a one-shot sync of the requested sources. With reparse
+// set, every upstream request is served from the response archive instead
+// of the network, so the rows are rebuilt from the archived responses. It
+// returns the process exit code the run summary reports.
+func runSync(args []string, reparse bool) int {
+	name := "sync"
+	if reparse {
+		name = "reparse"
+	}
+
+	// Parse command line flags
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	statesFlag := fs.String("states", sources.DefaultState, "Comma-separated list of state codes to sync: TX, OK, LA or NM, e.g. TX,OK")
+	stateCode := fs.String("state", "", "Deprecated: use --states")
+	zipsFlag := fs.String("zips", "", "Comma-separated list of ZIP codes to restrict HUD Small Area FMRs to")
+	zipFile := fs.String("zip-file", "", "File of ZIP codes (one per line) to restrict HUD Small Area FMRs to")
+	sourcesFlag := fs.String("sources", "all", "Comma-separated list of sources to sync ("+strings.Join(sources.Names(), ",")+",all)")
+	censusYear := fs.Int("census-year", 0, "Census ACS survey year (default: previous year)")
+	censusGeo := fs.String("census-geo", "county", "Census ACS geography level: county, tract or zcta (both require --census-counties)")
+	censusCounties := fs.String("census-counties", "", "Comma-separated county GEOIDs to fetch Census tracts or ZCTAs for, e.g. 48029,48091")
+	blsStartYear := fs.Int("bls-start-year", 0, "BLS data start year (default: 3 years ago)")
+	blsEndYear := fs.Int("bls-end-year", 0, "BLS data end year (default: current year)")
+	resumeSession := fs.String("resume", "", "Resume from a previous checkpoint session ID of any source, with the parameters it was started with (syncs only that source unless --sources is given), or \"latest\" to resume each source's latest interrupted session")
+	dryRun := fs.Bool("dry-run", false, "Don't write to database, just log what would happen")
+	wait := fs.Bool("wait", false, "Wait for another sync of the same source to finish instead of skipping the source")
+	summaryFormat := fs.String("summary-format", summary.FormatText, "Format of the run summary: text, json or markdown")
+	summaryFile := fs.String("summary-file", "", "Append the run summary to this file (e.g. $GITHUB_STEP_SUMMARY) instead of printing it; a text summary is still printed")
+	recordDir := fs.String("record", "", "Record every upstream exchange as a fixture in this directory")
+	replayDir := fs.String("replay", "", "Serve every upstream request from the fixtures recorded in this directory, without network access")
+	metricsTextfile := fs.String("metrics-textfile", "", "Write Prometheus metrics to this file when the run finishes, for the node exporter's textfile collector (e.g. /var/lib/node_exporter/data_sync.prom)")
+	fs.Parse(args)
+
+	switch *summaryFormat {
+	case summary.FormatText, summary.FormatJSON, summary.FormatMarkdown:
+	default:
+		slog.Error("invalid --summary-format flag: expected text, json or markdown", "format", *summaryFormat)
+		return 1
+	}
+
+	switch {
+	case *recordDir != "" && *replayDir != "":
+		slog.Error("--record and --replay cannot be used together")
+		return 1
+	case reparse && (*recordDir != "" || *replayDir != ""):
+		slog.Error("--record and --replay cannot be used with reparse, which reads SYNC_ARCHIVE")
+		return 1
+	}
+
 	// Load configuration
 	cfg, err := config.Load()
 	if err != nil {
 		slog.Error("failed to load configuration", "error", err)
-		os.Exit(1)
+		return 1
 	}
 	cfg.DryRun = *dryRun
 
-	// Parse sources
-	sourceList := parseSourceList(*sources)
-	if err := cfg.Validate(sourceList); err != nil {
-		slog.Error("configuration validation failed", "error", err)
-		os.Exit(1)
+	stopTracing := setupTracing(cfg)
+	defer stopTracing()
+
+	setupArchive(cfg, reparse)
+	setupFixtures(*recordDir, *replayDir)
+
+	// Resolve and build the requested sources
+	sourceNames, err := sources.ParseList(*sourcesFlag)
+	if err != nil {
+		slog.Error("invalid --sources flag", "error", err)
+		return 1
+	}
+
+	selected := make([]sources.Source, 0, len(sourceNames))
+	for _, name := range sourceNames {
+		src, err := sources.New(name, cfg)
+		if err != nil {
+			slog.Error("failed to create source", "source", name, "error", err)
+			return 1
+		}
+		// Replayed requests never reach the API, so its keys are optional
+		if *replayDir != "" {
+			selected = append(selected, src)
+			continue
+		}
+		if err := cfg.Validate(name, src.RequiredConfig()); err != nil {
+			slog.Error("configuration validation failed", "error", err)
+			return 1
+		}
+		selected = append(selected, src)
+	}
+
+	if *stateCode != "" {
+		slog.Warn("--state is deprecated, use --states")
+		*statesFlag = *stateCode
+	}
+
+	states, err := geography.ParseStateList(*statesFlag)
+	if err != nil {
+		slog.Error("invalid --states flag", "error", err)
+		return 1
+	}
+
+	stateCodes := make([]string, 0, len(states))
+	for _, state := range states {
+		stateCodes = append(stateCodes, state.Code)
+	}
+
+	zips, err := geography.ParseZIPList(*zipsFlag)
+	if err != nil {
+		slog.Error("invalid --zips flag", "error", err)
+		return 1
+	}
+	if *zipFile != "" {
+		fileZIPs, err := geography.LoadZIPsFromFile(*zipFile)
+		if err != nil {
+			slog.Error("failed to load --zip-file", "path", *zipFile, "error", err)
+			return 1
+		}
+		zips = geography.MergeZIPLists(zips, fileZIPs)
+	}
+	// An empty list would sync whole states rather than none
+	if (*zipsFlag != "" || *zipFile != "") && len(zips) == 0 {
+		slog.Error("--zips and --zip-file list no ZIP codes")
+		return 1
+	}
+
+	counties, err := geography.ParseCountyList(*censusCounties)
+	if err != nil {
+		slog.Error("invalid --census-counties flag", "error", err)
+		return 1
+	}
+
+	params := sources.Params{
+		States:    states,
+		ZIPs:      zips,
+		Counties:  counties,
+		GeoLevel:  *censusGeo,
+		Year:      *censusYear,
+		StartYear: *blsStartYear,
+		EndYear:   *blsEndYear,
 	}
 
 	// Log startup
 	slog.Info("starting data sync service",
-		"sources", sourceList,
-		"state", *stateCode,
+		"sources", sourceNames,
+		"states", stateCodes,
+		"zips", len(zips),
 		"dry_run", cfg.DryRun,
+		"reparse", reparse,
 		"resume_session", *resumeSession,
 	)
 
-	// Validate resume session
-	if *resumeSession != "" && !contains(sourceList, "bls") && !contains(sourceList, "all") {
-		slog.Warn("--resume flag provided but 'bls' not in sources list, ignoring")
-		*resumeSession = ""
-	}
-
 	// Set up context with cancellation
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := withShutdownSignals(context.Background())
 	defer cancel()
 
-	// Handle shutdown signals
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-
-	go func() {
-		sig := <-sigChan
-		slog.Info("received shutdown signal", "signal", sig)
-		cancel()
-	}()
-
 	// Initialize database client
 	dbClient, err := db.NewClient(ctx, cfg.DatabaseURL)
 	if err != nil {
 		slog.Error("failed to connect to database", "error", err)
-		os.Exit(1)
+		return 1
 	}
 	defer dbClient.Close()
 
-	// Create orchestrator
-	orch := sync.NewOrchestrator(
-		dbClient,
-		cfg.HUDAPIKey,
-		cfg.CensusAPIKey,
-		cfg.BLSAPIKey,
-		cfg.MaxConcurrent,
-		cfg.MaxRetries,
-		cfg.DryRun,
-	)
+	// Share each source's daily quota with other runs (dry runs count in memory)
+	if !cfg.DryRun {
+		ratelimit.UseStore(dbClient)
+	}
 
-	// Run sync based on requested sources
-	var results []*sync.SyncResult
+	// Resume sessions belong to a single source; find out which one
+	resumeSource := ""
+	if *resumeSession != "" && *resumeSession != sync.ResumeLatest {
+		checkpoint, err := dbClient.GetCheckpointBySession(ctx, *resumeSession)
+		if err != nil {
+			slog.Error("failed to load resume session", "session_id", *resumeSession, "error", err)
+			return 1
+		}
+		resumeSource = checkpoint.Source
 
-	for _, source := range sourceList {
-		select {
-		case <-ctx.Done():
-			slog.Info("sync cancelled")
-			os.Exit(0)
-		default:
-		}
-
-		var result *sync.SyncResult
-		var err error
-
-		switch source {
-		case "hud":
-			result, err = orch.SyncHUD(ctx, *stateCode)
-		case "census":
-			result, err = orch.SyncCensus(ctx, *censusYear)
-		case "bls":
-			result, err = orch.SyncBLS(ctx, *blsStartYear, *blsEndYear, *resumeSession)
-		case "all":
-			results, err = orch.SyncAll(ctx, *stateCode, *censusYear, *blsStartYear, *blsEndYear)
-			if err != nil {
-				slog.Error("sync failed", "error", err)
-				os.Exit(1)
+		// The session is resumed with the parameters it was started with, so
+		// explicit flags may only repeat them
+		stored, err := sync.SessionParams(checkpoint)
+		if err != nil {
+			slog.Error("failed to load resume session", "session_id", *resumeSession, "error", err)
+			return 1
+		}
+		if stored != nil {
+			// Only the parameters the session's source uses can conflict
+			requested, session := params, *stored
+			if matched := filterSources(selected, resumeSource); len(matched) > 0 {
+				requested, session = sync.ResolveParams(matched[0], params), sync.ResolveParams(matched[0], session)
 			}
-			// Skip adding to results since SyncAll returns all results
+			if conflicts := conflictingFlags(fs, requested, session); len(conflicts) > 0 {
+				slog.Error("--resume session was started with different parameters, drop the conflicting flags",
+					"session_id", *resumeSession,
+					"flags", conflicts,
+					"session_params", *stored,
+				)
+				return 1
+			}
+		}
+
+		switch {
+		case !flagWasSet(fs, "sources"):
+			// Without an explicit --sources, resume just the session's source
+			selected = filterSources(selected, resumeSource)
+		case !contains(sourceNames, resumeSource):
+			slog.Error("--resume session belongs to a source that is not in --sources",
+				"session_id", *resumeSession,
+				"source", resumeSource,
+			)
+			return 1
+		}
+	}
+
+	// Create orchestrator
+	orch := sync.NewOrchestrator(dbClient, cfg.MaxConcurrent, cfg.DryRun)
+	orch.WaitForLock(*wait)
+	orch.MaxResumeAge(cfg.MaxResumeAge)
+
+	// Run sync for each requested source
+	sum := &summary.Summary{DryRun: cfg.DryRun}
+
+	for _, src := range selected {
+		// Once cancelled, the remaining sources are reported as cancelled so
+		// the summary and exit code show the run was interrupted
+		if ctx.Err() != nil {
+			slog.Info("sync cancelled, not starting source", "source", src.Name())
+			sum.Add(src.Name(), db.RunCancelled, nil, errors.New("sync cancelled before the source started"))
 			continue
 		}
 
-		if err != nil {
-			slog.Error("sync failed", "source", source, "error", err)
+		resume := ""
+		if src.Name() == resumeSource || *resumeSession == sync.ResumeLatest {
+			resume = *resumeSession
+		}
+
+		result, err := orch.Sync(ctx, src, params, resume)
+		var locked *db.LockedError
+		if errors.As(err, &locked) {
+			holder := "unknown"
+			if locked.Holder != nil {
+				holder = locked.Holder.String()
+			}
+			slog.Error("skipping source, another sync is running (use --wait to wait for it)",
+				"source", src.Name(),
+				"lock_holder", holder,
+			)
+			sum.Add(src.Name(), summary.StatusSkipped, nil, err)
 			continue
 		}
+		if err != nil {
+			slog.Error("sync failed", "source", src.Name(), "error", err)
+		}
 
-		results = append(results, result)
+		sum.Add(src.Name(), sync.RunStatus(ctx, result, err), result, err)
 	}
 
 	// Print summary
-	fmt.Println("\n=== Sync Summary ===")
-	for _, r := range results {
-		fmt.Printf("\n%s:\n", r.Source)
-		fmt.Printf("  Successful: %d\n", r.Successful)
-		fmt.Printf("  Failed: %d\n", r.Failed)
-		fmt.Printf("  Duration: %s\n", r.Duration)
-		if len(r.Errors) > 0 && len(r.Errors) <= 10 {
-			fmt.Printf("  Errors:\n")
-			for _, e := range r.Errors {
-				fmt.Printf("    - %s\n", e)
-			}
-		} else if len(r.Errors) > 10 {
-			fmt.Printf("  First 10 errors (of %d):\n", len(r.Errors))
-			for _, e := range r.Errors[:10] {
-				fmt.Printf("    - %s\n", e)
-			}
+	if err := writeSummary(sum, *summaryFormat, *summaryFile); err != nil {
+		slog.Error("failed to write summary", "error", err)
+	}
+
+	if *metricsTextfile != "" {
+		if err := metrics.WriteTextfile(*metricsTextfile); err != nil {
+			slog.Error("failed to write metrics textfile", "path", *metricsTextfile, "error", err)
 		}
 	}
 
-	slog.Info("data sync service completed")
+	slog.Info("data sync service completed", "outcome", sum.Outcome())
+	return sum.ExitCode()
 }
 
-// parseSourceList parses the sources flag into a list of source names.
-func parseSourceList(sources string) []string {
-	if sources == "" || sources == "all" {
-		return []string{"all"}
+// writeSummary writes the run summary in the given format, to stdout or
+// appended to a file. With a file, a text summary is also printed.
+func writeSummary(sum *summary.Summary, format, path string) error {
+	if path == "" {
+		return sum.Write(os.Stdout, format)
 	}
 
-	parts := strings.Split(sources, ",")
-	result := make([]string, 0, len(parts))
-	for _, p := range parts {
-		s := strings.TrimSpace(strings.ToLower(p))
-		if s == "hud" || s == "census" || s == "bls" {
-			result = append(result, s)
+	if err := sum.Write(os.Stdout, summary.FormatText); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open summary file: %w", err)
+	}
+	if err := sum.Write(f, format); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// withShutdownSignals returns a context that is cancelled on SIGINT or SIGTERM.
+func withShutdownSignals(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+	go func() {
+		select {
+		case sig := <-sigChan:
+			slog.Info("received shutdown signal", "signal", sig)
+			cancel()
+		case <-ctx.Done():
+		}
+		signal.Stop(sigChan)
+	}()
+
+	return ctx, cancel
+}
+
+// flagWasSet reports whether a flag was passed on the command line.
+func flagWasSet(fs *flag.FlagSet, name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+// paramFlags maps each run parameter, by its JSON name, to the flags that
+// set it.
+var paramFlags = map[string][]string{
+	"states":     {"states", "state"},
+	"zips":       {"zips", "zip-file"},
+	"counties":   {"census-counties"},
+	"geo_level":  {"census-geo"},
+	"year":       {"census-year"},
+	"start_year": {"bls-start-year"},
+	"end_year":   {"bls-end-year"},
+}
+
+// conflictingFlags returns the flags passed on the command line whose values
+// differ from the parameters a session was started with.
+func conflictingFlags(fs *flag.FlagSet, params, stored sources.Params) []string {
+	var conflicts []string
+	for _, field := range params.Differences(stored) {
+		for _, name := range paramFlags[field] {
+			if flagWasSet(fs, name) {
+				conflicts = append(conflicts, "--"+name)
+			}
 		}
 	}
+	return conflicts
+}
 
-	if len(result) == 0 {
-		return []string{"all"}
+// filterSources returns the sources with the given name.
+func filterSources(srcs []sources.Source, name string) []sources.Source {
+	var filtered []sources.Source
+	for _, src := range srcs {
+		if src.Name() == name {
+			filtered = append(filtered, src)
+		}
 	}
-	return result
+	return filtered
 }
 
 // contains checks if a string slice contains a given string.
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+
+	"github.com/dealforge/data-sync/internal/config"
+	"github.com/dealforge/data-sync/internal/db"
+	"github.com/dealforge/data-sync/internal/metrics"
+)
+
+// serveMetrics serves Prometheus metrics in the background if
+// SYNC_METRICS_ADDR is set, until ctx is cancelled. Last-success timestamps
+// are seeded from the sync run ledger so they survive restarts.
+func serveMetrics(ctx context.Context, cfg *config.Config, dbClient *db.Client) {
+	if cfg.MetricsAddr == "" {
+		return
+	}
+
+	runs, err := dbClient.LatestSyncRuns(ctx, db.RunCompleted)
+	if err != nil {
+		slog.Warn("failed to seed last-success metrics", "error", err)
+	}
+	for _, run := range runs {
+		metrics.LastSuccess.WithLabelValues(run.Source).Set(float64(run.FinishedAt.Unix()))
+	}
+
+	go func() {
+		if err := metrics.Serve(ctx, cfg.MetricsAddr); err != nil {
+			slog.Error("metrics server failed", "addr", cfg.MetricsAddr, "error", err)
+			os.Exit(1)
+		}
+	}()
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log/slog"
+	"strings"
+
+	"github.com/dealforge/data-sync/internal/config"
+	"github.com/dealforge/data-sync/internal/db"
+	"github.com/dealforge/data-sync/internal/geography"
+	"github.com/dealforge/data-sync/internal/ratelimit"
+	"github.com/dealforge/data-sync/internal/scheduler"
+	"github.com/dealforge/data-sync/internal/sources"
+	"github.com/dealforge/data-sync/internal/sync"
+)
+
+// runSchedule runs each source on its own cadence until shut down.
+func runSchedule(args []string) int {
+	fs := flag.NewFlagSet("schedule", flag.ExitOnError)
+	sourcesFlag := fs.String("sources", "all", "Comma-separated list of sources to schedule ("+strings.Join(sources.Names(), ",")+",all)")
+	statesFlag := fs.String("states", sources.DefaultState, "Comma-separated list of state codes to sync, e.g. TX,OK,LA,NM")
+	dryRun := fs.Bool("dry-run", false, "Don't write to database, just log what would happen")
+	resume := fs.String("resume", sync.ResumeLatest, "Resume each source's latest interrupted session on startup (\"latest\"), or don't (\"none\")")
+	fs.Parse(args)
+
+	cfg, err := config.Load()
+	if err != nil {
+		slog.Error("failed to load configuration", "error", err)
+		return 1
+	}
+
+	stopTracing := setupTracing(cfg)
+	defer stopTracing()
+
+	setupArchive(cfg, false)
+
+	sourceNames, err := sources.ParseList(*sourcesFlag)
+	if err != nil {
+		slog.Error("invalid --sources flag", "error", err)
+		return 1
+	}
+
+	var selected []sources.Source
+	for _, src := range availableSources(cfg) {
+		if contains(sourceNames, src.Name()) {
+			selected = append(selected, src)
+		}
+	}
+
+	if *resume != sync.ResumeLatest && *resume != "none" {
+		slog.Error("invalid --resume flag: expected latest or none", "resume", *resume)
+		return 1
+	}
+
+	states, err := geography.ParseStateList(*statesFlag)
+	if err != nil {
+		slog.Error("invalid --states flag", "error", err)
+		return 1
+	}
+
+	schedules, err := scheduler.LoadSchedules(selected, cfg.Get)
+	if err != nil {
+		slog.Error("invalid schedule", "error", err)
+		return 1
+	}
+	if len(schedules) == 0 {
+		slog.Error("no sources are scheduled, nothing to do")
+		return 1
+	}
+	for _, sched := range schedules {
+		slog.Info("scheduling source", "source", sched.Source, "cron", sched.Spec)
+	}
+
+	ctx, cancel := withShutdownSignals(context.Background())
+	defer cancel()
+
+	dbClient, err := db.NewClient(ctx, cfg.DatabaseURL)
+	if err != nil {
+		slog.Error("failed to connect to database", "error", err)
+		return 1
+	}
+	defer dbClient.Close()
+
+	serveMetrics(ctx, cfg, dbClient)
+	if !(*dryRun || cfg.DryRun) {
+		ratelimit.UseStore(dbClient)
+	}
+
+	params := sources.Params{States: states}
+	sched := scheduler.New(dbClient, selected, schedules, params, cfg.MaxConcurrent, *dryRun || cfg.DryRun)
+	sched.ResumeLatest = *resume == sync.ResumeLatest
+	sched.MaxResumeAge = cfg.MaxResumeAge
+
+	if err := sched.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+		slog.Error("scheduler failed", "error", err)
+		return 1
+	}
+
+	slog.Info("data sync scheduler stopped")
+	return 0
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/dealforge/data-sync/internal/config"
+	"github.com/dealforge/data-sync/internal/db"
+	"github.com/dealforge/data-sync/internal/ratelimit"
+	"github.com/dealforge/data-sync/internal/server"
+	"github.com/dealforge/data-sync/internal/sources"
+)
+
+// shutdownTimeout bounds how long serve mode waits for in-flight requests
+// and the active run to stop.
+const shutdownTimeout = 30 * time.Second
+
+// runServe starts the long-running HTTP control API.
+func runServe(args []string) int {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", ":8080", "Address for the HTTP API to listen on")
+	fs.Parse(args)
+
+	cfg, err := config.Load()
+	if err != nil {
+		slog.Error("failed to load configuration", "error", err)
+		return 1
+	}
+	if cfg.APIToken == "" {
+		slog.Error("SYNC_API_TOKEN is required for serve mode")
+		return 1
+	}
+
+	stopTracing := setupTracing(cfg)
+	defer stopTracing()
+
+	setupArchive(cfg, false)
+
+	available := availableSources(cfg)
+
+	ctx, cancel := withShutdownSignals(context.Background())
+	defer cancel()
+
+	dbClient, err := db.NewClient(ctx, cfg.DatabaseURL)
+	if err != nil {
+		slog.Error("failed to connect to database", "error", err)
+		return 1
+	}
+	defer dbClient.Close()
+
+	serveMetrics(ctx, cfg, dbClient)
+	ratelimit.UseStore(dbClient)
+
+	srv := server.New(dbClient, available, cfg.APIToken, cfg.MaxConcurrent)
+	httpServer := &http.Server{
+		Addr:              *addr,
+		Handler:           srv.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		slog.Info("starting data sync API", "addr", *addr, "sources", len(available))
+		serveErr <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("HTTP server failed", "error", err)
+			return 1
+		}
+	case <-ctx.Done():
+	}
+
+	// Stop accepting requests, then cancel the active run and wait for it so
+	// its checkpoint is updated before the database pool closes
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancelShutdown()
+
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		slog.Warn("failed to shut down HTTP server cleanly", "error", err)
+	}
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Warn("active sync run did not stop in time", "error", err)
+	}
+
+	slog.Info("data sync API stopped")
+	return 0
+}
+
+// availableSources builds every source whose configuration is present, once,
+// so long-running modes keep their clients warm between runs.
+func availableSources(cfg *config.Config) []sources.Source {
+	var available []sources.Source
+	for _, name := range sources.Names() {
+		src, err := sources.New(name, cfg)
+		if err != nil {
+			slog.Error("failed to create source", "source", name, "error", err)
+			os.Exit(1)
+		}
+		if err := cfg.Validate(name, src.RequiredConfig()); err != nil {
+			slog.Warn("source not available", "source", name, "error", err)
+			continue
+		}
+		available = append(available, src)
+	}
+	return available
+}
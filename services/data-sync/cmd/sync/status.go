@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dealforge/data-sync/internal/config"
+	"github.com/dealforge/data-sync/internal/db"
+	"github.com/dealforge/data-sync/internal/sources"
+)
+
+// sourceFreshness summarises when a source's dataset was last refreshed.
+type sourceFreshness struct {
+	Source      string      `json:"source"`
+	LastSuccess *db.SyncRun `json:"last_success"` // Latest completed run
+	LastRun     *db.SyncRun `json:"last_run"`     // Latest run of any status
+}
+
+// runStatus prints the data freshness of each source from the sync run ledger.
+func runStatus(args []string) int {
+	fs := flag.NewFlagSet("status", flag.ExitOnError)
+	asJSON := fs.Bool("json", false, "Print the status as JSON")
+	fs.Parse(args)
+
+	cfg, err := config.Load()
+	if err != nil {
+		slog.Error("failed to load configuration", "error", err)
+		return 1
+	}
+
+	ctx, cancel := withShutdownSignals(context.Background())
+	defer cancel()
+
+	dbClient, err := db.NewClient(ctx, cfg.DatabaseURL)
+	if err != nil {
+		slog.Error("failed to connect to database", "error", err)
+		return 1
+	}
+	defer dbClient.Close()
+
+	latest, err := dbClient.LatestSyncRuns(ctx)
+	if err != nil {
+		slog.Error("failed to load sync runs", "error", err)
+		return 1
+	}
+	succeeded, err := dbClient.LatestSyncRuns(ctx, db.RunCompleted)
+	if err != nil {
+		slog.Error("failed to load sync runs", "error", err)
+		return 1
+	}
+
+	freshness := summariseFreshness(sources.Names(), latest, succeeded)
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(freshness); err != nil {
+			slog.Error("failed to write status", "error", err)
+			return 1
+		}
+		return 0
+	}
+
+	now := time.Now()
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SOURCE\tLAST REFRESHED\tAGE\tVINTAGE\tRECORDS\tLAST RUN")
+	for _, f := range freshness {
+		refreshed, age, vintage, records := "never", "-", "-", "-"
+		if run := f.LastSuccess; run != nil {
+			refreshed = run.FinishedAt.Format(time.RFC3339)
+			age = formatAge(now.Sub(run.FinishedAt))
+			records = fmt.Sprint(run.Successful)
+			if run.Vintage != nil {
+				vintage = *run.Vintage
+			}
+		}
+
+		lastRun := "-"
+		if run := f.LastRun; run != nil {
+			lastRun = fmt.Sprintf("%s at %s", run.Status, run.FinishedAt.Format(time.RFC3339))
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", f.Source, refreshed, age, vintage, records, lastRun)
+	}
+	tw.Flush()
+	return 0
+}
+
+// summariseFreshness combines the latest run and latest completed run of
+// each source. Registered sources are always listed, even if they never ran.
+func summariseFreshness(names []string, latest, succeeded []*db.SyncRun) []sourceFreshness {
+	bySource := make(map[string]*sourceFreshness)
+	entry := func(source string) *sourceFreshness {
+		if f, ok := bySource[source]; ok {
+			return f
+		}
+		f := &sourceFreshness{Source: source}
+		bySource[source] = f
+		return f
+	}
+
+	for _, name := range names {
+		entry(name)
+	}
+	for _, run := range latest {
+		entry(run.Source).LastRun = run
+	}
+	for _, run := range succeeded {
+		entry(run.Source).LastSuccess = run
+	}
+
+	freshness := make([]sourceFreshness, 0, len(bySource))
+	for _, f := range bySource {
+		freshness = append(freshness, *f)
+	}
+	sort.Slice(freshness, func(i, j int) bool { return freshness[i].Source < freshness[j].Source })
+	return freshness
+}
+
+// formatAge formats a duration in whole days, or hours under a day.
+func formatAge(d time.Duration) string {
+	if d < 24*time.Hour {
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dd", int(d.Hours()/24))
+}
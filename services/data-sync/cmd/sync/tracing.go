@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/dealforge/data-sync/internal/config"
+	"github.com/dealforge/data-sync/internal/tracing"
+)
+
+// traceFlushTimeout bounds how long exiting waits for pending spans to be
+// exported.
+const traceFlushTimeout = 5 * time.Second
+
+// setupTracing installs the configured trace exporter. The returned function
+// flushes pending spans; defer it so it runs before the command returns its
+// exit code.
+func setupTracing(cfg *config.Config) func() {
+	shutdown, err := tracing.Setup(context.Background(), cfg.TraceExporter, cfg.TraceFile)
+	if err != nil {
+		slog.Error("failed to set up tracing", "error", err)
+		os.Exit(1)
+	}
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), traceFlushTimeout)
+		defer cancel()
+		if err := shutdown(ctx); err != nil {
+			slog.Warn("failed to flush traces", "error", err)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log/slog"
+
+	"github.com/dealforge/data-sync/internal/config"
+	"github.com/dealforge/data-sync/internal/db"
+	"github.com/dealforge/data-sync/internal/ratelimit"
+	"github.com/dealforge/data-sync/internal/worker"
+)
+
+// runWorker consumes data-sync jobs queued in the jobs table.
+func runWorker(args []string) int {
+	fs := flag.NewFlagSet("worker", flag.ExitOnError)
+	pollInterval := fs.Duration("poll-interval", worker.DefaultPollInterval, "How often to look for pending jobs when idle")
+	heartbeatInterval := fs.Duration("heartbeat-interval", worker.DefaultHeartbeatInterval, "How often to write progress into a running job")
+	staleAfter := fs.Duration("stale-after", worker.DefaultStaleAfter, "How long a running job can go without a heartbeat before another worker reclaims it")
+	fs.Parse(args)
+
+	if *staleAfter <= *heartbeatInterval {
+		slog.Error("--stale-after must be longer than --heartbeat-interval", "stale_after", *staleAfter, "heartbeat_interval", *heartbeatInterval)
+		return 1
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		slog.Error("failed to load configuration", "error", err)
+		return 1
+	}
+
+	stopTracing := setupTracing(cfg)
+	defer stopTracing()
+
+	setupArchive(cfg, false)
+
+	available := availableSources(cfg)
+	if len(available) == 0 {
+		slog.Error("no sources are configured, nothing to do")
+		return 1
+	}
+
+	ctx, cancel := withShutdownSignals(context.Background())
+	defer cancel()
+
+	dbClient, err := db.NewClient(ctx, cfg.DatabaseURL)
+	if err != nil {
+		slog.Error("failed to connect to database", "error", err)
+		return 1
+	}
+	defer dbClient.Close()
+
+	serveMetrics(ctx, cfg, dbClient)
+	ratelimit.UseStore(dbClient)
+
+	w := worker.New(dbClient, available, cfg.MaxConcurrent)
+	w.PollInterval = *pollInterval
+	w.HeartbeatInterval = *heartbeatInterval
+	w.StaleAfter = *staleAfter
+
+	if err := w.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+		slog.Error("worker failed", "error", err)
+		return 1
+	}
+
+	slog.Info("data sync worker stopped")
+	return 0
+}
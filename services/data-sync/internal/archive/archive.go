@@ -0,0 +1,312 @@
+// Package archive keeps the raw upstream API responses the clients parse,
+// so that a fixed parser can rebuild database rows without calling the
+// APIs again.
+//
+// Response bodies are content-addressed: each is stored once under its
+// SHA-256, at objects/<sha256[:2]>/<sha256>. Each request is identified by
+// a key hashed from its method, URL and body with API keys removed, and
+// requests/<source>/<key>.json records the request and the response it got
+// most recently.
+//
+// While an archive is in use in Record mode, successful responses are
+// archived as the clients receive them; RecordAll archives error responses
+// too, so that recorded fixtures replay failures as well. In Offline mode,
+// requests are served from the archive and never reach the network.
+package archive
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dealforge/data-sync/internal/sources"
+)
+
+// ErrNotArchived is returned (wrapped) in Offline mode for requests the
+// archive has no response to.
+var ErrNotArchived = errors.New("no archived response")
+
+// Mode is how the client transports use the archive.
+type Mode int
+
+const (
+	// Record archives every successful response.
+	Record Mode = iota
+	// RecordAll archives every response, whatever its status.
+	RecordAll
+	// Offline serves every request from the archive.
+	Offline
+)
+
+// secretParams are the query parameters and JSON body fields that carry API
+// keys. They are left out of archived requests and request keys.
+var secretParams = []string{"key", "registrationkey", "api_key"}
+
+// Entry describes an archived request and the response it got.
+type Entry struct {
+	Source      string    `json:"source"`
+	RequestKey  string    `json:"request_key"`
+	Method      string    `json:"method"`
+	URL         string    `json:"url"`                    // Without API keys
+	RequestBody string    `json:"request_body,omitempty"` // Without API keys
+	Unit        string    `json:"unit,omitempty"`         // Key of the work unit the request was made for
+	Entity      string    `json:"entity,omitempty"`       // Name of that work unit
+	Status      int       `json:"status"`
+	ContentType string    `json:"content_type,omitempty"`
+	SHA256      string    `json:"sha256"` // Of the response body
+	Size        int       `json:"size"`
+	FetchedAt   time.Time `json:"fetched_at"`
+}
+
+// Archive stores upstream responses in a Store.
+type Archive struct {
+	store Store
+	now   func() time.Time
+}
+
+// New creates an archive backed by store.
+func New(store Store) *Archive {
+	return &Archive{store: store, now: time.Now}
+}
+
+var (
+	currentMu sync.Mutex
+	current   *Archive
+	mode      Mode
+)
+
+// Use makes every client transport use a in the given mode. Pass nil to stop
+// archiving.
+func Use(a *Archive, m Mode) {
+	currentMu.Lock()
+	defer currentMu.Unlock()
+	current, mode = a, m
+}
+
+func inUse() (*Archive, Mode) {
+	currentMu.Lock()
+	defer currentMu.Unlock()
+	return current, mode
+}
+
+// Save archives a response body for a request. The body is stored first, so
+// an entry never refers to a missing body.
+func (a *Archive) Save(ctx context.Context, entry *Entry, body []byte) error {
+	sum := sha256.Sum256(body)
+	entry.SHA256 = hex.EncodeToString(sum[:])
+	entry.Size = len(body)
+	if entry.FetchedAt.IsZero() {
+		entry.FetchedAt = a.now().UTC()
+	}
+
+	if err := a.store.Put(ctx, objectKey(entry.SHA256), body); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode archive entry: %w", err)
+	}
+	return a.store.Put(ctx, entryKey(entry.Source, entry.RequestKey), data)
+}
+
+// Load returns the latest archived response to a request of a source, by
+// its request key. It returns an error wrapping ErrNotArchived if there is
+// none.
+func (a *Archive) Load(ctx context.Context, source, requestKey string) (*Entry, []byte, error) {
+	data, err := a.store.Get(ctx, entryKey(source, requestKey))
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil, fmt.Errorf("%s request %s: %w", source, requestKey, ErrNotArchived)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode archive entry %s: %w", requestKey, err)
+	}
+
+	body, err := a.store.Get(ctx, objectKey(entry.SHA256))
+	if err != nil {
+		return nil, nil, err
+	}
+	if sum := sha256.Sum256(body); hex.EncodeToString(sum[:]) != entry.SHA256 {
+		return nil, nil, fmt.Errorf("archived response %s does not match its checksum", entry.SHA256)
+	}
+	return &entry, body, nil
+}
+
+// Transport archives the responses to a source's requests through base, or
+// serves them from the archive, according to the archive in use. Without
+// one, requests pass straight through. A nil base uses
+// http.DefaultTransport.
+//
+// It belongs outside the retrying transport, so that only the final
+// response to a request is archived and offline requests are neither
+// retried nor rate limited.
+func Transport(source string, base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		a, m := inUse()
+		if a == nil {
+			return base.RoundTrip(req)
+		}
+
+		entry, err := newEntry(source, req)
+		if err != nil {
+			return nil, err
+		}
+		if m == Offline {
+			return a.replay(req, entry)
+		}
+
+		resp, err := base.RoundTrip(req)
+		if err != nil || (m == Record && (resp.StatusCode < 200 || resp.StatusCode > 299)) {
+			return resp, err
+		}
+		return a.record(req.Context(), entry, resp)
+	})
+}
+
+// record archives a response, handing the caller an unread copy of its
+// body. Failing to archive it does not fail the request.
+func (a *Archive) record(ctx context.Context, entry *Entry, resp *http.Response) (*http.Response, error) {
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	entry.Status = resp.StatusCode
+	entry.ContentType = resp.Header.Get("Content-Type")
+	if err := a.Save(ctx, entry, body); err != nil {
+		slog.Warn("failed to archive upstream response", "source", entry.Source, "url", entry.URL, "error", err)
+	}
+	return resp, nil
+}
+
+// replay serves a request from the archive.
+func (a *Archive) replay(req *http.Request, entry *Entry) (*http.Response, error) {
+	archived, body, err := a.Load(req.Context(), entry.Source, entry.RequestKey)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", entry.Method, entry.URL, err)
+	}
+
+	header := make(http.Header)
+	if archived.ContentType != "" {
+		header.Set("Content-Type", archived.ContentType)
+	}
+	return &http.Response{
+		Status:        strconv.Itoa(archived.Status) + " " + http.StatusText(archived.Status),
+		StatusCode:    archived.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// newEntry describes a request, with API keys removed, and derives its
+// request key.
+func newEntry(source string, req *http.Request) (*Entry, error) {
+	body, err := requestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	u := *req.URL
+	query := u.Query()
+	for _, name := range secretParams {
+		query.Del(name)
+	}
+	u.RawQuery = query.Encode() // Sorted, so the key does not depend on parameter order
+
+	entry := &Entry{
+		Source:      source,
+		Method:      req.Method,
+		URL:         u.String(),
+		RequestBody: redactBody(body),
+	}
+	if unit, ok := sources.UnitFromContext(req.Context()); ok {
+		entry.Unit, entry.Entity = unit.Key, unit.Name
+	}
+
+	sum := sha256.Sum256([]byte(entry.Method + "\n" + entry.URL + "\n" + entry.RequestBody))
+	entry.RequestKey = hex.EncodeToString(sum[:])
+	return entry, nil
+}
+
+// requestBody returns a copy of a request's body, leaving the body itself
+// to be sent.
+func requestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		defer body.Close()
+		return io.ReadAll(body)
+	}
+
+	data, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(data))
+	return data, nil
+}
+
+// redactBody removes API keys from a JSON object body and re-encodes it with
+// sorted fields. Other bodies are kept as they are.
+func redactBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return string(body)
+	}
+	for _, name := range secretParams {
+		delete(fields, name)
+	}
+	canonical, err := json.Marshal(fields)
+	if err != nil {
+		return string(body)
+	}
+	return string(canonical)
+}
+
+func objectKey(sha string) string {
+	return "objects/" + sha[:2] + "/" + sha
+}
+
+func entryKey(source, requestKey string) string {
+	return "requests/" + url.PathEscape(source) + "/" + requestKey + ".json"
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
@@ -0,0 +1,209 @@
+package archive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dealforge/data-sync/internal/sources"
+)
+
+// useDir archives into a fresh directory for the rest of the test.
+func useDir(t *testing.T, m Mode) (*Archive, string) {
+	t.Helper()
+	dir := t.TempDir()
+	store, err := NewDirStore(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	a := New(store)
+	Use(a, m)
+	t.Cleanup(func() { Use(nil, Record) })
+	return a, dir
+}
+
+func get(t *testing.T, client *http.Client, ctx context.Context, url string) (*http.Response, string, error) {
+	t.Helper()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return resp, string(body), nil
+}
+
+func TestTransport_RecordThenReplayOffline(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `[["NAME","B01003_001E"],["Bexar County, Texas","2009324"]]`)
+	}))
+
+	a, dir := useDir(t, Record)
+	client := &http.Client{Transport: Transport("census", nil)}
+	ctx := sources.WithUnit(context.Background(), sources.WorkUnit{Key: "48029", Name: "Bexar County"})
+
+	_, body, err := get(t, client, ctx, server.URL+"/data?get=NAME,B01003_001E&for=county:029&in=state:48&key=secret1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(body, "Bexar County") {
+		t.Fatalf("expected the caller to get the response body, got %q", body)
+	}
+
+	// The entry records the request without its API key
+	entries, _ := filepath.Glob(filepath.Join(dir, "requests", "census", "*.json"))
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 archived entry, got %d", len(entries))
+	}
+	data, _ := os.ReadFile(entries[0])
+	if strings.Contains(string(data), "secret1") {
+		t.Errorf("expected the API key to be left out of the entry, got:\n%s", data)
+	}
+	if !strings.Contains(string(data), `"unit": "48029"`) {
+		t.Errorf("expected the entry to record the work unit, got:\n%s", data)
+	}
+
+	// Offline, the same request with another key and parameter order is
+	// served from the archive
+	server.Close()
+	Use(a, Offline)
+	resp, replayed, err := get(t, client, ctx, server.URL+"/data?key=secret2&in=state:48&for=county:029&get=NAME,B01003_001E")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if replayed != body || resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("expected the archived response, got %d %q (%s)", resp.StatusCode, replayed, resp.Header.Get("Content-Type"))
+	}
+	if requests != 1 {
+		t.Errorf("expected 1 request to reach the server, got %d", requests)
+	}
+}
+
+func TestTransport_OfflineWithoutArchivedResponse(t *testing.T) {
+	useDir(t, Offline)
+	client := &http.Client{Transport: Transport("hud", nil)}
+
+	_, _, err := get(t, client, context.Background(), "http://upstream.test/fmr/data/4802999999")
+	if !errors.Is(err, ErrNotArchived) {
+		t.Errorf("expected ErrNotArchived, got %v", err)
+	}
+}
+
+func TestTransport_SkipsUnsuccessfulResponses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	_, dir := useDir(t, Record)
+	client := &http.Client{Transport: Transport("hud", nil)}
+	if _, _, err := get(t, client, context.Background(), server.URL); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if entries, _ := filepath.Glob(filepath.Join(dir, "requests", "hud", "*.json")); len(entries) != 0 {
+		t.Errorf("expected no archived entries, got %d", len(entries))
+	}
+}
+
+func TestTransport_RecordAllReplaysErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"error":"No data found"}`)
+	}))
+
+	a, _ := useDir(t, RecordAll)
+	client := &http.Client{Transport: Transport("hud", nil)}
+	if _, _, err := get(t, client, context.Background(), server.URL+"/fmr/data/4802999999"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	server.Close()
+	Use(a, Offline)
+	resp, body, err := get(t, client, context.Background(), server.URL+"/fmr/data/4802999999")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != http.StatusNotFound || body != `{"error":"No data found"}` {
+		t.Errorf("expected the recorded 404, got %d %q", resp.StatusCode, body)
+	}
+}
+
+func TestTransport_PassesThroughWithoutArchive(t *testing.T) {
+	Use(nil, Record)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "ok")
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: Transport("hud", nil)}
+	if _, body, err := get(t, client, context.Background(), server.URL); err != nil || body != "ok" {
+		t.Errorf("expected the live response, got %q (%v)", body, err)
+	}
+}
+
+func TestNewEntry_RedactsBodyKeys(t *testing.T) {
+	entry := func(body string) *Entry {
+		req, err := http.NewRequest(http.MethodPost, "https://api.bls.gov/publicAPI/v2/timeseries/data/", strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		e, err := newEntry("bls", req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return e
+	}
+
+	a := entry(`{"seriesid":["LAUCN480290000000003"],"startyear":"2023","endyear":"2024","registrationkey":"secret1"}`)
+	b := entry(`{"registrationkey":"secret2","endyear":"2024","startyear":"2023","seriesid":["LAUCN480290000000003"]}`)
+	c := entry(`{"seriesid":["LAUCN480130000000003"],"startyear":"2023","endyear":"2024","registrationkey":"secret1"}`)
+
+	if strings.Contains(a.RequestBody, "secret1") {
+		t.Errorf("expected the registration key to be removed, got %s", a.RequestBody)
+	}
+	if a.RequestKey != b.RequestKey {
+		t.Error("expected requests differing only in key and field order to share a request key")
+	}
+	if a.RequestKey == c.RequestKey {
+		t.Error("expected requests for different series to have different request keys")
+	}
+}
+
+func TestArchive_StoresBodiesOnce(t *testing.T) {
+	a, dir := useDir(t, Record)
+	ctx := context.Background()
+
+	body := []byte(`{"data":{"basicdata":[]}}`)
+	for _, key := range []string{"a", "b"} {
+		if err := a.Save(ctx, &Entry{Source: "hud", RequestKey: key, Status: 200}, body); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	objects, _ := filepath.Glob(filepath.Join(dir, "objects", "*", "*"))
+	if len(objects) != 1 {
+		t.Errorf("expected identical bodies to be stored once, got %d objects", len(objects))
+	}
+
+	if _, got, err := a.Load(ctx, "hud", "b"); err != nil || string(got) != string(body) {
+		t.Errorf("expected the archived body, got %q (%v)", got, err)
+	}
+}
@@ -0,0 +1,97 @@
+package archive
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// defaultS3Endpoint serves s3:// archives when no endpoint is configured.
+const defaultS3Endpoint = "https://s3.amazonaws.com"
+
+// s3Store keeps archived objects in a bucket of an S3-compatible store
+// (AWS S3, MinIO, R2, ...). Credentials come from the standard AWS_* or
+// MINIO_* environment variables, or the instance's IAM role.
+type s3Store struct {
+	client *minio.Client
+	bucket string
+	prefix string
+}
+
+func newS3Store(endpoint, bucket, prefix string) (*s3Store, error) {
+	if bucket == "" {
+		return nil, fmt.Errorf("archive location s3:// needs a bucket, e.g. s3://bucket/prefix")
+	}
+	if endpoint == "" {
+		endpoint = defaultS3Endpoint
+	}
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "https://" + endpoint
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid S3 endpoint %q: %w", endpoint, err)
+	}
+
+	client, err := minio.New(u.Host, &minio.Options{
+		Creds: credentials.NewChainCredentials([]credentials.Provider{
+			&credentials.EnvAWS{},
+			&credentials.EnvMinio{},
+			&credentials.IAM{},
+		}),
+		Secure: u.Scheme != "http",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create S3 client: %w", err)
+	}
+
+	return &s3Store{client: client, bucket: bucket, prefix: strings.Trim(prefix, "/")}, nil
+}
+
+func (s *s3Store) Put(ctx context.Context, key string, data []byte) error {
+	_, err := s.client.PutObject(ctx, s.bucket, s.object(key), bytes.NewReader(data), int64(len(data)), minio.PutObjectOptions{
+		ContentType: contentType(key),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write archive object: %w", err)
+	}
+	return nil
+}
+
+func (s *s3Store) Get(ctx context.Context, key string) ([]byte, error) {
+	obj, err := s.client.GetObject(ctx, s.bucket, s.object(key), minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive object: %w", err)
+	}
+	defer obj.Close()
+
+	data, err := io.ReadAll(obj)
+	if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+		return nil, fmt.Errorf("%s: %w", key, ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive object: %w", err)
+	}
+	return data, nil
+}
+
+func (s *s3Store) object(key string) string {
+	return path.Join(s.prefix, key)
+}
+
+// contentType returns the content type objects are stored with: entries are
+// JSON, response bodies are kept as opaque bytes.
+func contentType(key string) string {
+	if strings.HasSuffix(key, ".json") {
+		return "application/json"
+	}
+	return "application/octet-stream"
+}
@@ -0,0 +1,86 @@
+package archive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrNotFound is returned (wrapped) by a Store for keys it does not hold.
+var ErrNotFound = errors.New("not found in archive")
+
+// Store holds archived objects by slash-separated key.
+type Store interface {
+	Put(ctx context.Context, key string, data []byte) error
+	Get(ctx context.Context, key string) ([]byte, error)
+}
+
+// Open opens the store at location: an s3://bucket/prefix URL, served by
+// s3Endpoint (or AWS S3 if empty), or a local directory.
+func Open(location, s3Endpoint string) (Store, error) {
+	if rest, ok := strings.CutPrefix(location, "s3://"); ok {
+		bucket, prefix, _ := strings.Cut(rest, "/")
+		return newS3Store(s3Endpoint, bucket, prefix)
+	}
+	return NewDirStore(location)
+}
+
+// DirStore keeps archived objects as files under a directory.
+type DirStore struct {
+	root string
+}
+
+// NewDirStore creates a store under root, creating the directory if needed.
+func NewDirStore(root string) (*DirStore, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create archive directory: %w", err)
+	}
+	return &DirStore{root: root}, nil
+}
+
+// Put writes an object, replacing any earlier one with the same key. The
+// file is written under a temporary name and renamed, so readers never see
+// a partial object.
+func (s *DirStore) Put(ctx context.Context, key string, data []byte) error {
+	path := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create archive directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to write archive object: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write archive object: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write archive object: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write archive object: %w", err)
+	}
+	return nil
+}
+
+// Get reads an object.
+func (s *DirStore) Get(ctx context.Context, key string) ([]byte, error) {
+	data, err := os.ReadFile(s.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%s: %w", key, ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive object: %w", err)
+	}
+	return data, nil
+}
+
+func (s *DirStore) path(key string) string {
+	return filepath.Join(s.root, filepath.FromSlash(key))
+}
@@ -0,0 +1,187 @@
+// Package breaker provides the circuit breaker that stops the upstream API
+// clients from hammering a source that is down.
+//
+// Each source has one Breaker. After Threshold consecutive failed requests
+// (server errors, timeouts or dropped connections) it opens, and every
+// request fails straight away with an error wrapping
+// sources.ErrUpstreamUnavailable until the cooldown has passed. A single
+// probe request is then let through: if it succeeds the breaker closes,
+// otherwise it opens for another cooldown.
+package breaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/dealforge/data-sync/internal/metrics"
+	"github.com/dealforge/data-sync/internal/sources"
+)
+
+// Defaults used by the upstream API clients.
+const (
+	DefaultThreshold = 5
+	DefaultCooldown  = 5 * time.Minute
+)
+
+// States of a breaker.
+const (
+	StateClosed   = "closed"    // Requests flow normally
+	StateOpen     = "open"      // Requests fail straight away
+	StateHalfOpen = "half_open" // One probe request is in flight
+)
+
+// Breaker tracks the health of one source's upstream API. It is safe for
+// concurrent use.
+type Breaker struct {
+	source    string
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	state    string
+	failures int       // Consecutive failures while closed
+	openedAt time.Time // When the breaker last opened
+}
+
+// New creates the breaker of a source, which opens after threshold
+// consecutive failures and stays open for cooldown.
+func New(source string, threshold int, cooldown time.Duration) *Breaker {
+	if threshold < 1 {
+		threshold = 1
+	}
+	metrics.CircuitOpen.WithLabelValues(source).Set(0)
+	return &Breaker{
+		source:    source,
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+		state:     StateClosed,
+	}
+}
+
+// State returns the breaker's current state.
+func (b *Breaker) State() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// Allow reports whether a request may be made. It returns an error wrapping
+// sources.ErrUpstreamUnavailable while the breaker is open, or while a probe
+// request is already in flight.
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return b.unavailable()
+		}
+		slog.Info("upstream circuit half-open, probing", "source", b.source)
+		b.state = StateHalfOpen
+		return nil
+	case StateHalfOpen:
+		return b.unavailable()
+	default:
+		return nil
+	}
+}
+
+// Success records a request that reached a healthy upstream, closing the
+// breaker.
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state != StateClosed {
+		slog.Info("upstream circuit closed", "source", b.source)
+		metrics.CircuitOpen.WithLabelValues(b.source).Set(0)
+	}
+	b.state = StateClosed
+	b.failures = 0
+}
+
+// Failure records a failed request, opening the breaker once threshold
+// consecutive requests have failed or when a probe fails.
+func (b *Breaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateHalfOpen:
+		b.open()
+	case StateClosed:
+		b.failures++
+		if b.failures >= b.threshold {
+			b.open()
+		}
+	}
+}
+
+// open opens the breaker. The caller must hold b.mu.
+func (b *Breaker) open() {
+	slog.Warn("upstream circuit open, failing requests fast",
+		"source", b.source,
+		"consecutive_failures", b.failures,
+		"cooldown", b.cooldown,
+	)
+	b.state = StateOpen
+	b.openedAt = b.now()
+	metrics.CircuitOpen.WithLabelValues(b.source).Set(1)
+}
+
+func (b *Breaker) unavailable() error {
+	return fmt.Errorf("%s circuit open after %d consecutive failures: %w", b.source, b.threshold, sources.ErrUpstreamUnavailable)
+}
+
+// Transport makes every request through an HTTP transport pass the breaker,
+// and records its outcome. A nil base uses http.DefaultTransport.
+//
+// Server errors (5xx) and transport errors count as failures. Any other
+// response, including 429 and other client errors, shows the upstream is up.
+// Requests cancelled by their caller, and requests refused by the daily
+// quota, are not counted either way.
+func Transport(b *Breaker, base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if err := b.Allow(); err != nil {
+			return nil, err
+		}
+
+		resp, err := base.RoundTrip(req)
+		switch {
+		case err != nil && (errors.Is(err, context.Canceled) || errors.Is(err, sources.ErrQuotaExhausted)):
+			b.release()
+		case err != nil || resp.StatusCode >= 500:
+			b.Failure()
+		default:
+			b.Success()
+		}
+		return resp, err
+	})
+}
+
+// release lets another probe through when a probe ended without showing
+// whether the upstream is up.
+func (b *Breaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == StateHalfOpen {
+		b.state = StateOpen
+		b.openedAt = b.now().Add(-b.cooldown)
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
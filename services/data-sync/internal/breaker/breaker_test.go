@@ -0,0 +1,131 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dealforge/data-sync/internal/sources"
+)
+
+// testBreaker returns a breaker whose clock the test controls.
+func testBreaker(threshold int, cooldown time.Duration) (*Breaker, *time.Time) {
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	b := New("test", threshold, cooldown)
+	b.now = func() time.Time { return now }
+	return b, &now
+}
+
+func TestBreaker_OpensAfterConsecutiveFailures(t *testing.T) {
+	b, _ := testBreaker(3, time.Minute)
+
+	b.Failure()
+	b.Failure()
+	b.Success() // A success resets the count
+	b.Failure()
+	b.Failure()
+	if err := b.Allow(); err != nil {
+		t.Fatalf("expected the breaker to stay closed, got %v", err)
+	}
+
+	b.Failure()
+	if b.State() != StateOpen {
+		t.Fatalf("expected the breaker to open, got %s", b.State())
+	}
+	if err := b.Allow(); !errors.Is(err, sources.ErrUpstreamUnavailable) {
+		t.Errorf("expected ErrUpstreamUnavailable, got %v", err)
+	}
+}
+
+func TestBreaker_HalfOpenProbe(t *testing.T) {
+	b, now := testBreaker(1, time.Minute)
+	b.Failure()
+
+	*now = now.Add(time.Minute)
+	if err := b.Allow(); err != nil {
+		t.Fatalf("expected a probe after the cooldown, got %v", err)
+	}
+	// Only one probe at a time
+	if err := b.Allow(); !errors.Is(err, sources.ErrUpstreamUnavailable) {
+		t.Errorf("expected a second request during the probe to fail fast, got %v", err)
+	}
+
+	// A failed probe opens the breaker for another cooldown
+	b.Failure()
+	if err := b.Allow(); !errors.Is(err, sources.ErrUpstreamUnavailable) {
+		t.Errorf("expected the breaker to reopen, got %v", err)
+	}
+
+	*now = now.Add(time.Minute)
+	if err := b.Allow(); err != nil {
+		t.Fatalf("expected another probe, got %v", err)
+	}
+	b.Success()
+	if b.State() != StateClosed {
+		t.Errorf("expected a successful probe to close the breaker, got %s", b.State())
+	}
+}
+
+func TestTransport_FailsFastWhileOpen(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: Transport(New("test", 3, time.Hour), nil)}
+	for i := 0; i < 10; i++ {
+		resp, err := client.Get(server.URL)
+		if err == nil {
+			resp.Body.Close()
+			continue
+		}
+		if !errors.Is(err, sources.ErrUpstreamUnavailable) {
+			t.Errorf("request %d: expected ErrUpstreamUnavailable, got %v", i+1, err)
+		}
+	}
+
+	if requests != 3 {
+		t.Errorf("expected 3 requests to reach the server, got %d", requests)
+	}
+}
+
+func TestTransport_ClientErrorsAreHealthy(t *testing.T) {
+	b := New("test", 1, time.Hour)
+	base := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusTooManyRequests, Body: http.NoBody, Request: r}, nil
+	})
+
+	client := &http.Client{Transport: Transport(b, base)}
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get("http://upstream.test/")
+		if err != nil {
+			t.Fatalf("request %d: unexpected error: %v", i+1, err)
+		}
+		resp.Body.Close()
+	}
+	if b.State() != StateClosed {
+		t.Errorf("expected 429s to leave the breaker closed, got %s", b.State())
+	}
+}
+
+func TestTransport_IgnoresCancelledAndQuotaErrors(t *testing.T) {
+	b := New("test", 1, time.Hour)
+	errs := []error{context.Canceled, fmt.Errorf("daily quota used up: %w", sources.ErrQuotaExhausted)}
+	for _, want := range errs {
+		base := roundTripperFunc(func(*http.Request) (*http.Response, error) { return nil, want })
+		client := &http.Client{Transport: Transport(b, base)}
+		if _, err := client.Get("http://upstream.test/"); !errors.Is(err, want) {
+			t.Errorf("expected %v, got %v", want, err)
+		}
+	}
+	if b.State() != StateClosed {
+		t.Errorf("expected the breaker to stay closed, got %s", b.State())
+	}
+}
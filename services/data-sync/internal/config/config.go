@@ -4,6 +4,9 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // Config holds all configuration values for the data sync service.
@@ -20,6 +23,30 @@ type Config struct {
 	MaxConcurrent int  // Max concurrent API requests
 	MaxRetries    int  // Max retry attempts for transient failures
 	DryRun        bool // If true, don't write to DB
+
+	// MaxResumeAge is how old a session may be for --resume=latest to
+	// continue it (SYNC_RESUME_MAX_AGE, e.g. "72h"). Zero disables the limit.
+	MaxResumeAge time.Duration
+
+	// Service mode
+	APIToken    string // Bearer token required by the serve mode HTTP API
+	MetricsAddr string // Address long-running modes serve Prometheus metrics on; empty disables it
+
+	// Tracing
+	TraceExporter string // "none", "otlp" (endpoint from OTEL_EXPORTER_OTLP_ENDPOINT) or "file"
+	TraceFile     string // Where the file exporter appends spans
+
+	// Raw response archive
+	ArchiveURL        string // Directory or s3://bucket/prefix to archive upstream responses in; empty disables it
+	ArchiveS3Endpoint string // S3-compatible endpoint for s3:// archives (default AWS S3)
+
+	// Per-source upstream limits, keyed by source name
+	rateLimits  map[string]float64 // SYNC_RATE_LIMIT_<SOURCE>, requests per second
+	dailyQuotas map[string]int     // SYNC_DAILY_QUOTA_<SOURCE>, requests per day
+
+	// env is a snapshot of the process environment taken at Load time,
+	// used to look up source-specific settings by name.
+	env map[string]string
 }
 
 // Load reads configuration from environment variables.
@@ -32,6 +59,39 @@ func Load() (*Config, error) {
 		MaxConcurrent: 1, // Sequential requests to respect BLS rate limits
 		MaxRetries:    3, // Retry transient failures up to 3 times
 		DryRun:        os.Getenv("DRY_RUN") == "true",
+		MaxResumeAge:  7 * 24 * time.Hour, // Sessions older than a week are started afresh
+		APIToken:      os.Getenv("SYNC_API_TOKEN"),
+		MetricsAddr:   os.Getenv("SYNC_METRICS_ADDR"),
+		TraceExporter: os.Getenv("SYNC_TRACE_EXPORTER"),
+		TraceFile:     os.Getenv("SYNC_TRACE_FILE"),
+		env:           make(map[string]string),
+		rateLimits:    make(map[string]float64),
+		dailyQuotas:   make(map[string]int),
+
+		ArchiveURL:        os.Getenv("SYNC_ARCHIVE"),
+		ArchiveS3Endpoint: os.Getenv("SYNC_ARCHIVE_S3_ENDPOINT"),
+	}
+
+	for _, kv := range os.Environ() {
+		if key, value, ok := strings.Cut(kv, "="); ok {
+			cfg.env[key] = value
+		}
+	}
+
+	if raw := cfg.env["SYNC_RESUME_MAX_AGE"]; raw != "" {
+		maxAge, err := time.ParseDuration(raw)
+		if err != nil || maxAge < 0 {
+			return nil, fmt.Errorf("invalid SYNC_RESUME_MAX_AGE %q: expected a duration such as 72h", raw)
+		}
+		cfg.MaxResumeAge = maxAge
+	}
+
+	if err := cfg.loadUpstreamLimits(); err != nil {
+		return nil, err
+	}
+
+	if cfg.TraceFile == "" {
+		cfg.TraceFile = "data-sync-traces.json"
 	}
 
 	if cfg.DatabaseURL == "" {
@@ -41,20 +101,53 @@ func Load() (*Config, error) {
 	return cfg, nil
 }
 
-// Validate checks that required API keys are set based on which sources will be synced.
-func (c *Config) Validate(sources []string) error {
-	for _, source := range sources {
-		switch source {
-		case "hud":
-			if c.HUDAPIKey == "" {
-				return fmt.Errorf("HUD_API_KEY is required for HUD data sync")
+// Get returns the value of a configuration setting by its environment
+// variable name, or an empty string if it is not set.
+func (c *Config) Get(key string) string {
+	return c.env[key]
+}
+
+// RateLimit returns the requests per second SYNC_RATE_LIMIT_<SOURCE> allows
+// a source, if set. Zero means unlimited.
+func (c *Config) RateLimit(source string) (float64, bool) {
+	rps, ok := c.rateLimits[source]
+	return rps, ok
+}
+
+// DailyQuota returns the daily request quota SYNC_DAILY_QUOTA_<SOURCE> sets
+// for a source, if set. Zero means no quota.
+func (c *Config) DailyQuota(source string) (int, bool) {
+	quota, ok := c.dailyQuotas[source]
+	return quota, ok
+}
+
+// loadUpstreamLimits parses the per-source rate limit and daily quota
+// settings.
+func (c *Config) loadUpstreamLimits() error {
+	for key, raw := range c.env {
+		if source, ok := strings.CutPrefix(key, "SYNC_RATE_LIMIT_"); ok && source != "" {
+			rps, err := strconv.ParseFloat(raw, 64)
+			if err != nil || rps < 0 {
+				return fmt.Errorf("invalid %s %q: expected requests per second, such as 4 or 0.5", key, raw)
 			}
-		case "census":
-			// Census API works without a key but has rate limits
-			// Key is recommended for production use
-		case "bls":
-			// BLS API works without a key but has rate limits
-			// Key is recommended for production use
+			c.rateLimits[strings.ToLower(source)] = rps
+		}
+		if source, ok := strings.CutPrefix(key, "SYNC_DAILY_QUOTA_"); ok && source != "" {
+			quota, err := strconv.Atoi(raw)
+			if err != nil || quota < 0 {
+				return fmt.Errorf("invalid %s %q: expected a number of requests per day", key, raw)
+			}
+			c.dailyQuotas[strings.ToLower(source)] = quota
+		}
+	}
+	return nil
+}
+
+// Validate checks that every setting a source requires is present.
+func (c *Config) Validate(source string, required []string) error {
+	for _, key := range required {
+		if c.Get(key) == "" {
+			return fmt.Errorf("%s is required for %s data sync", key, source)
 		}
 	}
 	return nil
@@ -0,0 +1,126 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/dealforge/data-sync/internal/metrics"
+	"github.com/dealforge/data-sync/internal/tracing"
+)
+
+// stagingRow is the staging table column holding each row's position in the
+// load, so that the last of several rows with the same key wins, as it would
+// with one upsert per row.
+const stagingRow = "staging_row"
+
+// bulkTable describes how records are bulk loaded into a market data table.
+type bulkTable struct {
+	name     string   // Target table
+	idPrefix string   // Prefix of generated row IDs
+	columns  []string // Columns loaded from each record, in row order
+	key      []string // Conflict target; the other columns are updated
+}
+
+// stagingTable is the temporary table rows are copied into before the merge.
+func (t bulkTable) stagingTable() string {
+	return t.name + "_staging"
+}
+
+// createStagingSQL creates an empty staging table with the loaded columns of
+// the target table, dropped when the transaction ends.
+func (t bulkTable) createStagingSQL() string {
+	return fmt.Sprintf(
+		`CREATE TEMP TABLE %s ON COMMIT DROP AS SELECT %s, 0 AS %s FROM %s WITH NO DATA`,
+		pgx.Identifier{t.stagingTable()}.Sanitize(), strings.Join(t.columns, ", "), stagingRow, t.name,
+	)
+}
+
+// mergeSQL upserts the staging table into the target table in one statement.
+// Postgres rejects an upsert that touches the same row twice, so only the
+// last staged row for each key is merged.
+func (t bulkTable) mergeSQL() string {
+	var updates []string
+	for _, col := range t.columns {
+		if !slices.Contains(t.key, col) {
+			updates = append(updates, fmt.Sprintf("%s = EXCLUDED.%s", col, col))
+		}
+	}
+	updates = append(updates, "source_updated_at = EXCLUDED.source_updated_at", "updated_at = NOW()")
+
+	columns := strings.Join(t.columns, ", ")
+	key := strings.Join(t.key, ", ")
+	return fmt.Sprintf(`
+		INSERT INTO %s (id, %s, source_updated_at, created_at, updated_at)
+		SELECT DISTINCT ON (%s) '%s' || gen_random_uuid()::text, %s, NOW(), NOW(), NOW()
+		FROM %s
+		ORDER BY %s, %s DESC
+		ON CONFLICT (%s)
+		DO UPDATE SET
+			%s
+	`,
+		t.name, columns,
+		key, t.idPrefix, columns,
+		pgx.Identifier{t.stagingTable()}.Sanitize(),
+		key, stagingRow,
+		key,
+		strings.Join(updates, ",\n\t\t\t"),
+	)
+}
+
+// bulkUpsert loads rows, each holding the values of t.columns, into a
+// staging table with COPY and merges them into the target table, all in one
+// transaction: either every row is written or none is.
+func (c *Client) bulkUpsert(ctx context.Context, t bulkTable, rows [][]any) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	ctx, span := tracing.Start(ctx, "db.bulk_upsert", tracing.Table.String(t.name), tracing.Records.Int(len(rows)))
+	defer span.End()
+
+	merged, err := c.copyAndMerge(ctx, t, rows)
+	if err != nil {
+		tracing.Fail(span, err)
+		return err
+	}
+
+	metrics.RecordsUpserted.WithLabelValues(t.name).Add(float64(merged))
+	return nil
+}
+
+// copyAndMerge runs the load transaction for bulkUpsert, returning the
+// number of rows merged.
+func (c *Client) copyAndMerge(ctx context.Context, t bulkTable, rows [][]any) (int64, error) {
+	tx, err := c.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin %s load: %w", t.name, err)
+	}
+	defer tx.Rollback(context.Background()) // No-op once committed
+
+	if _, err := tx.Exec(ctx, t.createStagingSQL()); err != nil {
+		return 0, fmt.Errorf("failed to create %s staging table: %w", t.name, err)
+	}
+
+	staged := make([][]any, len(rows))
+	for i, row := range rows {
+		staged[i] = append(row[:len(row):len(row)], i)
+	}
+	columns := append(t.columns[:len(t.columns):len(t.columns)], stagingRow)
+	if _, err := tx.CopyFrom(ctx, pgx.Identifier{t.stagingTable()}, columns, pgx.CopyFromRows(staged)); err != nil {
+		return 0, fmt.Errorf("failed to copy %s records: %w", t.name, err)
+	}
+
+	tag, err := tx.Exec(ctx, t.mergeSQL())
+	if err != nil {
+		return 0, fmt.Errorf("failed to merge %s records: %w", t.name, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit %s load: %w", t.name, err)
+	}
+	return tag.RowsAffected(), nil
+}
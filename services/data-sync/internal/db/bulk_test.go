@@ -0,0 +1,175 @@
+package db
+
+import (
+	"context"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestBulkTables_KeysAreLoadedColumns(t *testing.T) {
+	for _, table := range []bulkTable{hudFMRTable, censusDemographicTable, blsEmploymentTable} {
+		for _, col := range table.key {
+			if !slices.Contains(table.columns, col) {
+				t.Errorf("%s: key column %q is not loaded", table.name, col)
+			}
+		}
+	}
+}
+
+func TestBulkTable_MergeSQL(t *testing.T) {
+	table := bulkTable{
+		name:     "bls_employment",
+		idPrefix: "bls_",
+		columns:  []string{"area_code", "year", "month", "labor_force"},
+		key:      []string{"area_code", "year", "month"},
+	}
+	sql := table.mergeSQL()
+
+	for _, want := range []string{
+		`INSERT INTO bls_employment (id, area_code, year, month, labor_force, source_updated_at, created_at, updated_at)`,
+		`SELECT DISTINCT ON (area_code, year, month) 'bls_' || gen_random_uuid()::text, area_code, year, month, labor_force, NOW(), NOW(), NOW()`,
+		`FROM "bls_employment_staging"`,
+		`ORDER BY area_code, year, month, staging_row DESC`,
+		`ON CONFLICT (area_code, year, month)`,
+		`labor_force = EXCLUDED.labor_force`,
+	} {
+		if !strings.Contains(sql, want) {
+			t.Errorf("expected the merge to contain %q, got:\n%s", want, sql)
+		}
+	}
+	if strings.Contains(sql, "year = EXCLUDED.year") {
+		t.Errorf("expected key columns not to be updated, got:\n%s", sql)
+	}
+}
+
+func TestBulkTable_CreateStagingSQL(t *testing.T) {
+	table := bulkTable{name: "hud_fair_market_rents", columns: []string{"entity_code", "zip_code"}}
+
+	want := `CREATE TEMP TABLE "hud_fair_market_rents_staging" ON COMMIT DROP AS SELECT entity_code, zip_code, 0 AS staging_row FROM hud_fair_market_rents WITH NO DATA`
+	if got := table.createStagingSQL(); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+// testClient connects to the database in TEST_DATABASE_URL, skipping the
+// test if it is not set.
+func testClient(t *testing.T) *Client {
+	t.Helper()
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	c, err := NewClient(context.Background(), url)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(c.Close)
+	return c
+}
+
+func TestBulkUpsert_LastDuplicateWins(t *testing.T) {
+	c := testClient(t)
+	ctx := context.Background()
+
+	table := bulkTable{
+		name:     "bulk_upsert_test",
+		idPrefix: "tst_",
+		columns:  []string{"area_code", "year", "month", "labor_force"},
+		key:      []string{"area_code", "year", "month"},
+	}
+	setup := []string{
+		`DROP TABLE IF EXISTS bulk_upsert_test`,
+		`CREATE TABLE bulk_upsert_test (
+			id text PRIMARY KEY,
+			area_code text NOT NULL,
+			year integer NOT NULL,
+			month integer NOT NULL,
+			labor_force integer,
+			source_updated_at timestamp,
+			created_at timestamp NOT NULL,
+			updated_at timestamp NOT NULL,
+			UNIQUE (area_code, year, month)
+		)`,
+	}
+	for _, stmt := range setup {
+		if _, err := c.pool.Exec(ctx, stmt); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	t.Cleanup(func() { c.pool.Exec(context.Background(), `DROP TABLE IF EXISTS bulk_upsert_test`) })
+
+	laborForce := func(v int) *int { return &v }
+	load := func(rows ...[]any) {
+		t.Helper()
+		if err := c.bulkUpsert(ctx, table, rows); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	read := func() map[string]*int {
+		t.Helper()
+		rows, err := c.pool.Query(ctx, `SELECT area_code, labor_force FROM bulk_upsert_test`)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer rows.Close()
+		got := make(map[string]*int)
+		for rows.Next() {
+			var area string
+			var lf *int
+			if err := rows.Scan(&area, &lf); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got[area] = lf
+		}
+		if err := rows.Err(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return got
+	}
+
+	load(
+		[]any{"A", 2024, 1, laborForce(100)},
+		[]any{"B", 2024, 1, laborForce(300)},
+		[]any{"A", 2024, 1, laborForce(200)},
+		[]any{"C", 2024, 1, (*int)(nil)},
+		[]any{"A", 2024, 1, laborForce(250)},
+	)
+	got := read()
+	if len(got) != 3 {
+		t.Fatalf("expected 3 rows, got %d", len(got))
+	}
+	if got["A"] == nil || *got["A"] != 250 {
+		t.Errorf("expected the last duplicate of A to win with 250, got %v", got["A"])
+	}
+	if got["B"] == nil || *got["B"] != 300 {
+		t.Errorf("expected B to be 300, got %v", got["B"])
+	}
+	if got["C"] != nil {
+		t.Errorf("expected C to have no labor force, got %d", *got["C"])
+	}
+
+	var idBefore string
+	if err := c.pool.QueryRow(ctx, `SELECT id FROM bulk_upsert_test WHERE area_code = 'A'`).Scan(&idBefore); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// A second load updates the existing rows in place
+	load([]any{"A", 2024, 1, laborForce(999)}, []any{"A", 2024, 1, laborForce(998)})
+	got = read()
+	if got["A"] == nil || *got["A"] != 998 {
+		t.Errorf("expected A to be updated to 998, got %v", got["A"])
+	}
+
+	var idAfter string
+	if err := c.pool.QueryRow(ctx, `SELECT id FROM bulk_upsert_test WHERE area_code = 'A'`).Scan(&idAfter); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if idAfter != idBefore {
+		t.Errorf("expected the update to keep row ID %s, got %s", idBefore, idAfter)
+	}
+	if !strings.HasPrefix(idAfter, "tst_") {
+		t.Errorf("expected a generated ID with the table prefix, got %s", idAfter)
+	}
+}
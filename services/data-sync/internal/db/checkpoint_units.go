@@ -0,0 +1,147 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Checkpoint unit statuses.
+const (
+	UnitPending   = "pending"
+	UnitCompleted = "completed"
+	UnitFailed    = "failed"
+	UnitDeferred  = "deferred" // Not attempted because the upstream was unavailable
+)
+
+// CheckpointUnit is the status of a single work unit in a sync session.
+type CheckpointUnit struct {
+	SyncSessionID string    `json:"sync_session_id"`
+	UnitKey       string    `json:"unit_key"`
+	Status        string    `json:"status"` // 'pending', 'completed', 'failed', 'deferred'
+	Attempts      int       `json:"attempts"`
+	LastError     *string   `json:"last_error"`
+	RecordCount   int       `json:"record_count"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// AddCheckpointUnits records units of a session with the given status.
+// Units that are already recorded are left unchanged.
+func (c *Client) AddCheckpointUnits(ctx context.Context, sessionID string, unitKeys []string, status string) error {
+	if len(unitKeys) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(unitKeys))
+	for i := range unitKeys {
+		ids[i] = fmt.Sprintf("cku_%s", uuid.New().String())
+	}
+
+	query := `
+		INSERT INTO sync_checkpoint_units (id, sync_session_id, unit_key, status, updated_at)
+		SELECT id, $1, unit_key, $4, NOW()
+		FROM unnest($2::text[], $3::text[]) AS u(id, unit_key)
+		ON CONFLICT (sync_session_id, unit_key) DO NOTHING
+	`
+
+	if _, err := c.pool.Exec(ctx, query, sessionID, ids, unitKeys, status); err != nil {
+		return fmt.Errorf("failed to add checkpoint units: %w", err)
+	}
+
+	return nil
+}
+
+// GetCheckpointUnits retrieves the status of every recorded unit of a session.
+func (c *Client) GetCheckpointUnits(ctx context.Context, sessionID string) ([]*CheckpointUnit, error) {
+	query := `
+		SELECT sync_session_id, unit_key, status, attempts, last_error, record_count, updated_at
+		FROM sync_checkpoint_units
+		WHERE sync_session_id = $1
+		ORDER BY unit_key
+	`
+
+	rows, err := c.pool.Query(ctx, query, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get checkpoint units: %w", err)
+	}
+	defer rows.Close()
+
+	var units []*CheckpointUnit
+	for rows.Next() {
+		unit := &CheckpointUnit{}
+		if err := rows.Scan(
+			&unit.SyncSessionID,
+			&unit.UnitKey,
+			&unit.Status,
+			&unit.Attempts,
+			&unit.LastError,
+			&unit.RecordCount,
+			&unit.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan checkpoint unit: %w", err)
+		}
+		units = append(units, unit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get checkpoint units: %w", err)
+	}
+
+	return units, nil
+}
+
+// CompleteCheckpointUnit marks a unit as persisted with the given number of
+// records.
+func (c *Client) CompleteCheckpointUnit(ctx context.Context, sessionID, unitKey string, recordCount int) error {
+	query := `
+		UPDATE sync_checkpoint_units
+		SET status = 'completed',
+		    attempts = attempts + 1,
+		    last_error = NULL,
+		    record_count = $3,
+		    updated_at = NOW()
+		WHERE sync_session_id = $1 AND unit_key = $2
+	`
+
+	if _, err := c.pool.Exec(ctx, query, sessionID, unitKey, recordCount); err != nil {
+		return fmt.Errorf("failed to complete checkpoint unit: %w", err)
+	}
+
+	return nil
+}
+
+// FailCheckpointUnit marks a unit as failed with the given error.
+func (c *Client) FailCheckpointUnit(ctx context.Context, sessionID, unitKey, lastError string) error {
+	query := `
+		UPDATE sync_checkpoint_units
+		SET status = 'failed',
+		    attempts = attempts + 1,
+		    last_error = $3,
+		    updated_at = NOW()
+		WHERE sync_session_id = $1 AND unit_key = $2
+	`
+
+	if _, err := c.pool.Exec(ctx, query, sessionID, unitKey, lastError); err != nil {
+		return fmt.Errorf("failed to mark checkpoint unit as failed: %w", err)
+	}
+
+	return nil
+}
+
+// DeferCheckpointUnits marks every still-pending unit of a session as
+// deferred, for when a run stops because the upstream is unavailable.
+func (c *Client) DeferCheckpointUnits(ctx context.Context, sessionID string) error {
+	query := `
+		UPDATE sync_checkpoint_units
+		SET status = 'deferred',
+		    updated_at = NOW()
+		WHERE sync_session_id = $1 AND status = 'pending'
+	`
+
+	if _, err := c.pool.Exec(ctx, query, sessionID); err != nil {
+		return fmt.Errorf("failed to defer checkpoint units: %w", err)
+	}
+
+	return nil
+}
@@ -2,46 +2,63 @@ package db
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 )
 
 // SyncCheckpoint represents a sync progress checkpoint record.
 type SyncCheckpoint struct {
-	ID                   string
-	SyncSessionID        string
-	Source               string // 'bls', 'census', 'hud'
-	LastCompletedEntity  *string
-	TotalRecordsSynced   int
-	Status               string // 'in_progress', 'completed', 'rate_limited', 'failed'
-	StartedAt            time.Time
-	LastUpdatedAt        time.Time
+	ID                  string          `json:"id"`
+	SyncSessionID       string          `json:"sync_session_id"`
+	Source              string          `json:"source"` // 'bls', 'census', 'hud'
+	LastCompletedEntity *string         `json:"last_completed_entity"`
+	TotalRecordsSynced  int             `json:"total_records_synced"`
+	Status              string          `json:"status"`     // 'in_progress', 'completed', 'rate_limited', 'upstream_unavailable', 'failed'
+	StartYear           *int            `json:"start_year"` // Year range of time-series sources
+	EndYear             *int            `json:"end_year"`
+	Params              json.RawMessage `json:"params"` // Run parameters the session was started with
+	StartedAt           time.Time       `json:"started_at"`
+	LastUpdatedAt       time.Time       `json:"last_updated_at"`
 }
 
-// CreateCheckpoint creates a new sync checkpoint record.
-func (c *Client) CreateCheckpoint(ctx context.Context, sessionID, source string) (*SyncCheckpoint, error) {
+// CreateCheckpoint creates a new sync checkpoint record with the parameters
+// the session runs with. The year range is nil for sources that do not sync
+// a range of years.
+func (c *Client) CreateCheckpoint(ctx context.Context, sessionID, source string, params interface{}, startYear, endYear *int) (*SyncCheckpoint, error) {
 	id := fmt.Sprintf("chk_%s", uuid.New().String())
 
+	payload, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode checkpoint params: %w", err)
+	}
+
 	query := `
 		INSERT INTO sync_checkpoints (
-			id, sync_session_id, source, total_records_synced, status, started_at, last_updated_at
+			id, sync_session_id, source, total_records_synced, status, start_year, end_year,
+			params, started_at, last_updated_at
 		) VALUES (
-			$1, $2, $3, 0, 'in_progress', NOW(), NOW()
+			$1, $2, $3, 0, 'in_progress', $4, $5, $6, NOW(), NOW()
 		)
 		RETURNING id, sync_session_id, source, last_completed_entity, total_records_synced,
-		          status, started_at, last_updated_at
+		          status, start_year, end_year, params, started_at, last_updated_at
 	`
 
 	checkpoint := &SyncCheckpoint{}
-	err := c.pool.QueryRow(ctx, query, id, sessionID, source).Scan(
+	err = c.pool.QueryRow(ctx, query, id, sessionID, source, startYear, endYear, payload).Scan(
 		&checkpoint.ID,
 		&checkpoint.SyncSessionID,
 		&checkpoint.Source,
 		&checkpoint.LastCompletedEntity,
 		&checkpoint.TotalRecordsSynced,
 		&checkpoint.Status,
+		&checkpoint.StartYear,
+		&checkpoint.EndYear,
+		&checkpoint.Params,
 		&checkpoint.StartedAt,
 		&checkpoint.LastUpdatedAt,
 	)
@@ -53,10 +70,11 @@ func (c *Client) CreateCheckpoint(ctx context.Context, sessionID, source string)
 }
 
 // UpdateCheckpoint updates an existing checkpoint with progress information.
+// An empty lastEntity leaves the last completed entity unchanged.
 func (c *Client) UpdateCheckpoint(ctx context.Context, sessionID string, lastEntity string, recordCount int) error {
 	query := `
 		UPDATE sync_checkpoints
-		SET last_completed_entity = $1,
+		SET last_completed_entity = COALESCE(NULLIF($1, ''), last_completed_entity),
 		    total_records_synced = total_records_synced + $2,
 		    last_updated_at = NOW()
 		WHERE sync_session_id = $3 AND status = 'in_progress'
@@ -96,7 +114,7 @@ func (c *Client) UpdateCheckpointStatus(ctx context.Context, sessionID, status s
 func (c *Client) GetCheckpointBySession(ctx context.Context, sessionID string) (*SyncCheckpoint, error) {
 	query := `
 		SELECT id, sync_session_id, source, last_completed_entity, total_records_synced,
-		       status, started_at, last_updated_at
+		       status, start_year, end_year, params, started_at, last_updated_at
 		FROM sync_checkpoints
 		WHERE sync_session_id = $1
 	`
@@ -109,6 +127,9 @@ func (c *Client) GetCheckpointBySession(ctx context.Context, sessionID string) (
 		&checkpoint.LastCompletedEntity,
 		&checkpoint.TotalRecordsSynced,
 		&checkpoint.Status,
+		&checkpoint.StartYear,
+		&checkpoint.EndYear,
+		&checkpoint.Params,
 		&checkpoint.StartedAt,
 		&checkpoint.LastUpdatedAt,
 	)
@@ -119,12 +140,13 @@ func (c *Client) GetCheckpointBySession(ctx context.Context, sessionID string) (
 	return checkpoint, nil
 }
 
-// GetLastCheckpointForSource retrieves the most recent checkpoint for a given source.
-// This is useful for finding the last sync session to potentially resume from.
+// GetLastCheckpointForSource retrieves the most recent checkpoint for a given source,
+// or nil if the source has none. This is useful for finding the last sync session
+// to potentially resume from.
 func (c *Client) GetLastCheckpointForSource(ctx context.Context, source string) (*SyncCheckpoint, error) {
 	query := `
 		SELECT id, sync_session_id, source, last_completed_entity, total_records_synced,
-		       status, started_at, last_updated_at
+		       status, start_year, end_year, params, started_at, last_updated_at
 		FROM sync_checkpoints
 		WHERE source = $1
 		ORDER BY started_at DESC
@@ -139,12 +161,63 @@ func (c *Client) GetLastCheckpointForSource(ctx context.Context, source string)
 		&checkpoint.LastCompletedEntity,
 		&checkpoint.TotalRecordsSynced,
 		&checkpoint.Status,
+		&checkpoint.StartYear,
+		&checkpoint.EndYear,
+		&checkpoint.Params,
 		&checkpoint.StartedAt,
 		&checkpoint.LastUpdatedAt,
 	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get last checkpoint: %w", err)
 	}
 
 	return checkpoint, nil
 }
+
+// ListCheckpoints retrieves the most recent checkpoints, newest first.
+// An empty source lists checkpoints for every source.
+func (c *Client) ListCheckpoints(ctx context.Context, source string, limit int) ([]*SyncCheckpoint, error) {
+	query := `
+		SELECT id, sync_session_id, source, last_completed_entity, total_records_synced,
+		       status, start_year, end_year, params, started_at, last_updated_at
+		FROM sync_checkpoints
+		WHERE $1 = '' OR source = $1
+		ORDER BY started_at DESC
+		LIMIT $2
+	`
+
+	rows, err := c.pool.Query(ctx, query, source, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list checkpoints: %w", err)
+	}
+	defer rows.Close()
+
+	var checkpoints []*SyncCheckpoint
+	for rows.Next() {
+		checkpoint := &SyncCheckpoint{}
+		if err := rows.Scan(
+			&checkpoint.ID,
+			&checkpoint.SyncSessionID,
+			&checkpoint.Source,
+			&checkpoint.LastCompletedEntity,
+			&checkpoint.TotalRecordsSynced,
+			&checkpoint.Status,
+			&checkpoint.StartYear,
+			&checkpoint.EndYear,
+			&checkpoint.Params,
+			&checkpoint.StartedAt,
+			&checkpoint.LastUpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan checkpoint: %w", err)
+		}
+		checkpoints = append(checkpoints, checkpoint)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list checkpoints: %w", err)
+	}
+
+	return checkpoints, nil
+}
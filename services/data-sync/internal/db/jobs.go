@@ -0,0 +1,114 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Job represents a row of the web app's background jobs table.
+type Job struct {
+	ID         string
+	Type       string // e.g. 'hud_sync', 'census_sync', 'bls_sync'
+	Status     string // 'pending', 'running', 'completed', 'partial', 'rate_limited', 'upstream_unavailable', 'failed', 'cancelled'
+	Parameters json.RawMessage
+	CreatedAt  time.Time
+	Reclaimed  bool // Claimed from a worker that stopped heartbeating it
+}
+
+// ClaimJob marks the oldest pending job of one of the given types as running
+// and returns it, or returns nil if there is none. A running job whose
+// heartbeat is older than staleAfter is claimed too, since the worker
+// running it has died. Rows locked by another worker are skipped, so
+// concurrent workers never claim the same job.
+func (c *Client) ClaimJob(ctx context.Context, types []string, staleAfter time.Duration) (*Job, error) {
+	query := `
+		UPDATE jobs
+		SET status = 'running',
+		    started_at = NOW(),
+		    updated_at = NOW()
+		FROM (
+			SELECT id, status = 'running' AS reclaimed
+			FROM jobs
+			WHERE type::text = ANY($1)
+			  AND (status = 'pending'
+			       OR (status = 'running' AND updated_at < NOW() - make_interval(secs => $2)))
+			ORDER BY created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		) claimed
+		WHERE jobs.id = claimed.id
+		RETURNING jobs.id, jobs.type::text, jobs.status::text, jobs.parameters, jobs.created_at, claimed.reclaimed
+	`
+
+	job := &Job{}
+	err := c.pool.QueryRow(ctx, query, types, staleAfter.Seconds()).Scan(
+		&job.ID,
+		&job.Type,
+		&job.Status,
+		&job.Parameters,
+		&job.CreatedAt,
+		&job.Reclaimed,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim job: %w", err)
+	}
+
+	return job, nil
+}
+
+// HeartbeatJob stores the latest result of a running job. It returns false
+// if the job is no longer running, e.g. because an admin cancelled it.
+func (c *Client) HeartbeatJob(ctx context.Context, id string, result interface{}) (bool, error) {
+	payload, err := json.Marshal(result)
+	if err != nil {
+		return false, fmt.Errorf("failed to encode job result: %w", err)
+	}
+
+	query := `
+		UPDATE jobs
+		SET result = $2,
+		    updated_at = NOW()
+		WHERE id = $1 AND status = 'running'
+	`
+
+	tag, err := c.pool.Exec(ctx, query, id, payload)
+	if err != nil {
+		return false, fmt.Errorf("failed to update job result: %w", err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// FinishJob records the final status, result and error message of a job.
+// A job cancelled while it was running keeps its cancelled status, but still
+// receives the final result.
+func (c *Client) FinishJob(ctx context.Context, id, status string, result interface{}, errorMessage *string) error {
+	payload, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to encode job result: %w", err)
+	}
+
+	query := `
+		UPDATE jobs
+		SET status = CASE WHEN status = 'cancelled' THEN status ELSE $2::job_status END,
+		    result = $3,
+		    error_message = $4,
+		    completed_at = COALESCE(completed_at, NOW()),
+		    updated_at = NOW()
+		WHERE id = $1 AND status IN ('running', 'cancelled')
+	`
+
+	if _, err := c.pool.Exec(ctx, query, id, status, payload, errorMessage); err != nil {
+		return fmt.Errorf("failed to finish job: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,148 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// syncLockNamespace is the first key of every sync advisory lock ("DSNC"),
+// keeping them apart from advisory locks taken by other applications.
+const syncLockNamespace int32 = 0x44534E43
+
+// SyncLock is a held per-source sync lock.
+//
+// The lock is a transaction-level advisory lock held by an open transaction
+// on a dedicated connection. Unlike a session-level lock it cannot leak to
+// another client through a transaction-mode connection pooler (such as
+// Neon's), and it is released automatically if the process dies.
+type SyncLock struct {
+	Source string
+	tx     pgx.Tx
+}
+
+// LockHolder describes the database session holding a sync lock.
+type LockHolder struct {
+	PID             int
+	ApplicationName string
+	ClientAddr      *string
+	Since           *time.Time // Start of the holder's transaction
+}
+
+// String formats the holder for logs and error messages.
+func (h *LockHolder) String() string {
+	s := fmt.Sprintf("pid %d", h.PID)
+	if h.ApplicationName != "" {
+		s += " (" + h.ApplicationName + ")"
+	}
+	if h.ClientAddr != nil {
+		s += " from " + *h.ClientAddr
+	}
+	if h.Since != nil {
+		s += " since " + h.Since.Format(time.RFC3339)
+	}
+	return s
+}
+
+// LockedError is returned when another sync holds a source's lock.
+type LockedError struct {
+	Source string
+	Holder *LockHolder // Nil if the holder could not be identified
+}
+
+func (e *LockedError) Error() string {
+	if e.Holder == nil {
+		return fmt.Sprintf("%s sync is already running elsewhere", e.Source)
+	}
+	return fmt.Sprintf("%s sync is already running elsewhere: lock held by %s", e.Source, e.Holder)
+}
+
+// TryLockSource takes the sync lock for a source without waiting. If another
+// sync holds it, a *LockedError identifying the holder is returned.
+func (c *Client) TryLockSource(ctx context.Context, source string) (*SyncLock, error) {
+	tx, err := c.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin lock transaction: %w", err)
+	}
+
+	var acquired bool
+	if err := tx.QueryRow(ctx, `SELECT pg_try_advisory_xact_lock($1, $2)`, syncLockNamespace, sourceLockKey(source)).Scan(&acquired); err != nil {
+		tx.Rollback(ctx)
+		return nil, fmt.Errorf("failed to take %s sync lock: %w", source, err)
+	}
+	if !acquired {
+		tx.Rollback(ctx)
+		holder, _ := c.SourceLockHolder(ctx, source) // Best effort
+		return nil, &LockedError{Source: source, Holder: holder}
+	}
+
+	return &SyncLock{Source: source, tx: tx}, nil
+}
+
+// LockSource takes the sync lock for a source, waiting until the current
+// holder (if any) releases it or ctx is done.
+func (c *Client) LockSource(ctx context.Context, source string) (*SyncLock, error) {
+	tx, err := c.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin lock transaction: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1, $2)`, syncLockNamespace, sourceLockKey(source)); err != nil {
+		tx.Rollback(context.Background())
+		return nil, fmt.Errorf("failed to take %s sync lock: %w", source, err)
+	}
+
+	return &SyncLock{Source: source, tx: tx}, nil
+}
+
+// Release releases the lock and returns its connection to the pool.
+func (l *SyncLock) Release(ctx context.Context) error {
+	if err := l.tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+		return fmt.Errorf("failed to release %s sync lock: %w", l.Source, err)
+	}
+	return nil
+}
+
+// SourceLockHolder returns the session holding a source's sync lock, or nil
+// if it is not held.
+func (c *Client) SourceLockHolder(ctx context.Context, source string) (*LockHolder, error) {
+	query := `
+		SELECT a.pid, a.application_name, host(a.client_addr), a.xact_start
+		FROM pg_locks l
+		JOIN pg_stat_activity a ON a.pid = l.pid
+		WHERE l.locktype = 'advisory'
+		  AND l.granted
+		  AND l.classid = $1::oid
+		  AND l.objid = $2::oid
+		  AND l.objsubid = 2
+		LIMIT 1
+	`
+
+	holder := &LockHolder{}
+	err := c.pool.QueryRow(ctx, query, syncLockNamespace, sourceLockKey(source)).Scan(
+		&holder.PID,
+		&holder.ApplicationName,
+		&holder.ClientAddr,
+		&holder.Since,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up %s sync lock holder: %w", source, err)
+	}
+
+	return holder, nil
+}
+
+// sourceLockKey derives the second advisory lock key from a source name.
+// It is kept non-negative so it compares equal to pg_locks.objid.
+func sourceLockKey(source string) int32 {
+	h := fnv.New32a()
+	h.Write([]byte(source))
+	return int32(h.Sum32() & 0x7fffffff)
+}
@@ -0,0 +1,39 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSourceLockKey(t *testing.T) {
+	seen := make(map[int32]string)
+	for _, source := range []string{"hud", "census", "bls"} {
+		key := sourceLockKey(source)
+		if key < 0 {
+			t.Errorf("sourceLockKey(%q) = %d, expected a non-negative key", source, key)
+		}
+		if key != sourceLockKey(source) {
+			t.Errorf("sourceLockKey(%q) is not stable", source)
+		}
+		if other, dup := seen[key]; dup {
+			t.Errorf("sourceLockKey(%q) collides with %q", source, other)
+		}
+		seen[key] = source
+	}
+}
+
+func TestLockedError(t *testing.T) {
+	err := &LockedError{Source: "bls"}
+	if got := err.Error(); got != "bls sync is already running elsewhere" {
+		t.Errorf("unexpected message without holder: %q", got)
+	}
+
+	addr := "10.0.0.7"
+	since := time.Date(2024, 8, 20, 5, 0, 0, 0, time.UTC)
+	err.Holder = &LockHolder{PID: 4242, ApplicationName: "data-sync@runner-1 pid 17", ClientAddr: &addr, Since: &since}
+
+	want := "bls sync is already running elsewhere: lock held by pid 4242 (data-sync@runner-1 pid 17) from 10.0.0.7 since 2024-08-20T05:00:00Z"
+	if got := err.Error(); got != want {
+		t.Errorf("unexpected message:\n got: %q\nwant: %q", got, want)
+	}
+}
@@ -4,6 +4,7 @@ package db
 import (
 	"context"
 	"fmt"
+	"os"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -24,6 +25,11 @@ func NewClient(ctx context.Context, databaseURL string) (*Client, error) {
 	config.MaxConns = 10
 	config.MinConns = 2
 
+	// Name connections so other syncs can report who holds a sync lock
+	if _, ok := config.ConnConfig.RuntimeParams["application_name"]; !ok {
+		config.ConnConfig.RuntimeParams["application_name"] = applicationName()
+	}
+
 	pool, err := pgxpool.NewWithConfig(ctx, config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create connection pool: %w", err)
@@ -49,3 +55,13 @@ func (c *Client) Close() {
 func (c *Client) Pool() *pgxpool.Pool {
 	return c.pool
 }
+
+// applicationName identifies this process in pg_stat_activity, e.g.
+// "data-sync@runner-42 pid 1234".
+func applicationName() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("data-sync@%s pid %d", host, os.Getpid())
+}
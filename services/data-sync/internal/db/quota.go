@@ -0,0 +1,74 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// QuotaUsed returns how many requests have been made against a source's
+// daily quota on a day.
+func (c *Client) QuotaUsed(ctx context.Context, source string, day time.Time) (int, error) {
+	query := `
+		SELECT requests
+		FROM upstream_quota_usage
+		WHERE source = $1 AND day = $2
+	`
+
+	var used int
+	err := c.pool.QueryRow(ctx, query, source, day).Scan(&used)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get quota usage: %w", err)
+	}
+
+	return used, nil
+}
+
+// ConsumeQuota records one request against a source's daily quota, unless
+// the quota is already used up. It reports whether the request may be made.
+func (c *Client) ConsumeQuota(ctx context.Context, source string, day time.Time, quota int) (bool, error) {
+	query := `
+		INSERT INTO upstream_quota_usage (source, day, requests, updated_at)
+		VALUES ($1, $2, 1, NOW())
+		ON CONFLICT (source, day) DO UPDATE SET
+			requests = upstream_quota_usage.requests + 1,
+			updated_at = EXCLUDED.updated_at
+		WHERE upstream_quota_usage.requests < $3
+		RETURNING requests
+	`
+
+	var used int
+	err := c.pool.QueryRow(ctx, query, source, day, quota).Scan(&used)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to consume quota: %w", err)
+	}
+
+	return used <= quota, nil
+}
+
+// ExhaustQuota marks a source's daily quota as used up, e.g. when the
+// upstream API refuses requests before the recorded usage reaches it.
+func (c *Client) ExhaustQuota(ctx context.Context, source string, day time.Time, quota int) error {
+	query := `
+		INSERT INTO upstream_quota_usage (source, day, requests, updated_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (source, day) DO UPDATE SET
+			requests = GREATEST(upstream_quota_usage.requests, EXCLUDED.requests),
+			updated_at = EXCLUDED.updated_at
+	`
+
+	if _, err := c.pool.Exec(ctx, query, source, day, quota); err != nil {
+		return fmt.Errorf("failed to exhaust quota: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,134 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Sync run statuses.
+const (
+	RunCompleted   = "completed"            // Every unit synced
+	RunPartial     = "partial"              // Finished, but some units failed
+	RunRateLimited = "rate_limited"         // Stopped early by the upstream quota
+	RunUnavailable = "upstream_unavailable" // Stopped early by an upstream outage
+	RunFailed      = "failed"
+	RunCancelled   = "cancelled"
+)
+
+// SyncRun is one run of a source in the sync run ledger.
+type SyncRun struct {
+	ID            string          `json:"id"`
+	Source        string          `json:"source"`
+	SyncSessionID *string         `json:"sync_session_id"`
+	Status        string          `json:"status"` // 'completed', 'partial', 'rate_limited', 'upstream_unavailable', 'failed', 'cancelled'
+	Params        json.RawMessage `json:"params"`
+	Vintage       *string         `json:"vintage"` // Upstream vintage, if the source reports one
+	Successful    int             `json:"successful"`
+	Failed        int             `json:"failed"`
+	Skipped       int             `json:"skipped"`
+	Errors        []string        `json:"errors"`
+	ErrorMessage  *string         `json:"error_message"` // Why a failed run stopped
+	StartedAt     time.Time       `json:"started_at"`
+	FinishedAt    time.Time       `json:"finished_at"`
+}
+
+// RecordSyncRun adds a finished run to the ledger, filling in its ID.
+func (c *Client) RecordSyncRun(ctx context.Context, run *SyncRun) error {
+	run.ID = fmt.Sprintf("run_%s", uuid.New().String())
+
+	errs, err := json.Marshal(run.Errors)
+	if err != nil {
+		return fmt.Errorf("failed to encode sync run errors: %w", err)
+	}
+
+	query := `
+		INSERT INTO sync_runs (
+			id, source, sync_session_id, status, params, vintage,
+			successful, failed, skipped, errors, error_message, started_at, finished_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
+		)
+	`
+
+	_, err = c.pool.Exec(ctx, query,
+		run.ID,
+		run.Source,
+		run.SyncSessionID,
+		run.Status,
+		run.Params,
+		run.Vintage,
+		run.Successful,
+		run.Failed,
+		run.Skipped,
+		errs,
+		run.ErrorMessage,
+		run.StartedAt,
+		run.FinishedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record sync run: %w", err)
+	}
+
+	return nil
+}
+
+// LatestSyncRuns returns the most recent run of each source with one of the
+// given statuses (any status if none are given), ordered by source.
+func (c *Client) LatestSyncRuns(ctx context.Context, statuses ...string) ([]*SyncRun, error) {
+	query := `
+		SELECT DISTINCT ON (source)
+		       id, source, sync_session_id, status, params, vintage,
+		       successful, failed, skipped, errors, error_message, started_at, finished_at
+		FROM sync_runs
+		WHERE cardinality($1::text[]) = 0 OR status = ANY($1)
+		ORDER BY source, finished_at DESC
+	`
+
+	if statuses == nil {
+		statuses = []string{}
+	}
+
+	rows, err := c.pool.Query(ctx, query, statuses)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest sync runs: %w", err)
+	}
+	defer rows.Close()
+
+	var runs []*SyncRun
+	for rows.Next() {
+		run := &SyncRun{}
+		var errs []byte
+		if err := rows.Scan(
+			&run.ID,
+			&run.Source,
+			&run.SyncSessionID,
+			&run.Status,
+			&run.Params,
+			&run.Vintage,
+			&run.Successful,
+			&run.Failed,
+			&run.Skipped,
+			&errs,
+			&run.ErrorMessage,
+			&run.StartedAt,
+			&run.FinishedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan sync run: %w", err)
+		}
+		if len(errs) > 0 {
+			if err := json.Unmarshal(errs, &run.Errors); err != nil {
+				return nil, fmt.Errorf("failed to decode sync run errors: %w", err)
+			}
+		}
+		runs = append(runs, run)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get latest sync runs: %w", err)
+	}
+
+	return runs, nil
+}
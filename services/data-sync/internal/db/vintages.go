@@ -0,0 +1,47 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// GetSyncedVintage returns the vintage last synced for a source and scope,
+// or an empty string if none has been recorded.
+func (c *Client) GetSyncedVintage(ctx context.Context, source, scope string) (string, error) {
+	query := `
+		SELECT vintage
+		FROM sync_vintages
+		WHERE source = $1 AND scope = $2
+	`
+
+	var vintage string
+	err := c.pool.QueryRow(ctx, query, source, scope).Scan(&vintage)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get synced vintage: %w", err)
+	}
+
+	return vintage, nil
+}
+
+// SetSyncedVintage records the vintage synced for a source and scope.
+func (c *Client) SetSyncedVintage(ctx context.Context, source, scope, vintage string) error {
+	query := `
+		INSERT INTO sync_vintages (source, scope, vintage, synced_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (source, scope) DO UPDATE SET
+			vintage = EXCLUDED.vintage,
+			synced_at = EXCLUDED.synced_at
+	`
+
+	if _, err := c.pool.Exec(ctx, query, source, scope, vintage); err != nil {
+		return fmt.Errorf("failed to set synced vintage: %w", err)
+	}
+
+	return nil
+}
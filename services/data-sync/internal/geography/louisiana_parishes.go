@@ -0,0 +1,71 @@
+package geography
+
+// LouisianaParishes contains all 64 Louisiana parishes, which the Census
+// Bureau and BLS treat as county equivalents.
+// FIPS codes are the 3-digit parish portion (state code 22 is implied).
+var LouisianaParishes = []County{
+	{"001", "Acadia"},
+	{"003", "Allen"},
+	{"005", "Ascension"},
+	{"007", "Assumption"},
+	{"009", "Avoyelles"},
+	{"011", "Beauregard"},
+	{"013", "Bienville"},
+	{"015", "Bossier"},
+	{"017", "Caddo"},
+	{"019", "Calcasieu"},
+	{"021", "Caldwell"},
+	{"023", "Cameron"},
+	{"025", "Catahoula"},
+	{"027", "Claiborne"},
+	{"029", "Concordia"},
+	{"031", "De Soto"},
+	{"033", "East Baton Rouge"},
+	{"035", "East Carroll"},
+	{"037", "East Feliciana"},
+	{"039", "Evangeline"},
+	{"041", "Franklin"},
+	{"043", "Grant"},
+	{"045", "Iberia"},
+	{"047", "Iberville"},
+	{"049", "Jackson"},
+	{"051", "Jefferson"},
+	{"053", "Jefferson Davis"},
+	{"055", "Lafayette"},
+	{"057", "Lafourche"},
+	{"059", "LaSalle"},
+	{"061", "Lincoln"},
+	{"063", "Livingston"},
+	{"065", "Madison"},
+	{"067", "Morehouse"},
+	{"069", "Natchitoches"},
+	{"071", "Orleans"},
+	{"073", "Ouachita"},
+	{"075", "Plaquemines"},
+	{"077", "Pointe Coupee"},
+	{"079", "Rapides"},
+	{"081", "Red River"},
+	{"083", "Richland"},
+	{"085", "Sabine"},
+	{"087", "St. Bernard"},
+	{"089", "St. Charles"},
+	{"091", "St. Helena"},
+	{"093", "St. James"},
+	{"095", "St. John the Baptist"},
+	{"097", "St. Landry"},
+	{"099", "St. Martin"},
+	{"101", "St. Mary"},
+	{"103", "St. Tammany"},
+	{"105", "Tangipahoa"},
+	{"107", "Tensas"},
+	{"109", "Terrebonne"},
+	{"111", "Union"},
+	{"113", "Vermilion"},
+	{"115", "Vernon"},
+	{"117", "Washington"},
+	{"119", "Webster"},
+	{"121", "West Baton Rouge"},
+	{"123", "West Carroll"},
+	{"125", "West Feliciana"},
+	{"127", "Winn"},
+}
@@ -0,0 +1,41 @@
+package geography
+
+// NewMexicoCounties contains all 33 New Mexico counties.
+// FIPS codes are the 3-digit county portion (state code 35 is implied).
+// Cibola (006) and Los Alamos (028) were created after the original
+// alphabetical numbering, so they use even codes.
+var NewMexicoCounties = []County{
+	{"001", "Bernalillo"},
+	{"003", "Catron"},
+	{"005", "Chaves"},
+	{"006", "Cibola"},
+	{"007", "Colfax"},
+	{"009", "Curry"},
+	{"011", "De Baca"},
+	{"013", "Doña Ana"},
+	{"015", "Eddy"},
+	{"017", "Grant"},
+	{"019", "Guadalupe"},
+	{"021", "Harding"},
+	{"023", "Hidalgo"},
+	{"025", "Lea"},
+	{"027", "Lincoln"},
+	{"028", "Los Alamos"},
+	{"029", "Luna"},
+	{"031", "McKinley"},
+	{"033", "Mora"},
+	{"035", "Otero"},
+	{"037", "Quay"},
+	{"039", "Rio Arriba"},
+	{"041", "Roosevelt"},
+	{"043", "Sandoval"},
+	{"045", "San Juan"},
+	{"047", "San Miguel"},
+	{"049", "Santa Fe"},
+	{"051", "Sierra"},
+	{"053", "Socorro"},
+	{"055", "Taos"},
+	{"057", "Torrance"},
+	{"059", "Union"},
+	{"061", "Valencia"},
+}
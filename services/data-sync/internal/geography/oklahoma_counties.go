@@ -0,0 +1,83 @@
+package geography
+
+// OklahomaCounties contains all 77 Oklahoma counties.
+// FIPS codes are the 3-digit county portion (state code 40 is implied).
+var OklahomaCounties = []County{
+	{"001", "Adair"},
+	{"003", "Alfalfa"},
+	{"005", "Atoka"},
+	{"007", "Beaver"},
+	{"009", "Beckham"},
+	{"011", "Blaine"},
+	{"013", "Bryan"},
+	{"015", "Caddo"},
+	{"017", "Canadian"},
+	{"019", "Carter"},
+	{"021", "Cherokee"},
+	{"023", "Choctaw"},
+	{"025", "Cimarron"},
+	{"027", "Cleveland"},
+	{"029", "Coal"},
+	{"031", "Comanche"},
+	{"033", "Cotton"},
+	{"035", "Craig"},
+	{"037", "Creek"},
+	{"039", "Custer"},
+	{"041", "Delaware"},
+	{"043", "Dewey"},
+	{"045", "Ellis"},
+	{"047", "Garfield"},
+	{"049", "Garvin"},
+	{"051", "Grady"},
+	{"053", "Grant"},
+	{"055", "Greer"},
+	{"057", "Harmon"},
+	{"059", "Harper"},
+	{"061", "Haskell"},
+	{"063", "Hughes"},
+	{"065", "Jackson"},
+	{"067", "Jefferson"},
+	{"069", "Johnston"},
+	{"071", "Kay"},
+	{"073", "Kingfisher"},
+	{"075", "Kiowa"},
+	{"077", "Latimer"},
+	{"079", "Le Flore"},
+	{"081", "Lincoln"},
+	{"083", "Logan"},
+	{"085", "Love"},
+	{"087", "McClain"},
+	{"089", "McCurtain"},
+	{"091", "McIntosh"},
+	{"093", "Major"},
+	{"095", "Marshall"},
+	{"097", "Mayes"},
+	{"099", "Murray"},
+	{"101", "Muskogee"},
+	{"103", "Noble"},
+	{"105", "Nowata"},
+	{"107", "Okfuskee"},
+	{"109", "Oklahoma"},
+	{"111", "Okmulgee"},
+	{"113", "Osage"},
+	{"115", "Ottawa"},
+	{"117", "Pawnee"},
+	{"119", "Payne"},
+	{"121", "Pittsburg"},
+	{"123", "Pontotoc"},
+	{"125", "Pottawatomie"},
+	{"127", "Pushmataha"},
+	{"129", "Roger Mills"},
+	{"131", "Rogers"},
+	{"133", "Seminole"},
+	{"135", "Sequoyah"},
+	{"137", "Stephens"},
+	{"139", "Texas"},
+	{"141", "Tillman"},
+	{"143", "Tulsa"},
+	{"145", "Wagoner"},
+	{"147", "Washington"},
+	{"149", "Washita"},
+	{"151", "Woods"},
+	{"153", "Woodward"},
+}
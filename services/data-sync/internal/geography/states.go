@@ -0,0 +1,207 @@
+// Package geography provides the state and county FIPS tables used to
+// enumerate the geographies each data source syncs.
+package geography
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// State represents a U.S. state (or state-equivalent) with its FIPS code.
+type State struct {
+	FIPS string // 2-digit state FIPS code
+	Code string // USPS abbreviation
+	Name string // State name
+}
+
+// County represents a county (or county-equivalent, such as a Louisiana
+// parish) with its FIPS code.
+type County struct {
+	FIPS string // 3-digit county FIPS code (without state prefix)
+	Name string // County name
+}
+
+// States contains every state, the District of Columbia, and Puerto Rico.
+var States = []State{
+	{"01", "AL", "Alabama"},
+	{"02", "AK", "Alaska"},
+	{"04", "AZ", "Arizona"},
+	{"05", "AR", "Arkansas"},
+	{"06", "CA", "California"},
+	{"08", "CO", "Colorado"},
+	{"09", "CT", "Connecticut"},
+	{"10", "DE", "Delaware"},
+	{"11", "DC", "District of Columbia"},
+	{"12", "FL", "Florida"},
+	{"13", "GA", "Georgia"},
+	{"15", "HI", "Hawaii"},
+	{"16", "ID", "Idaho"},
+	{"17", "IL", "Illinois"},
+	{"18", "IN", "Indiana"},
+	{"19", "IA", "Iowa"},
+	{"20", "KS", "Kansas"},
+	{"21", "KY", "Kentucky"},
+	{"22", "LA", "Louisiana"},
+	{"23", "ME", "Maine"},
+	{"24", "MD", "Maryland"},
+	{"25", "MA", "Massachusetts"},
+	{"26", "MI", "Michigan"},
+	{"27", "MN", "Minnesota"},
+	{"28", "MS", "Mississippi"},
+	{"29", "MO", "Missouri"},
+	{"30", "MT", "Montana"},
+	{"31", "NE", "Nebraska"},
+	{"32", "NV", "Nevada"},
+	{"33", "NH", "New Hampshire"},
+	{"34", "NJ", "New Jersey"},
+	{"35", "NM", "New Mexico"},
+	{"36", "NY", "New York"},
+	{"37", "NC", "North Carolina"},
+	{"38", "ND", "North Dakota"},
+	{"39", "OH", "Ohio"},
+	{"40", "OK", "Oklahoma"},
+	{"41", "OR", "Oregon"},
+	{"42", "PA", "Pennsylvania"},
+	{"44", "RI", "Rhode Island"},
+	{"45", "SC", "South Carolina"},
+	{"46", "SD", "South Dakota"},
+	{"47", "TN", "Tennessee"},
+	{"48", "TX", "Texas"},
+	{"49", "UT", "Utah"},
+	{"50", "VT", "Vermont"},
+	{"51", "VA", "Virginia"},
+	{"53", "WA", "Washington"},
+	{"54", "WV", "West Virginia"},
+	{"55", "WI", "Wisconsin"},
+	{"56", "WY", "Wyoming"},
+	{"72", "PR", "Puerto Rico"},
+}
+
+// countyTables maps a state FIPS code to its bundled county table. Only
+// states with an entry can be selected (see ParseStateList), since BLS needs
+// a county list up front.
+var countyTables = map[string][]County{
+	"22": LouisianaParishes,
+	"35": NewMexicoCounties,
+	"40": OklahomaCounties,
+	"48": TexasCounties,
+}
+
+// GetState returns a state by its USPS abbreviation or FIPS code
+// (case-insensitive), or nil if none matches.
+func GetState(codeOrFIPS string) *State {
+	for i := range States {
+		if equalFold(States[i].Code, codeOrFIPS) || States[i].FIPS == codeOrFIPS {
+			return &States[i]
+		}
+	}
+	return nil
+}
+
+// ParseStateList parses a comma-separated list of USPS abbreviations or FIPS
+// codes (e.g., "TX,OK,LA,NM") into states, preserving order and dropping
+// duplicates. Unknown codes and states without a bundled county table are
+// an error.
+func ParseStateList(list string) ([]State, error) {
+	parts := strings.Split(list, ",")
+	states := make([]State, 0, len(parts))
+	seen := make(map[string]bool, len(parts))
+	for _, p := range parts {
+		code := strings.TrimSpace(p)
+		if code == "" {
+			continue
+		}
+		state := GetState(code)
+		if state == nil {
+			return nil, fmt.Errorf("unknown state %q", code)
+		}
+		if _, ok := countyTables[state.FIPS]; !ok {
+			return nil, fmt.Errorf("state %s is not supported: no county data is bundled for it (supported: %s)", state.Code, strings.Join(statesWithCounties(), ", "))
+		}
+		if seen[state.FIPS] {
+			continue
+		}
+		seen[state.FIPS] = true
+		states = append(states, *state)
+	}
+
+	if len(states) == 0 {
+		return nil, fmt.Errorf("no states selected")
+	}
+	return states, nil
+}
+
+// ParseCountyList parses a comma-separated list of 5-digit county GEOIDs
+// (state FIPS + county FIPS, e.g. "48029,40109"), dropping duplicates.
+// Counties in states with a bundled county table are checked against it.
+func ParseCountyList(list string) ([]string, error) {
+	parts := strings.Split(list, ",")
+	geoids := make([]string, 0, len(parts))
+	seen := make(map[string]bool, len(parts))
+	for _, p := range parts {
+		geoid := strings.TrimSpace(p)
+		if geoid == "" {
+			continue
+		}
+		if len(geoid) != 5 {
+			return nil, fmt.Errorf("invalid county %q: expected 5-digit GEOID", geoid)
+		}
+		state := GetState(geoid[:2])
+		if state == nil {
+			return nil, fmt.Errorf("invalid county %q: unknown state FIPS %s", geoid, geoid[:2])
+		}
+		if _, ok := countyTables[state.FIPS]; ok && state.GetCountyByFIPS(geoid[2:]) == nil {
+			return nil, fmt.Errorf("invalid county %q: no county %s in %s", geoid, geoid[2:], state.Code)
+		}
+		if seen[geoid] {
+			continue
+		}
+		seen[geoid] = true
+		geoids = append(geoids, geoid)
+	}
+	return geoids, nil
+}
+
+// Counties returns the bundled county table for a state.
+func (s State) Counties() ([]County, error) {
+	counties, ok := countyTables[s.FIPS]
+	if !ok {
+		return nil, fmt.Errorf("no county table bundled for %s (available: %s)", s.Code, strings.Join(statesWithCounties(), ", "))
+	}
+	return counties, nil
+}
+
+// GetCountyByFIPS returns a county in this state by its 3-digit FIPS code.
+func (s State) GetCountyByFIPS(fips string) *County {
+	counties := countyTables[s.FIPS]
+	for i := range counties {
+		if counties[i].FIPS == fips {
+			return &counties[i]
+		}
+	}
+	return nil
+}
+
+// GetCountyByName returns a county in this state by its name (case-insensitive).
+func (s State) GetCountyByName(name string) *County {
+	counties := countyTables[s.FIPS]
+	for i := range counties {
+		if equalFold(counties[i].Name, name) {
+			return &counties[i]
+		}
+	}
+	return nil
+}
+
+// statesWithCounties returns the USPS codes of states with bundled county tables.
+func statesWithCounties() []string {
+	codes := make([]string, 0, len(countyTables))
+	for fips := range countyTables {
+		if state := GetState(fips); state != nil {
+			codes = append(codes, state.Code)
+		}
+	}
+	sort.Strings(codes)
+	return codes
+}
@@ -0,0 +1,154 @@
+package geography
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGetState(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string // FIPS, empty for nil
+	}{
+		{"TX", "48"},
+		{"tx", "48"},
+		{"48", "48"},
+		{"OK", "40"},
+		{"LA", "22"},
+		{"NM", "35"},
+		{"PR", "72"},
+		{"XX", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		state := GetState(tt.input)
+		if tt.expected == "" {
+			if state != nil {
+				t.Errorf("GetState(%q) = %v, expected nil", tt.input, state)
+			}
+			continue
+		}
+		if state == nil || state.FIPS != tt.expected {
+			t.Errorf("GetState(%q) = %v, expected FIPS %s", tt.input, state, tt.expected)
+		}
+	}
+}
+
+func TestParseStateList(t *testing.T) {
+	states, err := ParseStateList("TX, ok,22,NM,TX")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{"TX", "OK", "LA", "NM"}
+	if len(states) != len(expected) {
+		t.Fatalf("expected %d states, got %d", len(expected), len(states))
+	}
+	for i, code := range expected {
+		if states[i].Code != code {
+			t.Errorf("states[%d] = %s, expected %s", i, states[i].Code, code)
+		}
+	}
+
+	if _, err := ParseStateList("TX,ZZ"); err == nil {
+		t.Error("expected error for unknown state, got nil")
+	}
+	_, err = ParseStateList("TX,CA")
+	if err == nil || !strings.Contains(err.Error(), "CA is not supported") {
+		t.Errorf("expected error for a state without county data, got %v", err)
+	}
+	if _, err := ParseStateList(" , "); err == nil {
+		t.Error("expected error for empty list, got nil")
+	}
+}
+
+func TestParseCountyList(t *testing.T) {
+	geoids, err := ParseCountyList("48029, 40109,48029,06037")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{"48029", "40109", "06037"}
+	if len(geoids) != len(expected) {
+		t.Fatalf("expected %d counties, got %d", len(expected), len(geoids))
+	}
+	for i, geoid := range expected {
+		if geoids[i] != geoid {
+			t.Errorf("geoids[%d] = %s, expected %s", i, geoids[i], geoid)
+		}
+	}
+
+	for _, list := range []string{"4802", "99001", "48998"} {
+		if _, err := ParseCountyList(list); err == nil {
+			t.Errorf("ParseCountyList(%q): expected error, got nil", list)
+		}
+	}
+}
+
+func TestCountyTables(t *testing.T) {
+	expectedCounts := map[string]int{
+		"TX": 254,
+		"OK": 77,
+		"LA": 64,
+		"NM": 33,
+	}
+
+	for code, expected := range expectedCounts {
+		counties, err := GetState(code).Counties()
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", code, err)
+		}
+		if len(counties) != expected {
+			t.Errorf("%s: expected %d counties, got %d", code, expected, len(counties))
+		}
+
+		seen := make(map[string]bool, len(counties))
+		for _, c := range counties {
+			if len(c.FIPS) != 3 {
+				t.Errorf("%s: county %s has invalid FIPS %q", code, c.Name, c.FIPS)
+			}
+			if seen[c.FIPS] {
+				t.Errorf("%s: duplicate county FIPS %s", code, c.FIPS)
+			}
+			seen[c.FIPS] = true
+		}
+	}
+}
+
+func TestCounties_NoTable(t *testing.T) {
+	if _, err := GetState("CA").Counties(); err == nil {
+		t.Error("expected error for state without a county table, got nil")
+	}
+}
+
+func TestState_GetCounty(t *testing.T) {
+	tx := GetState("TX")
+
+	if c := tx.GetCountyByFIPS("029"); c == nil || c.Name != "Bexar" {
+		t.Errorf("expected Bexar for 48029, got %v", c)
+	}
+	if c := tx.GetCountyByName("bexar"); c == nil || c.FIPS != "029" {
+		t.Errorf("expected FIPS 029 for Bexar, got %v", c)
+	}
+
+	nm := GetState("NM")
+	if c := nm.GetCountyByFIPS("028"); c == nil || c.Name != "Los Alamos" {
+		t.Errorf("expected Los Alamos for 35028, got %v", c)
+	}
+
+	if c := GetState("OK").GetCountyByFIPS("109"); c == nil || c.Name != "Oklahoma" {
+		t.Errorf("expected Oklahoma for 40109, got %v", c)
+	}
+	if c := GetState("OK").GetCountyByFIPS("143"); c == nil || c.Name != "Tulsa" {
+		t.Errorf("expected Tulsa for 40143, got %v", c)
+	}
+
+	la := GetState("LA")
+	if c := la.GetCountyByFIPS("071"); c == nil || c.Name != "Orleans" {
+		t.Errorf("expected Orleans for 22071, got %v", c)
+	}
+	if c := la.GetCountyByFIPS("033"); c == nil || c.Name != "East Baton Rouge" {
+		t.Errorf("expected East Baton Rouge for 22033, got %v", c)
+	}
+}
@@ -1,15 +1,8 @@
-// Package sync provides the data sync orchestrator and Texas geographic data.
-package sync
-
-// TexasCounty represents a Texas county with its FIPS code.
-type TexasCounty struct {
-	FIPS string // 3-digit county FIPS code (without state prefix)
-	Name string // County name
-}
+package geography
 
 // TexasCounties contains all 254 Texas counties.
 // FIPS codes are the 3-digit county portion (state code 48 is implied).
-var TexasCounties = []TexasCounty{
+var TexasCounties = []County{
 	{"001", "Anderson"},
 	{"003", "Andrews"},
 	{"005", "Angelina"},
@@ -266,26 +259,6 @@ var TexasCounties = []TexasCounty{
 	{"507", "Zavala"},
 }
 
-// GetCountyByName returns a county by its name (case-insensitive).
-func GetCountyByName(name string) *TexasCounty {
-	for i := range TexasCounties {
-		if equalFold(TexasCounties[i].Name, name) {
-			return &TexasCounties[i]
-		}
-	}
-	return nil
-}
-
-// GetCountyByFIPS returns a county by its FIPS code.
-func GetCountyByFIPS(fips string) *TexasCounty {
-	for i := range TexasCounties {
-		if TexasCounties[i].FIPS == fips {
-			return &TexasCounties[i]
-		}
-	}
-	return nil
-}
-
 // equalFold is a simple case-insensitive string comparison.
 func equalFold(a, b string) bool {
 	if len(a) != len(b) {
@@ -0,0 +1,95 @@
+package geography
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// LoadZIPsFromFile reads ZIP codes from a file (one per line), dropping
+// blank lines and duplicates. Any other line that is not a 5-digit ZIP code
+// is an error.
+func LoadZIPsFromFile(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var zips []string
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		zip := strings.TrimSpace(scanner.Text())
+		if zip == "" {
+			continue
+		}
+		if !isZIP(zip) {
+			return nil, fmt.Errorf("line %d: invalid ZIP code %q: expected 5 digits", line, zip)
+		}
+		if seen[zip] {
+			continue
+		}
+		seen[zip] = true
+		zips = append(zips, zip)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return zips, nil
+}
+
+// ParseZIPList parses a comma-separated list of ZIP codes, preserving order
+// and dropping duplicates. Any entry that is not a 5-digit ZIP code is an
+// error.
+func ParseZIPList(list string) ([]string, error) {
+	parts := strings.Split(list, ",")
+	zips := make([]string, 0, len(parts))
+	seen := make(map[string]bool, len(parts))
+	for _, p := range parts {
+		zip := strings.TrimSpace(p)
+		if zip == "" {
+			continue
+		}
+		if !isZIP(zip) {
+			return nil, fmt.Errorf("invalid ZIP code %q: expected 5 digits", zip)
+		}
+		if seen[zip] {
+			continue
+		}
+		seen[zip] = true
+		zips = append(zips, zip)
+	}
+	return zips, nil
+}
+
+// MergeZIPLists concatenates ZIP lists, dropping ZIPs already listed.
+func MergeZIPLists(lists ...[]string) []string {
+	var zips []string
+	seen := make(map[string]bool)
+	for _, list := range lists {
+		for _, zip := range list {
+			if !seen[zip] {
+				seen[zip] = true
+				zips = append(zips, zip)
+			}
+		}
+	}
+	return zips
+}
+
+// isZIP reports whether s is a 5-digit ZIP code.
+func isZIP(s string) bool {
+	if len(s) != 5 {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
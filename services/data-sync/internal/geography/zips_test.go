@@ -0,0 +1,60 @@
+package geography
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseZIPList(t *testing.T) {
+	zips, err := ParseZIPList("78201, 78259,,78201,00501")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{"78201", "78259", "00501"}
+	if !reflect.DeepEqual(zips, expected) {
+		t.Errorf("expected %v, got %v", expected, zips)
+	}
+
+	if zips, err := ParseZIPList(" , "); err != nil || len(zips) != 0 {
+		t.Errorf("expected an empty list, got %v (%v)", zips, err)
+	}
+
+	for _, list := range []string{"78201,ABCDE", "7820", "782011", "78201,7820-1"} {
+		if _, err := ParseZIPList(list); err == nil {
+			t.Errorf("ParseZIPList(%q): expected error, got nil", list)
+		}
+	}
+}
+
+func TestLoadZIPsFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "zips.txt")
+	if err := os.WriteFile(path, []byte("78201\n\n 78259 \n78201\n"), 0o644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	zips, err := LoadZIPsFromFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{"78201", "78259"}
+	if !reflect.DeepEqual(zips, expected) {
+		t.Errorf("expected %v, got %v", expected, zips)
+	}
+
+	if err := os.WriteFile(path, []byte("78201\nABCDE\n"), 0o644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := LoadZIPsFromFile(path); err == nil {
+		t.Error("expected error for a non-numeric ZIP, got nil")
+	}
+}
+
+func TestMergeZIPLists(t *testing.T) {
+	zips := MergeZIPLists([]string{"78201", "78259"}, []string{"78259", "78006"})
+	expected := []string{"78201", "78259", "78006"}
+	if !reflect.DeepEqual(zips, expected) {
+		t.Errorf("expected %v, got %v", expected, zips)
+	}
+}
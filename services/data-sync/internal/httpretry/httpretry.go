@@ -0,0 +1,259 @@
+// Package httpretry provides the retrying HTTP transport shared by the
+// upstream API clients.
+//
+// Rate limiting (429), server errors (5xx), timeouts and dropped connections
+// are retried with jittered exponential backoff, honouring Retry-After, until
+// the policy's retries or total time run out. Other client errors (4xx),
+// exhausted quotas and open circuit breakers are returned straight away.
+package httpretry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/dealforge/data-sync/internal/metrics"
+	"github.com/dealforge/data-sync/internal/sources"
+)
+
+// DefaultMaxRetries is the number of retries of DefaultPolicy.
+const DefaultMaxRetries = 3
+
+// maxDrain bounds how much of a retried response's body is read so its
+// connection can be reused.
+const maxDrain = 64 << 10
+
+// Policy configures when and how long a request is retried.
+type Policy struct {
+	MaxRetries     int           // Retries after the first attempt
+	BaseDelay      time.Duration // Backoff before the first retry, doubled for each later one
+	MaxDelay       time.Duration // Longest single wait; a longer Retry-After is not honoured
+	MaxElapsed     time.Duration // Longest total time for a request, including retries
+	AttemptTimeout time.Duration // Longest time for one attempt, including reading the body; zero means none
+}
+
+// DefaultPolicy returns a policy of DefaultMaxRetries retries starting at a
+// one-second backoff, within five minutes in total.
+func DefaultPolicy() Policy {
+	return Policy{
+		MaxRetries: DefaultMaxRetries,
+		BaseDelay:  time.Second,
+		MaxDelay:   time.Minute,
+		MaxElapsed: 5 * time.Minute,
+	}
+}
+
+// Transport retries the requests of a source through base according to
+// policy. A nil base uses http.DefaultTransport. Requests with a body are
+// only retried if it can be replayed (http.NewRequest sets GetBody for the
+// usual in-memory bodies).
+func Transport(source string, policy Policy, base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{source: source, policy: policy, base: base}
+}
+
+type transport struct {
+	source string
+	policy Policy
+	base   http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	start := time.Now()
+
+	log := slog.With("source", t.source, "method", req.Method, "host", req.URL.Host)
+	if unit, ok := sources.UnitFromContext(ctx); ok {
+		log = log.With("entity", unit.Name)
+	}
+
+	replayable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+
+	for attempt := 1; ; attempt++ {
+		attemptStart := time.Now()
+		resp, err := t.attempt(req, attempt)
+		elapsed := time.Since(attemptStart)
+
+		retryable, reason := classify(ctx, resp, err)
+		if !retryable {
+			if err != nil || resp.StatusCode >= 400 {
+				log.Warn("upstream request failed", "attempt", attempt, "reason", reason, "elapsed", elapsed)
+			} else {
+				log.Info("upstream request succeeded", "attempt", attempt, "status", resp.StatusCode, "elapsed", elapsed)
+			}
+			return resp, wrapAttempts(err, attempt)
+		}
+
+		delay, ok := t.delay(attempt, resp)
+		switch {
+		case !replayable:
+			ok, reason = false, reason+"; request body cannot be replayed"
+		case attempt > t.policy.MaxRetries:
+			ok, reason = false, reason+"; out of retries"
+		case !ok:
+			reason = fmt.Sprintf("%s; Retry-After exceeds %s", reason, t.policy.MaxDelay)
+		case t.policy.MaxElapsed > 0 && time.Since(start)+delay > t.policy.MaxElapsed:
+			ok, reason = false, fmt.Sprintf("%s; retrying would exceed %s", reason, t.policy.MaxElapsed)
+		}
+		if !ok {
+			log.Warn("upstream request failed, giving up", "attempt", attempt, "reason", reason, "elapsed", elapsed)
+			return resp, wrapAttempts(err, attempt)
+		}
+
+		log.Warn("upstream request failed, retrying", "attempt", attempt, "reason", reason, "elapsed", elapsed, "delay", delay)
+		metrics.UpstreamRetries.WithLabelValues(t.source).Inc()
+		if resp != nil {
+			drain(resp.Body)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// attempt makes one attempt of req, bounded by the policy's AttemptTimeout.
+func (t *transport) attempt(req *http.Request, attempt int) (*http.Response, error) {
+	ctx, cancel := req.Context(), context.CancelFunc(func() {})
+	if t.policy.AttemptTimeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, t.policy.AttemptTimeout)
+	}
+
+	try := req.Clone(ctx)
+	if attempt > 1 && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		try.Body = body
+	}
+
+	resp, err := t.base.RoundTrip(try)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	// The attempt's deadline covers reading the body, so release it on close
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// delay returns how long to wait before retrying after an attempt: the
+// response's Retry-After if it has one, otherwise jittered exponential
+// backoff. It reports false if Retry-After asks for longer than MaxDelay.
+func (t *transport) delay(attempt int, resp *http.Response) (time.Duration, bool) {
+	if resp != nil {
+		if wait, ok := retryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+			return wait, wait <= t.policy.MaxDelay
+		}
+	}
+
+	backoff := t.policy.BaseDelay << (attempt - 1)
+	if backoff <= 0 || backoff > t.policy.MaxDelay {
+		backoff = t.policy.MaxDelay
+	}
+	// Equal jitter: half fixed, half random, so concurrent clients spread out
+	half := backoff / 2
+	if half <= 0 {
+		return backoff, true
+	}
+	return half + rand.N(half), true
+}
+
+// classify reports whether an attempt's outcome is worth retrying, and why
+// it failed.
+func classify(ctx context.Context, resp *http.Response, err error) (bool, string) {
+	if err != nil {
+		switch {
+		case ctx.Err() != nil:
+			return false, ctx.Err().Error()
+		case errors.Is(err, sources.ErrQuotaExhausted), errors.Is(err, sources.ErrUpstreamUnavailable):
+			return false, err.Error()
+		case errors.Is(err, context.DeadlineExceeded):
+			return true, "attempt timed out"
+		case isTransient(err):
+			return true, err.Error()
+		default:
+			return false, err.Error()
+		}
+	}
+
+	switch code := resp.StatusCode; {
+	case code == http.StatusTooManyRequests:
+		return true, resp.Status
+	case code >= 500 && code != http.StatusNotImplemented && code != http.StatusHTTPVersionNotSupported:
+		return true, resp.Status
+	default:
+		return false, resp.Status
+	}
+}
+
+// isTransient reports whether a transport error is a timeout or a dropped
+// connection that may succeed on another attempt.
+func isTransient(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && (dnsErr.IsTemporary || dnsErr.IsTimeout) {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.EOF)
+}
+
+// retryAfter parses a Retry-After header, in seconds or as an HTTP date.
+func retryAfter(header string, now time.Time) (time.Duration, bool) {
+	if header == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(header); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(header); err == nil {
+		return max(at.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+func wrapAttempts(err error, attempts int) error {
+	if err == nil || attempts == 1 {
+		return err
+	}
+	return fmt.Errorf("after %d attempts: %w", attempts, err)
+}
+
+// drain reads and closes a response body so its connection can be reused.
+func drain(body io.ReadCloser) {
+	io.Copy(io.Discard, io.LimitReader(body, maxDrain))
+	body.Close()
+}
+
+// cancelOnClose releases an attempt's context once its body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
@@ -0,0 +1,319 @@
+package httpretry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/dealforge/data-sync/internal/sources"
+)
+
+// testPolicy retries quickly so tests stay fast.
+func testPolicy() Policy {
+	return Policy{
+		MaxRetries: 3,
+		BaseDelay:  time.Millisecond,
+		MaxDelay:   50 * time.Millisecond,
+		MaxElapsed: 5 * time.Second,
+	}
+}
+
+// statusServer responds with each status in turn, then with the last one.
+func statusServer(t *testing.T, attempts *int32, statuses ...int) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(atomic.AddInt32(attempts, 1))
+		status := statuses[min(n, len(statuses))-1]
+		if status == http.StatusTooManyRequests {
+			w.Header().Set("Retry-After", "0")
+		}
+		w.WriteHeader(status)
+		fmt.Fprintf(w, "attempt %d", n)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestTransport_RetriesTransientStatuses(t *testing.T) {
+	var attempts int32
+	server := statusServer(t, &attempts, http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK)
+
+	client := &http.Client{Transport: Transport("test", testPolicy(), nil)}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected status 200, got %d", resp.StatusCode)
+	}
+	if attempts != 3 {
+		t.Errorf("expected 3 attempts, got %d", attempts)
+	}
+}
+
+func TestTransport_DoesNotRetryClientErrors(t *testing.T) {
+	for _, status := range []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound, http.StatusNotImplemented} {
+		var attempts int32
+		server := statusServer(t, &attempts, status)
+
+		client := &http.Client{Transport: Transport("test", testPolicy(), nil)}
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatalf("%d: unexpected error: %v", status, err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != status || attempts != 1 {
+			t.Errorf("%d: expected a single attempt returning the status, got %d attempts (status %d)", status, attempts, resp.StatusCode)
+		}
+	}
+}
+
+func TestTransport_GivesUpAfterMaxRetries(t *testing.T) {
+	var attempts int32
+	server := statusServer(t, &attempts, http.StatusBadGateway)
+
+	client := &http.Client{Transport: Transport("test", testPolicy(), nil)}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if attempts != 4 {
+		t.Errorf("expected 4 attempts, got %d", attempts)
+	}
+
+	// The last response is returned intact for the caller to report
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusBadGateway || string(body) != "attempt 4" {
+		t.Errorf("expected the last 502 response, got %d %q", resp.StatusCode, body)
+	}
+}
+
+func TestTransport_RetryAfterBeyondMaxDelay(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: Transport("test", testPolicy(), nil)}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusTooManyRequests || attempts != 1 {
+		t.Errorf("expected to give up on a long Retry-After, got %d attempts (status %d)", attempts, resp.StatusCode)
+	}
+}
+
+func TestTransport_CapsElapsedTime(t *testing.T) {
+	var attempts int32
+	server := statusServer(t, &attempts, http.StatusInternalServerError)
+
+	policy := testPolicy()
+	policy.MaxRetries = 100
+	policy.BaseDelay = 20 * time.Millisecond
+	policy.MaxElapsed = 100 * time.Millisecond
+
+	client := &http.Client{Transport: Transport("test", policy, nil)}
+	start := time.Now()
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected to give up within the elapsed cap, took %v", elapsed)
+	}
+	if attempts < 2 || attempts > 10 {
+		t.Errorf("expected a few attempts within the elapsed cap, got %d", attempts)
+	}
+}
+
+func TestTransport_ReplaysRequestBody(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != `{"seriesid":["LAUCN480010000000003"]}` {
+			t.Errorf("attempt %d: unexpected body %q", atomic.LoadInt32(&attempts)+1, body)
+		}
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: Transport("test", testPolicy(), nil)}
+	resp, err := client.Post(server.URL, "application/json", strings.NewReader(`{"seriesid":["LAUCN480010000000003"]}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || attempts != 2 {
+		t.Errorf("expected success on the second attempt, got %d attempts (status %d)", attempts, resp.StatusCode)
+	}
+}
+
+func TestTransport_RetriesConnectionResets(t *testing.T) {
+	attempts := 0
+	base := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		attempts++
+		if attempts == 1 {
+			return nil, fmt.Errorf("read tcp: %w", syscall.ECONNRESET)
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: r}, nil
+	})
+
+	client := &http.Client{Transport: Transport("test", testPolicy(), base)}
+	resp, err := client.Get("http://upstream.test/")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if attempts != 2 {
+		t.Errorf("expected 2 attempts, got %d", attempts)
+	}
+}
+
+func TestTransport_DoesNotRetryQuotaErrors(t *testing.T) {
+	attempts := 0
+	base := roundTripFunc(func(*http.Request) (*http.Response, error) {
+		attempts++
+		return nil, fmt.Errorf("bls daily quota used up: %w", sources.ErrQuotaExhausted)
+	})
+
+	client := &http.Client{Transport: Transport("test", testPolicy(), base)}
+	if _, err := client.Get("http://upstream.test/"); !errors.Is(err, sources.ErrQuotaExhausted) {
+		t.Errorf("expected ErrQuotaExhausted, got %v", err)
+	}
+	if attempts != 1 {
+		t.Errorf("expected 1 attempt, got %d", attempts)
+	}
+}
+
+func TestTransport_DoesNotRetryOpenCircuit(t *testing.T) {
+	attempts := 0
+	base := roundTripFunc(func(*http.Request) (*http.Response, error) {
+		attempts++
+		return nil, fmt.Errorf("test circuit open: %w", sources.ErrUpstreamUnavailable)
+	})
+
+	client := &http.Client{Transport: Transport("test", testPolicy(), base)}
+	if _, err := client.Get("http://upstream.test/"); !errors.Is(err, sources.ErrUpstreamUnavailable) {
+		t.Errorf("expected ErrUpstreamUnavailable, got %v", err)
+	}
+	if attempts != 1 {
+		t.Errorf("expected 1 attempt, got %d", attempts)
+	}
+}
+
+func TestTransport_AttemptTimeout(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+			return
+		}
+		fmt.Fprint(w, "ok")
+	}))
+	defer server.Close()
+
+	policy := testPolicy()
+	policy.AttemptTimeout = 50 * time.Millisecond
+
+	client := &http.Client{Transport: Transport("test", policy, nil)}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil || string(body) != "ok" {
+		t.Errorf("expected the second attempt's body, got %q (%v)", body, err)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		header string
+		want   time.Duration
+		ok     bool
+	}{
+		{"", 0, false},
+		{"120", 2 * time.Minute, true},
+		{"Fri, 10 Jan 2025 12:00:30 GMT", 30 * time.Second, true},
+		{"Fri, 10 Jan 2025 11:00:00 GMT", 0, true},
+		{"soon", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := retryAfter(tt.header, now)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("retryAfter(%q) = %v, %v; expected %v, %v", tt.header, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestTransport_LogsSuccessAtInfo(t *testing.T) {
+	var logs bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&logs, &slog.HandlerOptions{Level: slog.LevelInfo})))
+	defer slog.SetDefault(previous)
+
+	var attempts int32
+	server := statusServer(t, &attempts, http.StatusOK)
+	client := &http.Client{Transport: Transport("test", testPolicy(), nil)}
+
+	ctx := sources.WithUnit(context.Background(), sources.WorkUnit{Key: "48", Name: "Texas"})
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(logs.Bytes(), &entry); err != nil {
+		t.Fatalf("expected one JSON log line, got %q: %v", logs.String(), err)
+	}
+	if entry["msg"] != "upstream request succeeded" || entry["level"] != "INFO" {
+		t.Errorf("expected an info-level success log, got %v", entry)
+	}
+	if entry["source"] != "test" || entry["entity"] != "Texas" || entry["status"] != float64(http.StatusOK) {
+		t.Errorf("expected the source, entity and status to be logged, got %v", entry)
+	}
+	if _, ok := entry["elapsed"]; !ok {
+		t.Errorf("expected the elapsed time to be logged, got %v", entry)
+	}
+}
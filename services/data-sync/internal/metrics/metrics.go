@@ -0,0 +1,177 @@
+// Package metrics defines the Prometheus metrics of the data sync service.
+//
+// Long-running modes serve them over HTTP on an optional listen address;
+// one-shot runs can write them for the node exporter's textfile collector.
+package metrics
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "datasync"
+
+// Registry holds the service's own metrics. Go runtime and process metrics
+// are kept apart so they are served but not written to textfiles.
+var Registry = prometheus.NewRegistry()
+
+var runtimeRegistry = prometheus.NewRegistry()
+
+func init() {
+	runtimeRegistry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+var factory = promauto.With(Registry)
+
+var (
+	// UpstreamRequests counts upstream API requests by source and HTTP
+	// status code ("error" if no response was received).
+	UpstreamRequests = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "upstream_requests_total",
+		Help:      "Upstream API requests by source and HTTP status.",
+	}, []string{"source", "status"})
+
+	// UpstreamRequestDuration observes upstream API latency by source and
+	// HTTP status code.
+	UpstreamRequestDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "upstream_request_duration_seconds",
+		Help:      "Upstream API request latency by source and HTTP status.",
+		Buckets:   prometheus.ExponentialBuckets(0.05, 2, 12), // 50ms to ~100s
+	}, []string{"source", "status"})
+
+	// UpstreamRetries counts retried upstream requests by source.
+	UpstreamRetries = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "upstream_retries_total",
+		Help:      "Upstream API requests retried after a transient failure.",
+	}, []string{"source"})
+
+	// QuotaExhausted counts runs stopped by an upstream quota (e.g., the BLS
+	// daily request limit) by source.
+	QuotaExhausted = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "upstream_quota_exhausted_total",
+		Help:      "Times an upstream API refused further requests for the day.",
+	}, []string{"source"})
+
+	// CircuitOpen is 1 while a source's circuit breaker is failing requests
+	// fast, and 0 otherwise.
+	CircuitOpen = factory.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "upstream_circuit_open",
+		Help:      "Whether the circuit breaker of an upstream API is open.",
+	}, []string{"source"})
+
+	// RecordsUpserted counts rows written by table.
+	RecordsUpserted = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "records_upserted_total",
+		Help:      "Rows inserted or updated by table.",
+	}, []string{"table"})
+
+	// CheckpointLag is the number of work units of each source's latest
+	// session that have not completed yet.
+	CheckpointLag = factory.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "checkpoint_lag_units",
+		Help:      "Work units of the latest sync session not yet completed.",
+	}, []string{"source"})
+
+	// Runs counts finished sync runs by source and ledger status.
+	Runs = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "runs_total",
+		Help:      "Finished sync runs by source and status.",
+	}, []string{"source", "status"})
+
+	// RunDuration observes how long sync runs take by source.
+	RunDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "run_duration_seconds",
+		Help:      "Sync run duration by source.",
+		Buckets:   prometheus.ExponentialBuckets(1, 2, 16), // 1s to ~9h
+	}, []string{"source"})
+
+	// LastSuccess is when each source last completed a run.
+	LastSuccess = factory.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "last_success_timestamp_seconds",
+		Help:      "Unix time each source last completed a sync run.",
+	}, []string{"source"})
+)
+
+// Transport instruments an HTTP transport with the upstream request metrics
+// of a source. A nil base uses http.DefaultTransport.
+func Transport(source string, base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		start := time.Now()
+		resp, err := base.RoundTrip(req)
+
+		status := "error"
+		if err == nil {
+			status = strconv.Itoa(resp.StatusCode)
+		}
+		UpstreamRequests.WithLabelValues(source, status).Inc()
+		UpstreamRequestDuration.WithLabelValues(source, status).Observe(time.Since(start).Seconds())
+
+		return resp, err
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+// Handler serves the service's metrics along with Go runtime and process
+// metrics.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(prometheus.Gatherers{Registry, runtimeRegistry}, promhttp.HandlerOpts{})
+}
+
+// Serve serves /metrics on addr until ctx is cancelled.
+func Serve(ctx context.Context, addr string) error {
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", Handler())
+
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+
+	slog.Info("serving metrics", "addr", addr)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// WriteTextfile writes the service's metrics to path in the text exposition
+// format, atomically, for the node exporter's textfile collector.
+func WriteTextfile(path string) error {
+	return prometheus.WriteToTextfile(path, Registry)
+}
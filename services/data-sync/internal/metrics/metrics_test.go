@@ -0,0 +1,80 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestTransport_CountsRequestsByStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: Transport("test_transport", nil)}
+	for _, path := range []string{"/", "/", "/missing"} {
+		resp, err := client.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	if got := testutil.ToFloat64(UpstreamRequests.WithLabelValues("test_transport", "200")); got != 2 {
+		t.Errorf("expected 2 requests with status 200, got %v", got)
+	}
+	if got := testutil.ToFloat64(UpstreamRequests.WithLabelValues("test_transport", "404")); got != 1 {
+		t.Errorf("expected 1 request with status 404, got %v", got)
+	}
+	if got := testutil.CollectAndCount(UpstreamRequestDuration, "datasync_upstream_request_duration_seconds"); got < 2 {
+		t.Errorf("expected latency series for both statuses, got %d", got)
+	}
+}
+
+func TestTransport_CountsTransportErrors(t *testing.T) {
+	failing := roundTripperFunc(func(*http.Request) (*http.Response, error) {
+		return nil, errors.New("connection refused")
+	})
+	client := &http.Client{Transport: Transport("test_errors", failing)}
+
+	if _, err := client.Get("http://upstream.invalid/"); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	if got := testutil.ToFloat64(UpstreamRequests.WithLabelValues("test_errors", "error")); got != 1 {
+		t.Errorf("expected 1 request with status error, got %v", got)
+	}
+}
+
+func TestWriteTextfile(t *testing.T) {
+	RecordsUpserted.WithLabelValues("test_table").Add(3)
+
+	path := filepath.Join(t.TempDir(), "data_sync.prom")
+	if err := WriteTextfile(path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read textfile: %v", err)
+	}
+	out := string(data)
+
+	if !strings.Contains(out, `datasync_records_upserted_total{table="test_table"} 3`) {
+		t.Errorf("expected upserted records in textfile, got:\n%s", out)
+	}
+	if strings.Contains(out, "go_goroutines") {
+		t.Errorf("expected no runtime metrics in textfile, got:\n%s", out)
+	}
+}
@@ -0,0 +1,254 @@
+// Package ratelimit throttles requests to upstream APIs with a token bucket
+// per source and enforces each source's daily request quota.
+//
+// Quota usage is kept in memory until UseStore attaches a QuotaStore (the
+// database), after which every process shares the day's budget, so a second
+// run on the same day knows up front how many requests it has left.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+	_ "time/tzdata" // Quota days follow US Eastern time, even without system zoneinfo
+
+	"golang.org/x/time/rate"
+
+	"github.com/dealforge/data-sync/internal/config"
+	"github.com/dealforge/data-sync/internal/sources"
+)
+
+// Unlimited is returned by Remaining for limiters without a daily quota.
+const Unlimited = -1
+
+// quotaLocation is where quota days start: the government APIs reset their
+// daily quotas at midnight US Eastern time.
+var quotaLocation = mustLoadLocation("America/New_York")
+
+// Limits configures a Limiter.
+type Limits struct {
+	RequestsPerSecond float64 // Sustained request rate; zero means unlimited
+	Burst             int     // Requests allowed at once; defaults to 1
+	DailyQuota        int     // Requests allowed per day; zero means no quota
+}
+
+// QuotaStore persists daily quota usage. *db.Client implements it.
+type QuotaStore interface {
+	QuotaUsed(ctx context.Context, source string, day time.Time) (int, error)
+	ConsumeQuota(ctx context.Context, source string, day time.Time, quota int) (bool, error)
+	ExhaustQuota(ctx context.Context, source string, day time.Time, quota int) error
+}
+
+// Limiter throttles the requests of one source. It is safe for concurrent
+// use; every client of a source shares the same Limiter.
+type Limiter struct {
+	source string
+	limits Limits
+	bucket *rate.Limiter
+
+	mu   sync.Mutex
+	day  time.Time // Quota day of used
+	used int       // Requests made today, when counted in memory
+}
+
+var (
+	registryMu sync.Mutex
+	limiters   = make(map[string]*Limiter)
+	store      QuotaStore
+)
+
+// New creates the limiter of a source. It replaces any earlier limiter of
+// the source returned by Lookup.
+func New(source string, limits Limits) *Limiter {
+	if limits.Burst < 1 {
+		limits.Burst = 1
+	}
+	every := rate.Inf
+	if limits.RequestsPerSecond > 0 {
+		every = rate.Limit(limits.RequestsPerSecond)
+	}
+
+	l := &Limiter{
+		source: source,
+		limits: limits,
+		bucket: rate.NewLimiter(every, limits.Burst),
+	}
+
+	registryMu.Lock()
+	limiters[source] = l
+	registryMu.Unlock()
+	return l
+}
+
+// FromConfig creates the limiter of a source from its defaults, overridden
+// by SYNC_RATE_LIMIT_<SOURCE> and SYNC_DAILY_QUOTA_<SOURCE>.
+func FromConfig(cfg *config.Config, source string, defaults Limits) *Limiter {
+	limits := defaults
+	if rps, ok := cfg.RateLimit(source); ok {
+		limits.RequestsPerSecond = rps
+	}
+	if quota, ok := cfg.DailyQuota(source); ok {
+		limits.DailyQuota = quota
+	}
+	return New(source, limits)
+}
+
+// Lookup returns the limiter of a source, or nil if it has none.
+func Lookup(source string) *Limiter {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	return limiters[source]
+}
+
+// UseStore makes every limiter track its daily quota in s. Pass nil to count
+// in memory again, e.g. for dry runs.
+func UseStore(s QuotaStore) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	store = s
+}
+
+func currentStore() QuotaStore {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	return store
+}
+
+// Limits returns the limits the limiter enforces.
+func (l *Limiter) Limits() Limits {
+	return l.limits
+}
+
+// Wait blocks until a request may be made, then counts it against the daily
+// quota. It returns an error wrapping sources.ErrQuotaExhausted once the
+// quota is used up, or the context's error if it is cancelled first.
+func (l *Limiter) Wait(ctx context.Context) error {
+	if err := l.bucket.Wait(ctx); err != nil {
+		return err
+	}
+	return l.consume(ctx)
+}
+
+// Remaining returns how many requests are left in today's quota, or
+// Unlimited if the source has no daily quota.
+func (l *Limiter) Remaining(ctx context.Context) (int, error) {
+	if l.limits.DailyQuota <= 0 {
+		return Unlimited, nil
+	}
+
+	day := quotaDay(time.Now())
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rollover(day)
+
+	used := l.used
+	if s := currentStore(); s != nil {
+		var err error
+		used, err = s.QuotaUsed(ctx, l.source, day)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return max(l.limits.DailyQuota-used, 0), nil
+}
+
+// Exhaust marks today's quota as used up, for when the upstream API refuses
+// requests before the limiter's count reaches the quota.
+func (l *Limiter) Exhaust(ctx context.Context) {
+	if l.limits.DailyQuota <= 0 {
+		return
+	}
+
+	day := quotaDay(time.Now())
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rollover(day)
+	l.used = l.limits.DailyQuota
+
+	if s := currentStore(); s != nil {
+		if err := s.ExhaustQuota(ctx, l.source, day, l.limits.DailyQuota); err != nil {
+			slog.Warn("failed to record exhausted upstream quota", "source", l.source, "error", err)
+		}
+	}
+}
+
+// consume counts a request against the daily quota. If the store fails, the
+// request is counted in memory rather than failing the run.
+func (l *Limiter) consume(ctx context.Context) error {
+	if l.limits.DailyQuota <= 0 {
+		return nil
+	}
+
+	day := quotaDay(time.Now())
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rollover(day)
+
+	if s := currentStore(); s != nil {
+		granted, err := s.ConsumeQuota(ctx, l.source, day, l.limits.DailyQuota)
+		if err == nil {
+			if !granted {
+				return l.exhausted()
+			}
+			l.used++
+			return nil
+		}
+		slog.Warn("failed to record upstream quota usage, counting in memory", "source", l.source, "error", err)
+	}
+
+	if l.used >= l.limits.DailyQuota {
+		return l.exhausted()
+	}
+	l.used++
+	return nil
+}
+
+// rollover resets the in-memory count when a new quota day starts. The
+// caller must hold l.mu.
+func (l *Limiter) rollover(day time.Time) {
+	if !l.day.Equal(day) {
+		l.day, l.used = day, 0
+	}
+}
+
+func (l *Limiter) exhausted() error {
+	return fmt.Errorf("%s daily quota of %d requests used up: %w", l.source, l.limits.DailyQuota, sources.ErrQuotaExhausted)
+}
+
+// Transport makes every request through an HTTP transport wait for the
+// limiter. A nil base uses http.DefaultTransport.
+func Transport(l *Limiter, base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if err := l.Wait(req.Context()); err != nil {
+			return nil, err
+		}
+		return base.RoundTrip(req)
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+// quotaDay returns the quota day t falls on, as midnight UTC of that date.
+func quotaDay(t time.Time) time.Time {
+	y, m, d := t.In(quotaLocation).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func mustLoadLocation(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		panic(err)
+	}
+	return loc
+}
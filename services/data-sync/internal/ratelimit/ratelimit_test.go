@@ -0,0 +1,199 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dealforge/data-sync/internal/config"
+	"github.com/dealforge/data-sync/internal/sources"
+)
+
+// memoryStore is a QuotaStore shared by limiters, standing in for the
+// database.
+type memoryStore struct {
+	used map[string]int
+	fail bool
+}
+
+func (s *memoryStore) QuotaUsed(ctx context.Context, source string, day time.Time) (int, error) {
+	if s.fail {
+		return 0, errors.New("connection refused")
+	}
+	return s.used[source], nil
+}
+
+func (s *memoryStore) ConsumeQuota(ctx context.Context, source string, day time.Time, quota int) (bool, error) {
+	if s.fail {
+		return false, errors.New("connection refused")
+	}
+	if s.used[source] >= quota {
+		return false, nil
+	}
+	s.used[source]++
+	return true, nil
+}
+
+func (s *memoryStore) ExhaustQuota(ctx context.Context, source string, day time.Time, quota int) error {
+	s.used[source] = max(s.used[source], quota)
+	return nil
+}
+
+func TestLimiter_DailyQuotaInMemory(t *testing.T) {
+	UseStore(nil)
+	l := New("test_memory", Limits{DailyQuota: 2})
+
+	for i := 0; i < 2; i++ {
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatalf("request %d: unexpected error: %v", i+1, err)
+		}
+	}
+	if err := l.Wait(context.Background()); !errors.Is(err, sources.ErrQuotaExhausted) {
+		t.Errorf("expected ErrQuotaExhausted after the quota, got %v", err)
+	}
+	if remaining, _ := l.Remaining(context.Background()); remaining != 0 {
+		t.Errorf("expected no remaining quota, got %d", remaining)
+	}
+}
+
+func TestLimiter_SharesQuotaThroughStore(t *testing.T) {
+	store := &memoryStore{used: map[string]int{"test_store": 3}}
+	UseStore(store)
+	defer UseStore(nil)
+
+	// A second run the same day starts with the budget the first left
+	l := New("test_store", Limits{DailyQuota: 5})
+	if remaining, err := l.Remaining(context.Background()); err != nil || remaining != 2 {
+		t.Fatalf("expected 2 remaining requests, got %d (%v)", remaining, err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatalf("request %d: unexpected error: %v", i+1, err)
+		}
+	}
+	if err := l.Wait(context.Background()); !errors.Is(err, sources.ErrQuotaExhausted) {
+		t.Errorf("expected ErrQuotaExhausted after the quota, got %v", err)
+	}
+	if store.used["test_store"] != 5 {
+		t.Errorf("expected 5 requests recorded, got %d", store.used["test_store"])
+	}
+}
+
+func TestLimiter_StoreFailureCountsInMemory(t *testing.T) {
+	UseStore(&memoryStore{fail: true})
+	defer UseStore(nil)
+
+	l := New("test_fail", Limits{DailyQuota: 1})
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatalf("expected the request to be allowed, got %v", err)
+	}
+	if err := l.Wait(context.Background()); !errors.Is(err, sources.ErrQuotaExhausted) {
+		t.Errorf("expected ErrQuotaExhausted from the in-memory count, got %v", err)
+	}
+}
+
+func TestLimiter_Exhaust(t *testing.T) {
+	store := &memoryStore{used: map[string]int{}}
+	UseStore(store)
+	defer UseStore(nil)
+
+	l := New("test_exhaust", Limits{DailyQuota: 500})
+	l.Exhaust(context.Background())
+
+	if store.used["test_exhaust"] != 500 {
+		t.Errorf("expected the stored quota to be used up, got %d", store.used["test_exhaust"])
+	}
+	if err := l.Wait(context.Background()); !errors.Is(err, sources.ErrQuotaExhausted) {
+		t.Errorf("expected ErrQuotaExhausted, got %v", err)
+	}
+}
+
+func TestLimiter_Unlimited(t *testing.T) {
+	l := New("test_unlimited", Limits{})
+	for i := 0; i < 100; i++ {
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if remaining, _ := l.Remaining(context.Background()); remaining != Unlimited {
+		t.Errorf("expected Unlimited, got %d", remaining)
+	}
+}
+
+func TestLimiter_Rate(t *testing.T) {
+	l := New("test_rate", Limits{RequestsPerSecond: 20})
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// The first request is immediate, the next two wait 50ms each
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("expected requests to be spaced out, took %v", elapsed)
+	}
+}
+
+func TestTransport_StopsAtQuota(t *testing.T) {
+	UseStore(nil)
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: Transport(New("test_transport", Limits{DailyQuota: 1}), nil)}
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if _, err := client.Get(server.URL); !errors.Is(err, sources.ErrQuotaExhausted) {
+		t.Errorf("expected ErrQuotaExhausted, got %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("expected 1 request to reach the server, got %d", requests)
+	}
+}
+
+func TestFromConfig(t *testing.T) {
+	t.Setenv("DATABASE_URL", "postgres://localhost/test")
+	t.Setenv("SYNC_RATE_LIMIT_TESTCFG", "0.5")
+	t.Setenv("SYNC_DAILY_QUOTA_TESTCFG", "100")
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	limits := FromConfig(cfg, "testcfg", Limits{RequestsPerSecond: 4, DailyQuota: 500}).Limits()
+	if limits.RequestsPerSecond != 0.5 || limits.DailyQuota != 100 {
+		t.Errorf("expected the configured limits, got %+v", limits)
+	}
+
+	limits = FromConfig(cfg, "other", Limits{RequestsPerSecond: 4, DailyQuota: 500}).Limits()
+	if limits.RequestsPerSecond != 4 || limits.DailyQuota != 500 {
+		t.Errorf("expected the defaults, got %+v", limits)
+	}
+
+	t.Setenv("SYNC_DAILY_QUOTA_TESTCFG", "lots")
+	if _, err := config.Load(); err == nil {
+		t.Error("expected an error for an invalid daily quota")
+	}
+}
+
+func TestQuotaDay_FollowsEasternTime(t *testing.T) {
+	// 03:00 UTC on the 2nd is still the 1st in New York
+	day := quotaDay(time.Date(2025, 1, 2, 3, 0, 0, 0, time.UTC))
+	if want := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC); !day.Equal(want) {
+		t.Errorf("expected %v, got %v", want, day)
+	}
+}
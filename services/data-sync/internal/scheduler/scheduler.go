@@ -0,0 +1,325 @@
+// Package scheduler runs each source on its own cron cadence inside a
+// long-running process, instead of syncing everything on one weekly cron.
+//
+// Each source runs in its own loop, so a source never overlaps itself: a run
+// that outlasts its cadence simply delays the next one. Sources that
+// implement sources.Versioned are skipped when the upstream vintage matches
+// the one last synced. Runs stopped by an upstream quota are resumed from
+// their checkpoint a day later, including after a restart: on startup each
+// source's latest interrupted session is picked up again.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"github.com/dealforge/data-sync/internal/db"
+	"github.com/dealforge/data-sync/internal/sources"
+	datasync "github.com/dealforge/data-sync/internal/sync"
+)
+
+// ScheduleOff disables a source's schedule when used as its cron expression.
+const ScheduleOff = "off"
+
+// DefaultResumeAfter is how long a rate-limited run waits before resuming,
+// long enough for a daily upstream quota to reset.
+const DefaultResumeAfter = 24 * time.Hour
+
+// DefaultRetryUnavailableAfter is how long a run stopped by an upstream
+// outage waits before resuming.
+const DefaultRetryUnavailableAfter = time.Hour
+
+// Schedule is the cadence of a single source.
+type Schedule struct {
+	Source string // Registry name of the source
+	Spec   string // Standard five-field cron expression
+
+	cron cron.Schedule
+}
+
+// Next returns the first activation time after t.
+func (s Schedule) Next(t time.Time) time.Time {
+	return s.cron.Next(t)
+}
+
+// EnvKey returns the environment variable that overrides a source's
+// schedule, e.g. SYNC_SCHEDULE_BLS.
+func EnvKey(source string) string {
+	return "SYNC_SCHEDULE_" + strings.ToUpper(source)
+}
+
+// LoadSchedules resolves the cadence of each source: the EnvKey setting if
+// present, otherwise the source's default (see sources.Scheduled). Sources
+// set to ScheduleOff, or without either setting, are not scheduled.
+func LoadSchedules(srcs []sources.Source, lookup func(key string) string) ([]Schedule, error) {
+	var schedules []Schedule
+	for _, src := range srcs {
+		spec := strings.TrimSpace(lookup(EnvKey(src.Name())))
+		if spec == "" {
+			if scheduled, ok := src.(sources.Scheduled); ok {
+				spec = scheduled.DefaultSchedule()
+			}
+		}
+		if spec == "" || strings.EqualFold(spec, ScheduleOff) {
+			continue
+		}
+
+		parsed, err := cron.ParseStandard(spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule %q for %s: %w", spec, src.Name(), err)
+		}
+		schedules = append(schedules, Schedule{Source: src.Name(), Spec: spec, cron: parsed})
+	}
+	return schedules, nil
+}
+
+// Scope identifies the set of states a vintage was synced for, so that
+// widening a schedule to new states is not skipped as already synced.
+func Scope(p sources.Params) string {
+	states := p.SelectedStates()
+	codes := make([]string, 0, len(states))
+	for _, state := range states {
+		codes = append(codes, state.Code)
+	}
+	return strings.Join(codes, ",")
+}
+
+// Scheduler runs sources on their schedules.
+type Scheduler struct {
+	db            *db.Client
+	sources       map[string]sources.Source
+	schedules     []Schedule
+	params        sources.Params
+	maxConcurrent int
+	dryRun        bool
+
+	ResumeAfter           time.Duration // Delay before resuming a rate-limited run
+	RetryUnavailableAfter time.Duration // Delay before resuming a run stopped by an upstream outage
+	ResumeLatest          bool          // Resume each source's latest interrupted session on startup
+	MaxResumeAge          time.Duration // Older sessions are not resumed on startup
+}
+
+// pendingResume is a rate-limited or upstream-unavailable run waiting to be
+// resumed.
+type pendingResume struct {
+	sessionID string
+	vintage   string // Recorded once the resumed run completes
+	at        time.Time
+}
+
+// New creates a scheduler for the given sources and schedules. Every run
+// uses the same parameters.
+func New(dbClient *db.Client, srcs []sources.Source, schedules []Schedule, p sources.Params, maxConcurrent int, dryRun bool) *Scheduler {
+	byName := make(map[string]sources.Source, len(srcs))
+	for _, src := range srcs {
+		byName[src.Name()] = src
+	}
+
+	return &Scheduler{
+		db:            dbClient,
+		sources:       byName,
+		schedules:     schedules,
+		params:        p,
+		maxConcurrent: maxConcurrent,
+		dryRun:        dryRun,
+		ResumeAfter:   DefaultResumeAfter,
+		ResumeLatest:  true,
+		MaxResumeAge:  datasync.DefaultMaxResumeAge,
+
+		RetryUnavailableAfter: DefaultRetryUnavailableAfter,
+	}
+}
+
+// Run runs every schedule until ctx is cancelled.
+func (s *Scheduler) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	for _, sched := range s.schedules {
+		src, ok := s.sources[sched.Source]
+		if !ok {
+			return fmt.Errorf("no source configured for schedule %s", sched.Source)
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.loop(ctx, sched, src)
+		}()
+	}
+
+	wg.Wait()
+	return ctx.Err()
+}
+
+// loop runs a single source on its schedule. A pending resume takes the
+// place of scheduled runs until it has finished, so a fresh run never
+// competes with it for the upstream quota.
+func (s *Scheduler) loop(ctx context.Context, sched Schedule, src sources.Source) {
+	var pending *pendingResume
+	if s.ResumeLatest {
+		pending = s.latestResume(ctx, src)
+	}
+
+	for {
+		next := sched.Next(time.Now())
+		if pending != nil {
+			next = pending.at
+		}
+		slog.Info("next scheduled sync",
+			"source", sched.Source,
+			"at", next,
+			"resume_session", resumeSession(pending),
+		)
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		if pending != nil {
+			pending = s.sync(ctx, src, pending.sessionID, pending.vintage)
+		} else {
+			pending = s.runScheduled(ctx, src)
+		}
+	}
+}
+
+// runScheduled syncs a source unless its upstream vintage is unchanged.
+func (s *Scheduler) runScheduled(ctx context.Context, src sources.Source) *pendingResume {
+	name := src.Name()
+
+	vintage := ""
+	if versioned, ok := src.(sources.Versioned); ok {
+		latest, err := versioned.LatestVintage(ctx, s.params)
+		switch {
+		case errors.Is(err, sources.ErrVintageUnavailable):
+			slog.Info("skipping scheduled sync, vintage not yet published", "source", name, "reason", err)
+			return nil
+		case err != nil:
+			slog.Warn("failed to check upstream vintage, syncing anyway", "source", name, "error", err)
+		default:
+			synced, err := s.db.GetSyncedVintage(ctx, name, Scope(s.params))
+			if err != nil {
+				slog.Warn("failed to load synced vintage, syncing anyway", "source", name, "error", err)
+			} else if synced == latest {
+				slog.Info("skipping scheduled sync, vintage unchanged", "source", name, "vintage", latest)
+				return nil
+			}
+			vintage = latest
+		}
+	}
+
+	return s.sync(ctx, src, "", vintage)
+}
+
+// sync runs a source and records the synced vintage once it has fully
+// completed. A rate-limited run, or one stopped by an upstream outage, is
+// returned as a pending resume.
+func (s *Scheduler) sync(ctx context.Context, src sources.Source, resumeSessionID, vintage string) *pendingResume {
+	name := src.Name()
+	slog.Info("starting scheduled sync", "source", name, "vintage", vintage, "resume_session", resumeSessionID)
+
+	orch := datasync.NewOrchestrator(s.db, s.maxConcurrent, s.dryRun)
+	result, err := orch.Sync(ctx, src, s.params, resumeSessionID)
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.Error("scheduled sync failed", "source", name, "error", err)
+		}
+		return nil
+	}
+
+	if result.RateLimited && result.SessionID != "" {
+		at := time.Now().Add(s.ResumeAfter)
+		slog.Warn("scheduled sync rate limited, will resume",
+			"source", name,
+			"session_id", result.SessionID,
+			"resume_at", at,
+		)
+		return &pendingResume{sessionID: result.SessionID, vintage: vintage, at: at}
+	}
+
+	if result.Unavailable && result.SessionID != "" {
+		at := time.Now().Add(s.RetryUnavailableAfter)
+		slog.Warn("scheduled sync stopped by upstream outage, will resume",
+			"source", name,
+			"session_id", result.SessionID,
+			"deferred_units", result.Deferred,
+			"resume_at", at,
+		)
+		return &pendingResume{sessionID: result.SessionID, vintage: vintage, at: at}
+	}
+
+	if result.Failed > 0 {
+		slog.Warn("scheduled sync had failures, vintage not recorded", "source", name, "failed_units", result.Failed)
+		return nil
+	}
+
+	if vintage != "" && !s.dryRun {
+		if err := s.db.SetSyncedVintage(ctx, name, Scope(s.params), vintage); err != nil {
+			slog.Warn("failed to record synced vintage", "source", name, "vintage", vintage, "error", err)
+		}
+	}
+
+	slog.Info("scheduled sync completed", "source", name, "vintage", vintage, "records", result.Successful)
+	return nil
+}
+
+// latestResume returns a pending resume for the source's latest interrupted
+// session, if it has one that can be resumed.
+func (s *Scheduler) latestResume(ctx context.Context, src sources.Source) *pendingResume {
+	name := src.Name()
+
+	checkpoint, err := datasync.LatestResumable(ctx, s.db, src, s.params, s.MaxResumeAge)
+	switch {
+	case errors.Is(err, datasync.ErrResumeRefused):
+		slog.Warn("not resuming latest session", "source", name, "reason", err)
+		return nil
+	case err != nil:
+		slog.Warn("failed to look up latest session", "source", name, "error", err)
+		return nil
+	case checkpoint == nil:
+		return nil
+	}
+
+	at := resumeAt(checkpoint, s.ResumeAfter, s.RetryUnavailableAfter)
+	slog.Info("resuming latest interrupted session",
+		"source", name,
+		"session_id", checkpoint.SyncSessionID,
+		"status", checkpoint.Status,
+		"resume_at", at,
+	)
+	return &pendingResume{sessionID: checkpoint.SyncSessionID, at: at}
+}
+
+// resumeAt returns when an interrupted session should be resumed. A
+// rate-limited session waits until resumeAfter has passed since it was last
+// updated, so the upstream quota has reset, and one stopped by an upstream
+// outage waits retryUnavailableAfter; a session that was cut off mid-run
+// (e.g., by a restart) is resumed straight away.
+func resumeAt(checkpoint *db.SyncCheckpoint, resumeAfter, retryUnavailableAfter time.Duration) time.Time {
+	switch checkpoint.Status {
+	case "rate_limited":
+		return checkpoint.LastUpdatedAt.Add(resumeAfter)
+	case "upstream_unavailable":
+		return checkpoint.LastUpdatedAt.Add(retryUnavailableAfter)
+	default:
+		return time.Now()
+	}
+}
+
+// resumeSession returns the session a pending resume continues, if any.
+func resumeSession(pending *pendingResume) string {
+	if pending == nil {
+		return ""
+	}
+	return pending.sessionID
+}
@@ -0,0 +1,127 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dealforge/data-sync/internal/db"
+	"github.com/dealforge/data-sync/internal/geography"
+	"github.com/dealforge/data-sync/internal/sources"
+)
+
+type fakeSource struct{ name string }
+
+func (s *fakeSource) Name() string             { return s.name }
+func (s *fakeSource) RequiredConfig() []string { return nil }
+func (s *fakeSource) WorkUnits(ctx context.Context, p sources.Params) ([]sources.WorkUnit, error) {
+	return nil, nil
+}
+func (s *fakeSource) Fetch(ctx context.Context, unit sources.WorkUnit, p sources.Params) (sources.Records, error) {
+	return nil, nil
+}
+func (s *fakeSource) Persist(ctx context.Context, store *db.Client, records sources.Records) error {
+	return nil
+}
+
+// scheduledSource has a default cadence.
+type scheduledSource struct {
+	fakeSource
+	spec string
+}
+
+func (s *scheduledSource) DefaultSchedule() string { return s.spec }
+
+func lookupFrom(env map[string]string) func(string) string {
+	return func(key string) string { return env[key] }
+}
+
+func TestLoadSchedules(t *testing.T) {
+	srcs := []sources.Source{
+		&scheduledSource{fakeSource{"hud"}, "0 3 1 * *"},
+		&scheduledSource{fakeSource{"bls"}, "0 5 * * 2"},
+		&scheduledSource{fakeSource{"census"}, "0 4 * * 1"},
+		&fakeSource{"manual"},
+	}
+	env := map[string]string{
+		"SYNC_SCHEDULE_BLS":    "30 6 * * *",
+		"SYNC_SCHEDULE_CENSUS": "off",
+	}
+
+	schedules, err := LoadSchedules(srcs, lookupFrom(env))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := make(map[string]string)
+	for _, s := range schedules {
+		got[s.Source] = s.Spec
+	}
+	want := map[string]string{
+		"hud": "0 3 1 * *",  // Source default
+		"bls": "30 6 * * *", // Environment override
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected schedules %v, got %v", want, got)
+	}
+	for source, spec := range want {
+		if got[source] != spec {
+			t.Errorf("expected %s schedule %q, got %q", source, spec, got[source])
+		}
+	}
+}
+
+func TestLoadSchedules_Invalid(t *testing.T) {
+	srcs := []sources.Source{&fakeSource{"bls"}}
+	if _, err := LoadSchedules(srcs, lookupFrom(map[string]string{"SYNC_SCHEDULE_BLS": "every tuesday"})); err == nil {
+		t.Error("expected an error for an invalid cron expression")
+	}
+}
+
+func TestSchedule_Next(t *testing.T) {
+	schedules, err := LoadSchedules([]sources.Source{&fakeSource{"bls"}}, lookupFrom(map[string]string{"SYNC_SCHEDULE_BLS": "0 5 * * 2"}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Wednesday 2024-08-14 -> Tuesday 2024-08-20 05:00
+	from := time.Date(2024, 8, 14, 12, 0, 0, 0, time.UTC)
+	want := time.Date(2024, 8, 20, 5, 0, 0, 0, time.UTC)
+	if next := schedules[0].Next(from); !next.Equal(want) {
+		t.Errorf("expected next run at %s, got %s", want, next)
+	}
+}
+
+func TestScope(t *testing.T) {
+	if got := Scope(sources.Params{}); got != sources.DefaultState {
+		t.Errorf("expected default scope %q, got %q", sources.DefaultState, got)
+	}
+
+	states, err := geography.ParseStateList("TX,OK")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := Scope(sources.Params{States: states}); got != "TX,OK" {
+		t.Errorf("expected scope TX,OK, got %q", got)
+	}
+}
+
+func TestResumeAt(t *testing.T) {
+	updated := time.Date(2024, 8, 14, 12, 0, 0, 0, time.UTC)
+
+	rateLimited := &db.SyncCheckpoint{Status: "rate_limited", LastUpdatedAt: updated}
+	if at := resumeAt(rateLimited, DefaultResumeAfter, DefaultRetryUnavailableAfter); !at.Equal(updated.Add(DefaultResumeAfter)) {
+		t.Errorf("expected rate-limited session to resume at %s, got %s", updated.Add(DefaultResumeAfter), at)
+	}
+
+	unavailable := &db.SyncCheckpoint{Status: "upstream_unavailable", LastUpdatedAt: updated}
+	if at := resumeAt(unavailable, DefaultResumeAfter, DefaultRetryUnavailableAfter); !at.Equal(updated.Add(DefaultRetryUnavailableAfter)) {
+		t.Errorf("expected upstream-unavailable session to resume at %s, got %s", updated.Add(DefaultRetryUnavailableAfter), at)
+	}
+
+	// A session cut off mid-run is resumed straight away
+	inProgress := &db.SyncCheckpoint{Status: "in_progress", LastUpdatedAt: updated}
+	if at := resumeAt(inProgress, DefaultResumeAfter, DefaultRetryUnavailableAfter); time.Until(at) > time.Second {
+		t.Errorf("expected in-progress session to resume now, got %s", at)
+	}
+}
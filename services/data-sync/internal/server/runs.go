@@ -0,0 +1,296 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/dealforge/data-sync/internal/db"
+	"github.com/dealforge/data-sync/internal/geography"
+	"github.com/dealforge/data-sync/internal/sources"
+	datasync "github.com/dealforge/data-sync/internal/sync"
+)
+
+// Run statuses. A finished run has the worst status of its sources, as
+// recorded in the sync run ledger.
+const (
+	RunRunning     = "running"
+	RunCompleted   = db.RunCompleted
+	RunPartial     = db.RunPartial
+	RunRateLimited = db.RunRateLimited
+	RunUnavailable = db.RunUnavailable
+	RunFailed      = db.RunFailed
+	RunCancelled   = db.RunCancelled
+)
+
+// statusRank orders the statuses of finished sources from best to worst.
+var statusRank = map[string]int{
+	RunCompleted:   0,
+	RunPartial:     1,
+	RunRateLimited: 2,
+	RunUnavailable: 3,
+	RunFailed:      4,
+	RunCancelled:   5,
+}
+
+// maxRunHistory bounds how many finished runs are kept in memory.
+const maxRunHistory = 50
+
+// RunRequest is the body of a trigger request. Zero values fall back to the
+// same defaults as the command line.
+type RunRequest struct {
+	Sources      []string `json:"sources"`   // Defaults to every configured source
+	States       []string `json:"states"`    // Defaults to Texas
+	ZIPs         []string `json:"zips"`      // HUD Small Area FMRs
+	Counties     []string `json:"counties"`  // Census tracts and ZCTAs
+	GeoLevel     string   `json:"geo_level"` // Census geography level, defaults to county
+	CensusYear   int      `json:"census_year"`
+	BLSStartYear int      `json:"bls_start_year"`
+	BLSEndYear   int      `json:"bls_end_year"`
+	DryRun       bool     `json:"dry_run"`
+}
+
+// Run is a sync triggered through the API.
+type Run struct {
+	ID         string                 `json:"id"`
+	Request    RunRequest             `json:"request"`
+	Status     string                 `json:"status"`
+	StartedAt  time.Time              `json:"started_at"`
+	FinishedAt *time.Time             `json:"finished_at,omitempty"`
+	Progress   []datasync.Progress    `json:"progress"` // One entry per source started so far
+	Results    []*datasync.SyncResult `json:"results,omitempty"`
+	Errors     []string               `json:"errors,omitempty"`
+
+	cancel context.CancelFunc
+}
+
+// snapshot returns a copy of the run that is safe to encode while the run
+// keeps going. Callers hold the server mutex.
+func (r *Run) snapshot() Run {
+	c := *r
+	c.Progress = append([]datasync.Progress(nil), r.Progress...)
+	c.Results = append([]*datasync.SyncResult(nil), r.Results...)
+	c.Errors = append([]string(nil), r.Errors...)
+	return c
+}
+
+// plan resolves a request into the sources to run and their parameters.
+func (s *Server) plan(req RunRequest) ([]sources.Source, sources.Params, error) {
+	names, err := sources.ParseList(strings.Join(req.Sources, ","))
+	if err != nil {
+		return nil, sources.Params{}, err
+	}
+
+	selected := make([]sources.Source, 0, len(names))
+	for _, name := range names {
+		src, ok := s.sources[name]
+		if !ok {
+			return nil, sources.Params{}, fmt.Errorf("source %q is not configured on this server", name)
+		}
+		selected = append(selected, src)
+	}
+
+	stateList := strings.Join(req.States, ",")
+	if stateList == "" {
+		stateList = sources.DefaultState
+	}
+	states, err := geography.ParseStateList(stateList)
+	if err != nil {
+		return nil, sources.Params{}, err
+	}
+
+	zips, err := geography.ParseZIPList(strings.Join(req.ZIPs, ","))
+	if err != nil {
+		return nil, sources.Params{}, err
+	}
+	// An empty list would sync whole states rather than none
+	if len(req.ZIPs) > 0 && len(zips) == 0 {
+		return nil, sources.Params{}, fmt.Errorf("zips lists no ZIP codes")
+	}
+
+	counties, err := geography.ParseCountyList(strings.Join(req.Counties, ","))
+	if err != nil {
+		return nil, sources.Params{}, err
+	}
+
+	// The Census geography levels, checked here so a bad request is rejected
+	// before the run starts
+	switch req.GeoLevel {
+	case "", "county":
+	case "tract", "zcta":
+		if len(counties) == 0 {
+			return nil, sources.Params{}, fmt.Errorf("geo_level %s requires counties", req.GeoLevel)
+		}
+	default:
+		return nil, sources.Params{}, fmt.Errorf("unknown geo_level %q (expected county, tract or zcta)", req.GeoLevel)
+	}
+
+	params := sources.Params{
+		States:    states,
+		ZIPs:      zips,
+		Counties:  counties,
+		GeoLevel:  req.GeoLevel,
+		Year:      req.CensusYear,
+		StartYear: req.BLSStartYear,
+		EndYear:   req.BLSEndYear,
+	}
+	return selected, params, nil
+}
+
+// startRun registers a run and executes it in the background. Only one run
+// may be active at a time, so two runs never write the same tables at once.
+func (s *Server) startRun(req RunRequest) (Run, error) {
+	selected, params, err := s.plan(req)
+	if err != nil {
+		return Run{}, fmt.Errorf("%w: %v", errInvalidRequest, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.active != "" {
+		return Run{}, fmt.Errorf("%w: run %s", errRunInProgress, s.active)
+	}
+
+	ctx, cancel := context.WithCancel(s.baseCtx)
+	run := &Run{
+		ID:        "run_" + uuid.New().String(),
+		Request:   req,
+		Status:    RunRunning,
+		StartedAt: time.Now(),
+		cancel:    cancel,
+	}
+	s.runs[run.ID] = run
+	s.order = append(s.order, run.ID)
+	s.active = run.ID
+	s.pruneLocked()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer cancel()
+		s.execute(ctx, run, selected, params)
+	}()
+
+	return run.snapshot(), nil
+}
+
+// execute syncs each selected source in turn, recording progress and results
+// on the run as it goes.
+func (s *Server) execute(ctx context.Context, run *Run, selected []sources.Source, params sources.Params) {
+	slog.Info("starting API sync run", "run_id", run.ID, "request", run.Request)
+
+	status := RunCompleted
+	for _, src := range selected {
+		if ctx.Err() != nil {
+			break
+		}
+
+		orch := datasync.NewOrchestrator(s.db, s.maxConcurrent, run.Request.DryRun)
+		idx := s.addProgress(run, src.Name())
+		orch.OnProgress(func(p datasync.Progress) {
+			s.mu.Lock()
+			run.Progress[idx] = p
+			s.mu.Unlock()
+		})
+
+		result, err := orch.Sync(ctx, src, params, "")
+		if srcStatus := datasync.RunStatus(ctx, result, err); statusRank[srcStatus] > statusRank[status] {
+			status = srcStatus
+		}
+
+		s.mu.Lock()
+		if err != nil {
+			run.Errors = append(run.Errors, fmt.Sprintf("%s: %v", src.Name(), err))
+		} else {
+			run.Results = append(run.Results, result)
+		}
+		s.mu.Unlock()
+
+		if err != nil {
+			slog.Error("sync failed", "run_id", run.ID, "source", src.Name(), "error", err)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	run.FinishedAt = &now
+	run.Status = status
+	if ctx.Err() != nil {
+		run.Status = RunCancelled
+	}
+	if s.active == run.ID {
+		s.active = ""
+	}
+
+	slog.Info("API sync run finished", "run_id", run.ID, "status", run.Status, "duration", now.Sub(run.StartedAt))
+}
+
+// addProgress appends an empty progress entry for a source and returns its
+// index.
+func (s *Server) addProgress(run *Run, source string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	run.Progress = append(run.Progress, datasync.Progress{Source: source})
+	return len(run.Progress) - 1
+}
+
+// cancelRun cancels a running run. It returns false if the run has already
+// finished.
+func (s *Server) cancelRun(id string) (Run, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	run, ok := s.runs[id]
+	if !ok {
+		return Run{}, false, errRunNotFound
+	}
+	if run.Status != RunRunning {
+		return run.snapshot(), false, nil
+	}
+	run.cancel()
+	return run.snapshot(), true, nil
+}
+
+// getRun returns a snapshot of a run by ID.
+func (s *Server) getRun(id string) (Run, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	run, ok := s.runs[id]
+	if !ok {
+		return Run{}, errRunNotFound
+	}
+	return run.snapshot(), nil
+}
+
+// listRuns returns snapshots of the retained runs, newest first.
+func (s *Server) listRuns() []Run {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	runs := make([]Run, 0, len(s.order))
+	for i := len(s.order) - 1; i >= 0; i-- {
+		runs = append(runs, s.runs[s.order[i]].snapshot())
+	}
+	return runs
+}
+
+// pruneLocked drops the oldest finished runs beyond maxRunHistory.
+// Callers hold the server mutex.
+func (s *Server) pruneLocked() {
+	for len(s.order) > maxRunHistory {
+		oldest := s.order[0]
+		if oldest == s.active {
+			return
+		}
+		delete(s.runs, oldest)
+		s.order = s.order[1:]
+	}
+}
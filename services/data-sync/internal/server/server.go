@@ -0,0 +1,203 @@
+// Package server implements the serve mode HTTP API, which lets admins
+// trigger, monitor and cancel syncs without waiting for the weekly schedule.
+//
+// The server keeps the database pool and source clients warm between runs.
+// Every endpoint except /healthz requires the configured bearer token.
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/dealforge/data-sync/internal/db"
+	"github.com/dealforge/data-sync/internal/sources"
+)
+
+// Checkpoint listing limits.
+const (
+	defaultCheckpointLimit = 20
+	maxCheckpointLimit     = 200
+)
+
+var (
+	errInvalidRequest = errors.New("invalid request")
+	errRunInProgress  = errors.New("a sync run is already in progress")
+	errRunNotFound    = errors.New("run not found")
+)
+
+// Server runs syncs on behalf of API clients.
+type Server struct {
+	db            *db.Client
+	sources       map[string]sources.Source
+	token         string
+	maxConcurrent int
+
+	// baseCtx is the parent of every run context; stop cancels it on shutdown.
+	baseCtx context.Context
+	stop    context.CancelFunc
+	wg      sync.WaitGroup
+
+	mu     sync.Mutex
+	runs   map[string]*Run
+	order  []string // Run IDs, oldest first
+	active string   // ID of the running run, if any
+}
+
+// New creates a server that runs the given sources. The sources are built
+// once and reused by every run.
+func New(dbClient *db.Client, srcs []sources.Source, token string, maxConcurrent int) *Server {
+	byName := make(map[string]sources.Source, len(srcs))
+	for _, src := range srcs {
+		byName[src.Name()] = src
+	}
+
+	ctx, stop := context.WithCancel(context.Background())
+	return &Server{
+		db:            dbClient,
+		sources:       byName,
+		token:         token,
+		maxConcurrent: maxConcurrent,
+		baseCtx:       ctx,
+		stop:          stop,
+		runs:          make(map[string]*Run),
+	}
+}
+
+// Handler returns the HTTP handler for the API.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", s.handleHealth)
+	mux.Handle("POST /runs", s.authenticate(s.handleCreateRun))
+	mux.Handle("GET /runs", s.authenticate(s.handleListRuns))
+	mux.Handle("GET /runs/{id}", s.authenticate(s.handleGetRun))
+	mux.Handle("POST /runs/{id}/cancel", s.authenticate(s.handleCancelRun))
+	mux.Handle("GET /checkpoints", s.authenticate(s.handleListCheckpoints))
+	return mux
+}
+
+// Shutdown cancels any active run and waits for it to stop, or for ctx to
+// be done.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.stop()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// authenticate rejects requests without the configured bearer token.
+func (s *Server) authenticate(next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || s.token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			writeError(w, http.StatusUnauthorized, "missing or invalid bearer token")
+			return
+		}
+		next(w, r)
+	})
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (s *Server) handleCreateRun(w http.ResponseWriter, r *http.Request) {
+	var req RunRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON body: "+err.Error())
+			return
+		}
+	}
+
+	run, err := s.startRun(req)
+	switch {
+	case errors.Is(err, errInvalidRequest):
+		writeError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, errRunInProgress):
+		writeError(w, http.StatusConflict, err.Error())
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, err.Error())
+	default:
+		writeJSON(w, http.StatusAccepted, run)
+	}
+}
+
+func (s *Server) handleListRuns(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string][]Run{"runs": s.listRuns()})
+}
+
+func (s *Server) handleGetRun(w http.ResponseWriter, r *http.Request) {
+	run, err := s.getRun(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, run)
+}
+
+func (s *Server) handleCancelRun(w http.ResponseWriter, r *http.Request) {
+	run, cancelled, err := s.cancelRun(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if !cancelled {
+		writeError(w, http.StatusConflict, "run has already finished with status "+run.Status)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, run)
+}
+
+func (s *Server) handleListCheckpoints(w http.ResponseWriter, r *http.Request) {
+	limit := defaultCheckpointLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxCheckpointLimit {
+			writeError(w, http.StatusBadRequest, "limit must be between 1 and "+strconv.Itoa(maxCheckpointLimit))
+			return
+		}
+		limit = n
+	}
+
+	checkpoints, err := s.db.ListCheckpoints(r.Context(), r.URL.Query().Get("source"), limit)
+	if err != nil {
+		slog.Error("failed to list checkpoints", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to list checkpoints")
+		return
+	}
+	if checkpoints == nil {
+		checkpoints = []*db.SyncCheckpoint{}
+	}
+	writeJSON(w, http.StatusOK, map[string][]*db.SyncCheckpoint{"checkpoints": checkpoints})
+}
+
+// writeJSON encodes v as the response body.
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Warn("failed to encode response", "error", err)
+	}
+}
+
+// writeError writes a JSON error body.
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
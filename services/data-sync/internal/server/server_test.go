@@ -0,0 +1,243 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dealforge/data-sync/internal/config"
+	"github.com/dealforge/data-sync/internal/db"
+	"github.com/dealforge/data-sync/internal/sources"
+)
+
+const testToken = "test-token"
+
+// fakeSource returns three units per state. Fetch blocks until release is
+// closed (if set) or the context is cancelled, and fails the "b" units if
+// failUnits is set.
+type fakeSource struct {
+	release   chan struct{}
+	failUnits bool
+}
+
+type fakeRecords int
+
+func (r fakeRecords) Len() int { return int(r) }
+
+func (s *fakeSource) Name() string             { return "fake" }
+func (s *fakeSource) RequiredConfig() []string { return nil }
+
+func (s *fakeSource) WorkUnits(ctx context.Context, p sources.Params) ([]sources.WorkUnit, error) {
+	var units []sources.WorkUnit
+	for _, state := range p.SelectedStates() {
+		for _, suffix := range []string{"a", "b", "c"} {
+			units = append(units, sources.WorkUnit{Key: state.Code + suffix, Name: state.Code + suffix})
+		}
+	}
+	return units, nil
+}
+
+func (s *fakeSource) Fetch(ctx context.Context, unit sources.WorkUnit, p sources.Params) (sources.Records, error) {
+	if s.release != nil {
+		select {
+		case <-s.release:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if s.failUnits && strings.HasSuffix(unit.Key, "b") {
+		return nil, errors.New("upstream error")
+	}
+	return fakeRecords(2), nil
+}
+
+func (s *fakeSource) Persist(ctx context.Context, store *db.Client, records sources.Records) error {
+	return nil
+}
+
+func init() {
+	sources.Register("fake", func(cfg *config.Config) sources.Source { return &fakeSource{} })
+}
+
+// newTestServer returns a server running src, with no database; requests
+// must use dry runs.
+func newTestServer(t *testing.T, src *fakeSource) (*Server, *httptest.Server) {
+	srv := New(nil, []sources.Source{src}, testToken, 2)
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(func() {
+		ts.Close()
+		srv.Shutdown(context.Background())
+	})
+	return srv, ts
+}
+
+func doRequest(t *testing.T, ts *httptest.Server, method, path, body string) (*http.Response, map[string]interface{}) {
+	t.Helper()
+
+	req, err := http.NewRequest(method, ts.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("failed to build request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+testToken)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var decoded map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&decoded)
+	return resp, decoded
+}
+
+// waitForStatus polls a run until it leaves the running state.
+func waitForStatus(t *testing.T, ts *httptest.Server, id string) map[string]interface{} {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		_, run := doRequest(t, ts, http.MethodGet, "/runs/"+id, "")
+		if run["status"] != RunRunning {
+			return run
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("run %s did not finish", id)
+	return nil
+}
+
+func TestAuthentication(t *testing.T) {
+	_, ts := newTestServer(t, &fakeSource{})
+
+	resp, err := http.Get(ts.URL + "/healthz")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected healthz status 200, got %d", resp.StatusCode)
+	}
+
+	for _, header := range []string{"", "Bearer wrong-token", testToken} {
+		req, _ := http.NewRequest(http.MethodGet, ts.URL+"/runs", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Authorization %q: expected status 401, got %d", header, resp.StatusCode)
+		}
+	}
+}
+
+func TestCreateRun_Completes(t *testing.T) {
+	_, ts := newTestServer(t, &fakeSource{})
+
+	resp, run := doRequest(t, ts, http.MethodPost, "/runs", `{"sources":["fake"],"states":["TX","OK"],"dry_run":true}`)
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("expected status 202, got %d: %v", resp.StatusCode, run)
+	}
+
+	run = waitForStatus(t, ts, run["id"].(string))
+	if run["status"] != RunCompleted {
+		t.Fatalf("expected status completed, got %v (errors: %v)", run["status"], run["errors"])
+	}
+
+	progress := run["progress"].([]interface{})[0].(map[string]interface{})
+	if progress["total_units"].(float64) != 6 || progress["done_units"].(float64) != 6 {
+		t.Errorf("expected 6 of 6 units done, got %v", progress)
+	}
+	result := run["results"].([]interface{})[0].(map[string]interface{})
+	if result["successful"].(float64) != 12 {
+		t.Errorf("expected 12 successful records, got %v", result["successful"])
+	}
+}
+
+func TestCreateRun_GeographyParams(t *testing.T) {
+	_, ts := newTestServer(t, &fakeSource{})
+
+	body := `{"sources":["fake"],"zips":["78201"],"counties":["48029"],"geo_level":"zcta","dry_run":true}`
+	resp, run := doRequest(t, ts, http.MethodPost, "/runs", body)
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("expected status 202, got %d: %v", resp.StatusCode, run)
+	}
+
+	request := run["request"].(map[string]interface{})
+	if request["geo_level"] != "zcta" || len(request["zips"].([]interface{})) != 1 || len(request["counties"].([]interface{})) != 1 {
+		t.Errorf("expected the geography parameters to be kept, got %v", request)
+	}
+	waitForStatus(t, ts, run["id"].(string))
+}
+
+func TestCreateRun_PartialStatus(t *testing.T) {
+	_, ts := newTestServer(t, &fakeSource{failUnits: true})
+
+	_, run := doRequest(t, ts, http.MethodPost, "/runs", `{"sources":["fake"],"dry_run":true}`)
+	run = waitForStatus(t, ts, run["id"].(string))
+	if run["status"] != RunPartial {
+		t.Errorf("expected status partial for a run with failed units, got %v", run["status"])
+	}
+}
+
+func TestCreateRun_InvalidRequest(t *testing.T) {
+	_, ts := newTestServer(t, &fakeSource{})
+
+	for _, body := range []string{
+		`{"sources":["nope"]}`,
+		`{"states":["ZZ"]}`,
+		`{"zips":["ABCDE"]}`,
+		`{"zips":[" "]}`,
+		`{"counties":["480"]}`,
+		`{"geo_level":"tract"}`,
+		`{"geo_level":"block","counties":["48029"]}`,
+		`{"sources":`,
+	} {
+		resp, _ := doRequest(t, ts, http.MethodPost, "/runs", body)
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("body %s: expected status 400, got %d", body, resp.StatusCode)
+		}
+	}
+}
+
+func TestCreateRun_ConflictAndCancel(t *testing.T) {
+	src := &fakeSource{release: make(chan struct{})}
+	_, ts := newTestServer(t, src)
+
+	_, run := doRequest(t, ts, http.MethodPost, "/runs", `{"dry_run":true}`)
+	id := run["id"].(string)
+
+	resp, _ := doRequest(t, ts, http.MethodPost, "/runs", `{"dry_run":true}`)
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("expected status 409 while a run is active, got %d", resp.StatusCode)
+	}
+
+	resp, _ = doRequest(t, ts, http.MethodPost, "/runs/"+id+"/cancel", "")
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("expected status 202 for cancel, got %d", resp.StatusCode)
+	}
+
+	run = waitForStatus(t, ts, id)
+	if run["status"] != RunCancelled {
+		t.Errorf("expected status cancelled, got %v", run["status"])
+	}
+
+	resp, _ = doRequest(t, ts, http.MethodPost, "/runs/"+id+"/cancel", "")
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("expected status 409 for cancelling a finished run, got %d", resp.StatusCode)
+	}
+
+	resp, _ = doRequest(t, ts, http.MethodGet, "/runs/run_missing", "")
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status 404 for an unknown run, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,11 @@
+// Package all links every data source into the binary.
+//
+// Importing it for side effects registers each source with the sources
+// registry. New source packages only need a blank import added here.
+package all
+
+import (
+	_ "github.com/dealforge/data-sync/internal/sources/bls"
+	_ "github.com/dealforge/data-sync/internal/sources/census"
+	_ "github.com/dealforge/data-sync/internal/sources/hud"
+)
@@ -4,41 +4,73 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/dealforge/data-sync/internal/db"
+	"github.com/dealforge/data-sync/internal/geography"
+	"github.com/dealforge/data-sync/internal/httpretry"
+	"github.com/dealforge/data-sync/internal/ratelimit"
+	"github.com/dealforge/data-sync/internal/sources"
+	"github.com/dealforge/data-sync/internal/tracing"
+	"github.com/dealforge/data-sync/internal/upstream"
 )
 
 // ErrDailyLimitReached is returned when the BLS API daily request limit is exceeded.
-var ErrDailyLimitReached = errors.New("BLS API daily request limit reached")
+// It wraps sources.ErrQuotaExhausted so the orchestrator can stop the run early.
+var ErrDailyLimitReached = fmt.Errorf("BLS API daily request limit reached: %w", sources.ErrQuotaExhausted)
 
 const (
 	baseURLV1 = "https://api.bls.gov/publicAPI/v1/timeseries/data/"
 	baseURLV2 = "https://api.bls.gov/publicAPI/v2/timeseries/data/"
+
+	// Maximum number of series per request for each API version
+	maxSeriesV1 = 25
+	maxSeriesV2 = 50
 )
 
 // Client provides access to the BLS LAUS API.
 type Client struct {
 	apiKey     string
 	httpClient *http.Client
+	limiter    *ratelimit.Limiter // Nil for clients built with NewClientWithHTTPClient
 }
 
-// NewClient creates a new BLS API client.
+// NewClient creates a new BLS API client, throttled to DefaultLimits and
+// retrying transient failures with the default policy.
 func NewClient(apiKey string) *Client {
+	return newClient(apiKey, ratelimit.New(SourceName, DefaultLimits(apiKey)), httpretry.DefaultMaxRetries)
+}
+
+// newClient creates a BLS API client whose requests wait for limiter and
+// are retried up to maxRetries times.
+func newClient(apiKey string, limiter *ratelimit.Limiter, maxRetries int) *Client {
+	policy := httpretry.DefaultPolicy()
+	policy.MaxRetries = maxRetries
+	policy.AttemptTimeout = 60 * time.Second
+
 	return &Client{
-		apiKey: apiKey,
-		httpClient: &http.Client{
-			Timeout: 60 * time.Second,
-		},
+		apiKey:     apiKey,
+		limiter:    limiter,
+		httpClient: &http.Client{Transport: upstream.Transport(SourceName, limiter, policy)},
 	}
 }
 
+// DefaultLimits returns the published limits of the BLS API: 500 queries a
+// day with a key (v2) and 25 without (v1), at no more than 50 requests per
+// 10 seconds; 4 requests per second leaves some headroom.
+func DefaultLimits(apiKey string) ratelimit.Limits {
+	if apiKey != "" {
+		return ratelimit.Limits{RequestsPerSecond: 4, DailyQuota: 500}
+	}
+	return ratelimit.Limits{RequestsPerSecond: 4, DailyQuota: 25}
+}
+
 // NewClientWithHTTPClient creates a new BLS API client with a custom HTTP client.
 // This is primarily for testing purposes.
 func NewClientWithHTTPClient(apiKey string, httpClient *http.Client) *Client {
@@ -48,55 +80,38 @@ func NewClientWithHTTPClient(apiKey string, httpClient *http.Client) *Client {
 	}
 }
 
-// GetCountyEmploymentWithRetry fetches LAUS employment data with automatic retry logic.
-// It will retry transient failures (HTTP errors, timeouts) with exponential backoff.
-// Rate limit errors (ErrDailyLimitReached) are NOT retried.
-func (c *Client) GetCountyEmploymentWithRetry(ctx context.Context, countyFIPS, countyName string, startYear, endYear, maxRetries int) ([]*db.BLSEmployment, error) {
-	var lastErr error
-
-	for attempt := 0; attempt <= maxRetries; attempt++ {
-		records, err := c.GetCountyEmployment(ctx, countyFIPS, countyName, startYear, endYear)
-
-		// Success - return immediately
-		if err == nil {
-			return records, nil
-		}
-
-		// Don't retry rate limit errors - these should fail immediately
-		if errors.Is(err, ErrDailyLimitReached) {
-			return nil, err
-		}
-
-		lastErr = err
-
-		// If we've exhausted retries, return the last error
-		if attempt >= maxRetries {
-			break
-		}
-
-		// Exponential backoff: 1s, 2s, 4s, 8s, etc.
-		backoff := time.Second * time.Duration(1<<uint(attempt))
+// GetCountyEmployment fetches LAUS employment data for a single county.
+func (c *Client) GetCountyEmployment(ctx context.Context, stateFIPS, countyFIPS, countyName string, startYear, endYear int) ([]*db.BLSEmployment, error) {
+	return c.GetBatchEmployment(ctx, []County{{StateFIPS: stateFIPS, CountyFIPS: countyFIPS, Name: countyName}}, startYear, endYear)
+}
 
-		// Check if context is cancelled before sleeping
-		select {
-		case <-ctx.Done():
-			return nil, fmt.Errorf("context cancelled during retry: %w", ctx.Err())
-		case <-time.After(backoff):
-			// Continue to next retry attempt
-		}
+// CountiesPerRequest returns how many counties fit in a single request.
+// Each county needs one series per LAUS measure, and the v2 API (used when
+// an API key is set) accepts more series per request than v1.
+func (c *Client) CountiesPerRequest() int {
+	if c.apiKey != "" {
+		return maxSeriesV2 / len(lausMeasures)
 	}
-
-	return nil, fmt.Errorf("max retries (%d) exceeded: %w", maxRetries, lastErr)
+	return maxSeriesV1 / len(lausMeasures)
 }
 
-// GetCountyEmployment fetches LAUS employment data for a Texas county.
-func (c *Client) GetCountyEmployment(ctx context.Context, countyFIPS, countyName string, startYear, endYear int) ([]*db.BLSEmployment, error) {
-	// Build series IDs for all measures
-	seriesIDs := []string{
-		BuildSeriesID(countyFIPS, LAUSLaborForce),
-		BuildSeriesID(countyFIPS, LAUSEmployed),
-		BuildSeriesID(countyFIPS, LAUSUnemployed),
-		BuildSeriesID(countyFIPS, LAUSUnemploymentRate),
+// GetBatchEmployment fetches LAUS employment data for several counties in a
+// single request and splits the response back into per-county records.
+// At most CountiesPerRequest counties may be requested at once.
+func (c *Client) GetBatchEmployment(ctx context.Context, counties []County, startYear, endYear int) ([]*db.BLSEmployment, error) {
+	if len(counties) == 0 {
+		return nil, nil
+	}
+	if len(counties) > c.CountiesPerRequest() {
+		return nil, fmt.Errorf("batch of %d counties exceeds the limit of %d per request", len(counties), c.CountiesPerRequest())
+	}
+
+	// Build series IDs for all measures of every county
+	seriesIDs := make([]string, 0, len(counties)*len(lausMeasures))
+	for _, county := range counties {
+		for _, measure := range lausMeasures {
+			seriesIDs = append(seriesIDs, BuildSeriesID(county.StateFIPS, county.CountyFIPS, measure))
+		}
 	}
 
 	// Build request body
@@ -130,9 +145,6 @@ func (c *Client) GetCountyEmployment(ctx context.Context, countyFIPS, countyName
 	}
 	defer resp.Body.Close()
 
-	// Rate limit: BLS v2 API allows 50 requests per 10 seconds (5/sec)
-	time.Sleep(250 * time.Millisecond)
-
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response body: %w", err)
@@ -143,7 +155,7 @@ func (c *Client) GetCountyEmployment(ctx context.Context, countyFIPS, countyName
 	}
 
 	var blsResp LAUSResponse
-	if err := json.Unmarshal(body, &blsResp); err != nil {
+	if err := tracing.DecodeJSON(ctx, SourceName, body, &blsResp); err != nil {
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
@@ -151,21 +163,105 @@ func (c *Client) GetCountyEmployment(ctx context.Context, countyFIPS, countyName
 		// Check for daily rate limit exceeded
 		for _, msg := range blsResp.Message {
 			if strings.Contains(strings.ToLower(msg), "daily threshold") {
-				return nil, ErrDailyLimitReached
+				return nil, c.dailyLimitReached(ctx)
 			}
 		}
 		return nil, fmt.Errorf("BLS API error: %v", blsResp.Message)
 	}
 
-	return c.parseResponse(&blsResp, countyFIPS, countyName)
+	return c.parseResponse(&blsResp, counties)
 }
 
-// parseResponse converts the BLS API response to database records.
-func (c *Client) parseResponse(resp *LAUSResponse, countyFIPS, countyName string) ([]*db.BLSEmployment, error) {
-	// Group data by year/month
+// dailyLimitReached records that BLS refused further requests today, so
+// later runs today know the quota is gone without spending a request.
+func (c *Client) dailyLimitReached(ctx context.Context) error {
+	if c.limiter != nil {
+		c.limiter.Exhaust(ctx)
+	}
+	return ErrDailyLimitReached
+}
+
+// GetLatestPeriod returns the year and month of the most recent monthly
+// observation of a series, using a single request for its latest value.
+func (c *Client) GetLatestPeriod(ctx context.Context, seriesID string) (year, month int, err error) {
+	params := url.Values{}
+	params.Set("latest", "true")
+
+	baseURL := baseURLV1
+	if c.apiKey != "" {
+		baseURL = baseURLV2
+		params.Set("registrationkey", c.apiKey)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+seriesID+"?"+params.Encode(), nil)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to fetch BLS data: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, 0, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var blsResp LAUSResponse
+	if err := tracing.DecodeJSON(ctx, SourceName, body, &blsResp); err != nil {
+		return 0, 0, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	if blsResp.Status != "REQUEST_SUCCEEDED" {
+		for _, msg := range blsResp.Message {
+			if strings.Contains(strings.ToLower(msg), "daily threshold") {
+				return 0, 0, c.dailyLimitReached(ctx)
+			}
+		}
+		return 0, 0, fmt.Errorf("BLS API error: %v", blsResp.Message)
+	}
+
+	for _, series := range blsResp.Results.Series {
+		for _, data := range series.Data {
+			month := parseMonth(data.Period)
+			if month < 1 || month > 12 {
+				continue // Skip annual averages
+			}
+			year, err := strconv.Atoi(data.Year)
+			if err != nil {
+				continue
+			}
+			return year, month, nil
+		}
+	}
+
+	return 0, 0, fmt.Errorf("no monthly data returned for series %s", seriesID)
+}
+
+// parseResponse converts the BLS API response to database records,
+// producing one record per county and month.
+func (c *Client) parseResponse(resp *LAUSResponse, counties []County) ([]*db.BLSEmployment, error) {
+	// Index requested counties by LAUS area code
+	countyByArea := make(map[string]County, len(counties))
+	for _, county := range counties {
+		countyByArea[BuildAreaCode(county.StateFIPS, county.CountyFIPS)] = county
+	}
+
+	// Group data by area and year/month
 	dataByPeriod := make(map[string]map[string]string)
 
 	for _, series := range resp.Results.Series {
+		areaCode := getAreaCode(series.SeriesID)
+		if _, ok := countyByArea[areaCode]; !ok {
+			continue // Not a series we asked for
+		}
+
 		measureType := getMeasureType(series.SeriesID)
 		for _, d := range series.Data {
 			// Skip annual averages (M13)
@@ -173,9 +269,10 @@ func (c *Client) parseResponse(resp *LAUSResponse, countyFIPS, countyName string
 				continue
 			}
 
-			key := d.Year + d.Period
+			key := areaCode + d.Year + d.Period
 			if dataByPeriod[key] == nil {
 				dataByPeriod[key] = make(map[string]string)
+				dataByPeriod[key]["area"] = areaCode
 				dataByPeriod[key]["year"] = d.Year
 				dataByPeriod[key]["period"] = d.Period
 			}
@@ -193,15 +290,16 @@ func (c *Client) parseResponse(resp *LAUSResponse, countyFIPS, countyName string
 	// Convert to records
 	records := make([]*db.BLSEmployment, 0, len(dataByPeriod))
 	for _, data := range dataByPeriod {
+		county := countyByArea[data["area"]]
 		year, _ := strconv.Atoi(data["year"])
 		month := parseMonth(data["period"])
 
 		record := &db.BLSEmployment{
-			AreaCode:   BuildSeriesID(countyFIPS, LAUSUnemploymentRate)[:15], // Area code portion
-			AreaName:   countyName + ", TX",
+			AreaCode:   data["area"],
+			AreaName:   county.areaName(),
 			AreaType:   ptrString("county"),
-			StateCode:  ptrString("48"),
-			CountyCode: ptrString(countyFIPS),
+			StateCode:  ptrString(county.StateFIPS),
+			CountyCode: ptrString(county.CountyFIPS),
 			Year:       year,
 			Month:      month,
 			PeriodType: "monthly",
@@ -232,6 +330,22 @@ func (c *Client) parseResponse(resp *LAUSResponse, countyFIPS, countyName string
 	return records, nil
 }
 
+// areaName returns the display name stored with each record (e.g., "Bexar, TX").
+func (c County) areaName() string {
+	if state := geography.GetState(c.StateFIPS); state != nil {
+		return c.Name + ", " + state.Code
+	}
+	return c.Name
+}
+
+// getAreaCode extracts the 15-character area code from a series ID.
+func getAreaCode(seriesID string) string {
+	if len(seriesID) != 20 {
+		return ""
+	}
+	return seriesID[3:18]
+}
+
 // getMeasureType extracts the measure type from a series ID.
 func getMeasureType(seriesID string) string {
 	if len(seriesID) < 2 {
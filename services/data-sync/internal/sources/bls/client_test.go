@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -12,24 +13,40 @@ import (
 
 func TestBuildSeriesID(t *testing.T) {
 	tests := []struct {
+		stateFIPS   string
 		countyFIPS  string
 		measureType LAUSSeriesType
 		expected    string
 	}{
-		// Format: LAUCN48 + countyFIPS + 0000000 + measureType
-		{"029", LAUSUnemploymentRate, "LAUCN48029000000003"},
-		{"029", LAUSLaborForce, "LAUCN48029000000006"},
-		{"029", LAUSEmployed, "LAUCN48029000000005"},
-		{"029", LAUSUnemployed, "LAUCN48029000000004"},
-		{"215", LAUSUnemploymentRate, "LAUCN48215000000003"},
+		// Format: LAU + CN + stateFIPS + countyFIPS + 00000000 + measureType
+		{"48", "029", LAUSUnemploymentRate, "LAUCN480290000000003"},
+		{"48", "029", LAUSLaborForce, "LAUCN480290000000006"},
+		{"48", "029", LAUSEmployed, "LAUCN480290000000005"},
+		{"48", "029", LAUSUnemployed, "LAUCN480290000000004"},
+		{"48", "215", LAUSUnemploymentRate, "LAUCN482150000000003"},
+		{"40", "109", LAUSUnemploymentRate, "LAUCN401090000000003"},
+		{"22", "071", LAUSLaborForce, "LAUCN220710000000006"},
 	}
 
 	for _, tt := range tests {
-		result := BuildSeriesID(tt.countyFIPS, tt.measureType)
+		result := BuildSeriesID(tt.stateFIPS, tt.countyFIPS, tt.measureType)
 		if result != tt.expected {
-			t.Errorf("BuildSeriesID(%q, %q) = %q, expected %q",
-				tt.countyFIPS, tt.measureType, result, tt.expected)
+			t.Errorf("BuildSeriesID(%q, %q, %q) = %q, expected %q",
+				tt.stateFIPS, tt.countyFIPS, tt.measureType, result, tt.expected)
 		}
+		if len(result) != 20 {
+			t.Errorf("BuildSeriesID(%q, %q, %q) has length %d, expected 20",
+				tt.stateFIPS, tt.countyFIPS, tt.measureType, len(result))
+		}
+	}
+}
+
+func TestBuildAreaCode(t *testing.T) {
+	if got := BuildAreaCode("48", "029"); got != "CN4802900000000" {
+		t.Errorf("BuildAreaCode(48, 029) = %q, expected CN4802900000000", got)
+	}
+	if got := BuildAreaCode("35", "001"); got != "CN3500100000000" {
+		t.Errorf("BuildAreaCode(35, 001) = %q, expected CN3500100000000", got)
 	}
 }
 
@@ -98,7 +115,7 @@ func TestClient_GetCountyEmployment(t *testing.T) {
 	}
 
 	ctx := context.Background()
-	records, err := client.GetCountyEmployment(ctx, "029", "Bexar County", 2024, 2024)
+	records, err := client.GetCountyEmployment(ctx, "48", "029", "Bexar", 2024, 2024)
 
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -151,6 +168,17 @@ func TestClient_GetCountyEmployment(t *testing.T) {
 	if dec2024.unemploymentRate == nil || *dec2024.unemploymentRate != 4.8 {
 		t.Errorf("expected unemployment rate 4.8, got %v", dec2024.unemploymentRate)
 	}
+	for _, r := range records {
+		if r.AreaCode != "CN4802900000000" {
+			t.Errorf("expected area code CN4802900000000, got %s", r.AreaCode)
+		}
+		if r.AreaName != "Bexar, TX" {
+			t.Errorf("expected area name 'Bexar, TX', got '%s'", r.AreaName)
+		}
+		if r.StateCode == nil || *r.StateCode != "48" {
+			t.Errorf("expected state code 48, got %v", r.StateCode)
+		}
+	}
 }
 
 func TestClient_GetCountyEmployment_SkipsAnnualAverages(t *testing.T) {
@@ -183,7 +211,7 @@ func TestClient_GetCountyEmployment_SkipsAnnualAverages(t *testing.T) {
 	}
 
 	ctx := context.Background()
-	records, err := client.GetCountyEmployment(ctx, "029", "Bexar County", 2024, 2024)
+	records, err := client.GetCountyEmployment(ctx, "48", "029", "Bexar", 2024, 2024)
 
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -215,7 +243,7 @@ func TestClient_GetCountyEmployment_HandlesAPIError(t *testing.T) {
 	}
 
 	ctx := context.Background()
-	_, err := client.GetCountyEmployment(ctx, "029", "Bexar County", 2024, 2024)
+	_, err := client.GetCountyEmployment(ctx, "48", "029", "Bexar", 2024, 2024)
 
 	if err == nil {
 		t.Error("expected error for failed API request, got nil")
@@ -244,7 +272,7 @@ func TestClient_GetCountyEmployment_HandlesDailyRateLimit(t *testing.T) {
 	}
 
 	ctx := context.Background()
-	_, err := client.GetCountyEmployment(ctx, "029", "Bexar County", 2024, 2024)
+	_, err := client.GetCountyEmployment(ctx, "48", "029", "Bexar", 2024, 2024)
 
 	if err == nil {
 		t.Fatal("expected error for daily rate limit, got nil")
@@ -291,7 +319,7 @@ func TestClient_GetCountyEmployment_HandlesPreliminaryData(t *testing.T) {
 	}
 
 	ctx := context.Background()
-	records, err := client.GetCountyEmployment(ctx, "029", "Bexar County", 2024, 2024)
+	records, err := client.GetCountyEmployment(ctx, "48", "029", "Bexar", 2024, 2024)
 
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -332,12 +360,137 @@ func TestClient_GetCountyEmployment_UsesV2WithAPIKey(t *testing.T) {
 	}
 
 	ctx := context.Background()
-	client.GetCountyEmployment(ctx, "029", "Bexar County", 2024, 2024)
+	client.GetCountyEmployment(ctx, "48", "029", "Bexar", 2024, 2024)
 
 	// The request body should contain registrationkey when API key is provided
 	// This is verified in the actual API call structure
 }
 
+func TestClient_GetBatchEmployment(t *testing.T) {
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			SeriesID []string `json:"seriesid"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("failed to decode request body: %v", err)
+		}
+		requested = body.SeriesID
+
+		response := LAUSResponse{
+			Status: "REQUEST_SUCCEEDED",
+			Results: LAUSResults{
+				Series: []LAUSSeries{
+					{
+						SeriesID: "LAUCN480290000000003", // Bexar unemployment rate
+						Data: []LAUSData{
+							{Year: "2024", Period: "M12", Value: "4.8"},
+							{Year: "2024", Period: "M11", Value: "4.5"},
+						},
+					},
+					{
+						SeriesID: "LAUCN480290000000006", // Bexar labor force
+						Data: []LAUSData{
+							{Year: "2024", Period: "M12", Value: "1050000"},
+						},
+					},
+					{
+						SeriesID: "LAUCN401090000000003", // Oklahoma County unemployment rate
+						Data: []LAUSData{
+							{Year: "2024", Period: "M12", Value: "3.4"},
+						},
+					},
+				},
+			},
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	client := &Client{
+		apiKey: "test-api-key",
+		httpClient: &http.Client{
+			Transport: &mockTransport{baseURL: server.URL},
+		},
+	}
+
+	counties := []County{
+		{StateFIPS: "48", CountyFIPS: "029", Name: "Bexar"},
+		{StateFIPS: "40", CountyFIPS: "109", Name: "Oklahoma"},
+	}
+
+	records, err := client.GetBatchEmployment(context.Background(), counties, 2024, 2024)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// One request carries every measure for both counties
+	if len(requested) != 8 {
+		t.Errorf("expected 8 series in request, got %d: %v", len(requested), requested)
+	}
+
+	// Bexar has M11 and M12, Oklahoma County has M12
+	if len(records) != 3 {
+		t.Fatalf("expected 3 records, got %d", len(records))
+	}
+
+	byKey := make(map[string]int)
+	for _, r := range records {
+		byKey[r.AreaCode+"/"+strconv.Itoa(r.Month)]++
+
+		switch r.AreaCode {
+		case "CN4802900000000":
+			if r.AreaName != "Bexar, TX" || *r.CountyCode != "029" {
+				t.Errorf("unexpected Bexar record: %+v", r)
+			}
+			if r.Month == 12 && (r.LaborForce == nil || *r.LaborForce != 1050000) {
+				t.Errorf("expected Bexar labor force 1050000, got %v", r.LaborForce)
+			}
+		case "CN4010900000000":
+			if r.AreaName != "Oklahoma, OK" || *r.StateCode != "40" {
+				t.Errorf("unexpected Oklahoma County record: %+v", r)
+			}
+			if r.UnemploymentRate == nil || *r.UnemploymentRate != 3.4 {
+				t.Errorf("expected Oklahoma County rate 3.4, got %v", r.UnemploymentRate)
+			}
+		default:
+			t.Errorf("unexpected area code %s", r.AreaCode)
+		}
+	}
+
+	for _, key := range []string{"CN4802900000000/12", "CN4802900000000/11", "CN4010900000000/12"} {
+		if byKey[key] != 1 {
+			t.Errorf("expected exactly one record for %s, got %d", key, byKey[key])
+		}
+	}
+}
+
+func TestClient_GetBatchEmployment_TooManyCounties(t *testing.T) {
+	client := NewClient("test-api-key")
+
+	counties := make([]County, client.CountiesPerRequest()+1)
+	for i := range counties {
+		counties[i] = County{StateFIPS: "48", CountyFIPS: "001", Name: "Anderson"}
+	}
+
+	if _, err := client.GetBatchEmployment(context.Background(), counties, 2024, 2024); err == nil {
+		t.Error("expected error for oversized batch, got nil")
+	}
+}
+
+func TestClient_CountiesPerRequest(t *testing.T) {
+	// v2 allows 50 series per request, 4 measures per county
+	if got := NewClient("test-api-key").CountiesPerRequest(); got != 12 {
+		t.Errorf("expected 12 counties per request with API key, got %d", got)
+	}
+	// v1 allows 25 series per request
+	if got := NewClient("").CountiesPerRequest(); got != 6 {
+		t.Errorf("expected 6 counties per request without API key, got %d", got)
+	}
+}
+
 func TestParseMonth(t *testing.T) {
 	tests := []struct {
 		period   string
@@ -447,3 +600,78 @@ func (t *mockTransportWithURLCapture) RoundTrip(req *http.Request) (*http.Respon
 	req.URL.Host = strings.TrimPrefix(t.baseURL, "http://")
 	return http.DefaultTransport.RoundTrip(req)
 }
+
+func TestBuildStateSeriesID(t *testing.T) {
+	if got := BuildStateSeriesID("48", LAUSUnemploymentRate); got != "LASST480000000000003" {
+		t.Errorf("BuildStateSeriesID(48, 03) = %q, expected LASST480000000000003", got)
+	}
+}
+
+func TestClient_GetLatestPeriod(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("expected GET method, got %s", r.Method)
+		}
+		if !strings.HasSuffix(r.URL.Path, "/LASST480000000000003") {
+			t.Errorf("expected series ID in path, got %s", r.URL.Path)
+		}
+		if r.URL.Query().Get("latest") != "true" {
+			t.Errorf("expected latest=true, got %q", r.URL.Query().Get("latest"))
+		}
+
+		response := LAUSResponse{
+			Status: "REQUEST_SUCCEEDED",
+			Results: LAUSResults{
+				Series: []LAUSSeries{
+					{
+						SeriesID: "LASST480000000000003",
+						Data: []LAUSData{
+							{Year: "2024", Period: "M13", Value: "4.1"}, // Annual average (should be skipped)
+							{Year: "2024", Period: "M08", Value: "4.2"},
+						},
+					},
+				},
+			},
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	client := &Client{
+		apiKey: "test-api-key",
+		httpClient: &http.Client{
+			Transport: &mockTransport{baseURL: server.URL},
+		},
+	}
+
+	year, month, err := client.GetLatestPeriod(context.Background(), "LASST480000000000003")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if year != 2024 || month != 8 {
+		t.Errorf("expected 2024-08, got %d-%02d", year, month)
+	}
+}
+
+func TestClient_GetLatestPeriod_DailyLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response := LAUSResponse{
+			Status:  "REQUEST_NOT_PROCESSED",
+			Message: []string{"daily threshold for total number of requests allocated per user has been reached."},
+		}
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	client := &Client{
+		httpClient: &http.Client{
+			Transport: &mockTransport{baseURL: server.URL},
+		},
+	}
+
+	if _, _, err := client.GetLatestPeriod(context.Background(), "LASST480000000000003"); err != ErrDailyLimitReached {
+		t.Errorf("expected ErrDailyLimitReached, got %v", err)
+	}
+}
@@ -0,0 +1,147 @@
+package bls
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dealforge/data-sync/internal/config"
+	"github.com/dealforge/data-sync/internal/db"
+	"github.com/dealforge/data-sync/internal/geography"
+	"github.com/dealforge/data-sync/internal/ratelimit"
+	"github.com/dealforge/data-sync/internal/sources"
+)
+
+// SourceName is the registry name of the BLS LAUS source.
+const SourceName = "bls"
+
+func init() {
+	sources.Register(SourceName, func(cfg *config.Config) sources.Source {
+		return &Source{
+			client: newClient(cfg.BLSAPIKey, ratelimit.FromConfig(cfg, SourceName, DefaultLimits(cfg.BLSAPIKey)), cfg.MaxRetries),
+		}
+	})
+}
+
+// Source syncs BLS LAUS employment data for every county in the selected
+// states.
+// The BLS daily request limit often stops a full run part-way through; the
+// run is then resumed from its checkpoint.
+type Source struct {
+	client *Client
+}
+
+// employmentRecords is the parsed output of a single batch fetch.
+type employmentRecords []*db.BLSEmployment
+
+// Len implements sources.Records.
+func (r employmentRecords) Len() int { return len(r) }
+
+// Vintage implements sources.Vintaged: the latest month of the records, as
+// LatestVintage reports it. Annual averages are not a month and are skipped.
+func (r employmentRecords) Vintage() string {
+	latest := ""
+	for _, rec := range r {
+		if rec.Month < 1 || rec.Month > 12 {
+			continue
+		}
+		latest = max(latest, fmt.Sprintf("%d-%02d", rec.Year, rec.Month))
+	}
+	return latest
+}
+
+// Name implements sources.Source.
+func (s *Source) Name() string { return SourceName }
+
+// RequiredConfig implements sources.Source.
+// The BLS API works without a key but has rate limits; a key is
+// recommended for production use.
+func (s *Source) RequiredConfig() []string { return nil }
+
+// WorkUnits returns the counties in the selected states grouped into
+// batches, so that each unit is a single BLS request covering as many
+// counties as the API allows (12 with an API key).
+func (s *Source) WorkUnits(ctx context.Context, p sources.Params) ([]sources.WorkUnit, error) {
+	counties, err := sources.CountyWorkUnits(p.SelectedStates())
+	if err != nil {
+		return nil, err
+	}
+	return sources.BatchWorkUnits(counties, s.client.CountiesPerRequest()), nil
+}
+
+// Fetch retrieves the LAUS series for every county in a batch with a single
+// request; the client retries transient failures. ErrDailyLimitReached is
+// returned as-is so the run stops early.
+func (s *Source) Fetch(ctx context.Context, unit sources.WorkUnit, p sources.Params) (sources.Records, error) {
+	startYear, endYear := yearRange(p)
+
+	counties := make([]County, 0, len(unit.Members))
+	for _, key := range unit.Members {
+		stateFIPS, countyFIPS, err := sources.SplitCountyKey(key)
+		if err != nil {
+			return nil, err
+		}
+
+		county := County{StateFIPS: stateFIPS, CountyFIPS: countyFIPS, Name: key}
+		if state := geography.GetState(stateFIPS); state != nil {
+			if c := state.GetCountyByFIPS(countyFIPS); c != nil {
+				county.Name = c.Name
+			}
+		}
+		counties = append(counties, county)
+	}
+
+	records, err := s.client.GetBatchEmployment(ctx, counties, startYear, endYear)
+	if err != nil {
+		return nil, err
+	}
+	return employmentRecords(records), nil
+}
+
+// Persist upserts the fetched employment records.
+func (s *Source) Persist(ctx context.Context, store *db.Client, records sources.Records) error {
+	return store.BatchUpsertBLSEmployment(ctx, records.(employmentRecords))
+}
+
+// LatestVintage implements sources.Versioned. The vintage is the latest
+// month of the first selected state's LAUS unemployment rate, which moves
+// forward with each monthly release.
+func (s *Source) LatestVintage(ctx context.Context, p sources.Params) (string, error) {
+	state := p.SelectedStates()[0]
+
+	year, month, err := s.client.GetLatestPeriod(ctx, BuildStateSeriesID(state.FIPS, LAUSUnemploymentRate))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d-%02d", year, month), nil
+}
+
+// DefaultSchedule implements sources.Scheduled. LAUS county data is released
+// (and the prior month revised) monthly, so it is checked weekly.
+func (s *Source) DefaultSchedule() string { return "0 5 * * 2" }
+
+// RelevantParams implements sources.Scoped: LAUS data is selected by state
+// and year range, with the default range resolved.
+func (s *Source) RelevantParams(p sources.Params) sources.Params {
+	startYear, endYear := yearRange(p)
+	return sources.Params{States: p.States, StartYear: startYear, EndYear: endYear}
+}
+
+// YearRange implements sources.YearRanged.
+func (s *Source) YearRange(p sources.Params) (startYear, endYear int) {
+	return yearRange(p)
+}
+
+// yearRange resolves the requested year range, defaulting to the last
+// three years ending with the current year.
+func yearRange(p sources.Params) (startYear, endYear int) {
+	endYear = p.EndYear
+	if endYear == 0 {
+		endYear = time.Now().Year()
+	}
+	startYear = p.StartYear
+	if startYear == 0 {
+		startYear = endYear - 2 // Last 3 years by default
+	}
+	return startYear, endYear
+}
@@ -0,0 +1,18 @@
+package bls
+
+import "testing"
+
+func TestEmploymentRecords_Vintage(t *testing.T) {
+	records := employmentRecords{
+		{AreaCode: "48029", Year: 2024, Month: 7},
+		{AreaCode: "48029", Year: 2024, Month: 13}, // Annual average
+		{AreaCode: "48029", Year: 2024, Month: 9},
+		{AreaCode: "48029", Year: 2023, Month: 12},
+	}
+	if got := records.Vintage(); got != "2024-09" {
+		t.Errorf("expected vintage 2024-09, got %q", got)
+	}
+	if got := (employmentRecords{}).Vintage(); got != "" {
+		t.Errorf("expected no vintage without records, got %q", got)
+	}
+}
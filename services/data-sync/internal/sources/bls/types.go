@@ -45,9 +45,38 @@ const (
 	LAUSUnemploymentRate LAUSSeriesType = "03" // Unemployment rate
 )
 
-// BuildSeriesID constructs a LAUS series ID for a Texas county.
-// Format: LAUCN480290000000003 (for unemployment rate in county 029)
-// Breakdown: LAU + CN + 48 + 029 + 0000000 + 03
-func BuildSeriesID(countyFIPS string, measureType LAUSSeriesType) string {
-	return "LAUCN48" + countyFIPS + "0000000" + string(measureType)
+// lausMeasures lists the measures requested for every county.
+var lausMeasures = []LAUSSeriesType{
+	LAUSLaborForce,
+	LAUSEmployed,
+	LAUSUnemployed,
+	LAUSUnemploymentRate,
+}
+
+// County identifies a county whose LAUS series are requested.
+type County struct {
+	StateFIPS  string // 2-digit state FIPS code
+	CountyFIPS string // 3-digit county FIPS code
+	Name       string // County name without state (e.g., "Bexar")
+}
+
+// BuildAreaCode constructs the 15-character LAUS area code for a county.
+// Format: CN4802900000000 (for Bexar County, TX)
+// Breakdown: CN + 48 + 029 + 00000000
+func BuildAreaCode(stateFIPS, countyFIPS string) string {
+	return "CN" + stateFIPS + countyFIPS + "00000000"
+}
+
+// BuildSeriesID constructs a LAUS series ID for a county.
+// Format: LAUCN480290000000003 (for unemployment rate in Bexar County, TX)
+// Breakdown: LAU + CN4802900000000 + 03
+func BuildSeriesID(stateFIPS, countyFIPS string, measureType LAUSSeriesType) string {
+	return "LAU" + BuildAreaCode(stateFIPS, countyFIPS) + string(measureType)
+}
+
+// BuildStateSeriesID constructs a statewide LAUS series ID.
+// Format: LASST480000000000003 (for the Texas unemployment rate)
+// Breakdown: LAS + ST480000000000 + 03
+func BuildStateSeriesID(stateFIPS string, measureType LAUSSeriesType) string {
+	return "LASST" + stateFIPS + "00000000000" + string(measureType)
 }
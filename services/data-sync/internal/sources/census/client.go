@@ -2,16 +2,21 @@ package census
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/dealforge/data-sync/internal/db"
+	"github.com/dealforge/data-sync/internal/httpretry"
+	"github.com/dealforge/data-sync/internal/ratelimit"
+	"github.com/dealforge/data-sync/internal/tracing"
+	"github.com/dealforge/data-sync/internal/upstream"
 )
 
 const (
@@ -22,18 +27,42 @@ const (
 type Client struct {
 	apiKey     string
 	httpClient *http.Client
+	limiter    *ratelimit.Limiter // Nil for clients built with NewClientWithHTTPClient
+
+	zctaMu      sync.Mutex
+	countyZCTAs map[string][]string // ZCTAs by county GEOID, loaded by CountyZCTAs
 }
 
-// NewClient creates a new Census API client.
+// NewClient creates a new Census API client, throttled to DefaultLimits and
+// retrying transient failures with the default policy.
 func NewClient(apiKey string) *Client {
+	return newClient(apiKey, ratelimit.New(SourceName, DefaultLimits(apiKey)), httpretry.DefaultMaxRetries)
+}
+
+// newClient creates a Census API client whose requests wait for limiter and
+// are retried up to maxRetries times.
+func newClient(apiKey string, limiter *ratelimit.Limiter, maxRetries int) *Client {
+	policy := httpretry.DefaultPolicy()
+	policy.MaxRetries = maxRetries
+	policy.AttemptTimeout = 60 * time.Second // Census API can be slow
+
 	return &Client{
-		apiKey: apiKey,
-		httpClient: &http.Client{
-			Timeout: 60 * time.Second, // Census API can be slow
-		},
+		apiKey:     apiKey,
+		limiter:    limiter,
+		httpClient: &http.Client{Transport: upstream.Transport(SourceName, limiter, policy)},
 	}
 }
 
+// DefaultLimits returns the limits of the Census API: 500 queries a day
+// without a key and no daily limit with one. The request rate is not
+// published, so it is kept modest.
+func DefaultLimits(apiKey string) ratelimit.Limits {
+	if apiKey != "" {
+		return ratelimit.Limits{RequestsPerSecond: 5}
+	}
+	return ratelimit.Limits{RequestsPerSecond: 5, DailyQuota: 500}
+}
+
 // NewClientWithHTTPClient creates a new Census API client with a custom HTTP client.
 // This is primarily for testing purposes.
 func NewClientWithHTTPClient(apiKey string, httpClient *http.Client) *Client {
@@ -43,21 +72,142 @@ func NewClientWithHTTPClient(apiKey string, httpClient *http.Client) *Client {
 	}
 }
 
-// GetCountyDemographics fetches ACS 5-year estimates for a Texas county.
-func (c *Client) GetCountyDemographics(ctx context.Context, countyFIPS string, year int) (*db.CensusDemographic, error) {
-	// Build list of variables to query
+// GetCountyDemographics fetches ACS 5-year estimates for a single county.
+func (c *Client) GetCountyDemographics(ctx context.Context, stateFIPS, countyFIPS string, year int) (*db.CensusDemographic, error) {
+	q := GeoQuery{Level: GeoCounty, StateFIPS: stateFIPS, CountyFIPS: countyFIPS}
+	acsResp, err := c.query(ctx, year, acsVariableCodes(), q)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(acsResp) < 2 {
+		return nil, fmt.Errorf("no data returned for county %s%s", stateFIPS, countyFIPS)
+	}
+
+	return c.parseResponse(acsResp, stateFIPS, countyFIPS, year)
+}
+
+// GetStateCountyDemographics fetches ACS 5-year estimates for every county in
+// a state with a single request, using the county:* wildcard.
+func (c *Client) GetStateCountyDemographics(ctx context.Context, stateFIPS string, year int) ([]*db.CensusDemographic, error) {
+	return c.GetDemographics(ctx, GeoQuery{Level: GeoCounty, StateFIPS: stateFIPS}, year)
+}
+
+// GetDemographics fetches ACS 5-year estimates for every geography matched by
+// the query (all counties of a state, all tracts of a county, or a list of
+// ZCTAs) with a single request.
+func (c *Client) GetDemographics(ctx context.Context, q GeoQuery, year int) ([]*db.CensusDemographic, error) {
+	acsResp, err := c.query(ctx, year, acsVariableCodes(), q)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(acsResp) < 2 {
+		return nil, fmt.Errorf("no data returned for %s", q)
+	}
+
+	return c.parseRows(acsResp, q.Level, year)
+}
+
+// GetStateCountyPopulation fetches only the total population of every county
+// in a state for the given ACS year, keyed by county GEOID.
+func (c *Client) GetStateCountyPopulation(ctx context.Context, stateFIPS string, year int) (map[string]int, error) {
+	return c.GetPopulation(ctx, GeoQuery{Level: GeoCounty, StateFIPS: stateFIPS}, year)
+}
+
+// GetPopulation fetches only the total population of every geography matched
+// by the query for the given ACS year, keyed by GEOID. It is used to read
+// prior vintages when computing population growth.
+func (c *Client) GetPopulation(ctx context.Context, q GeoQuery, year int) (map[string]int, error) {
+	acsResp, err := c.query(ctx, year, []string{totalPopulationVar}, q)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(acsResp) < 2 {
+		return nil, fmt.Errorf("no data returned for %s", q)
+	}
+
+	headers := acsResp[0]
+	population := make(map[string]int, len(acsResp)-1)
+	for _, data := range acsResp[1:] {
+		values := rowValues(headers, data)
+		key, err := rowKey(q.Level, values)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", err, data)
+		}
+		if pop := parseInt(values[totalPopulationVar]); pop != nil {
+			population[key.id] = *pop
+		}
+	}
+
+	return population, nil
+}
+
+// acsVariableCodes returns the codes of every variable in ACSVariables,
+// sorted so that the same query always builds the same URL (and archive
+// key).
+func acsVariableCodes() []string {
 	vars := make([]string, 0, len(ACSVariables))
 	for varCode := range ACSVariables {
 		vars = append(vars, varCode)
 	}
+	sort.Strings(vars)
+	return vars
+}
+
+// Published reports whether the ACS 5-year estimates for year have been
+// released. It requests a single national row, so it is cheap to call.
+func (c *Client) Published(ctx context.Context, year int) (bool, error) {
+	params := url.Values{}
+	params.Set("get", "NAME")
+	params.Set("for", "us:1")
+	if c.apiKey != "" {
+		params.Set("key", c.apiKey)
+	}
+
+	fullURL := fmt.Sprintf("%s/%d/acs/acs5?%s", baseURL, year, params.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fullURL, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch census vintage: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		// Unreleased vintages are unknown datasets
+		return false, nil
+	default:
+		body, _ := io.ReadAll(resp.Body)
+		return false, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
+	}
+}
+
+// query requests the given ACS variables for the geographies of q and
+// returns the raw 2D response.
+func (c *Client) query(ctx context.Context, year int, vars []string, q GeoQuery) (ACSResponse, error) {
+	forGeo, inGeo, err := q.predicates()
+	if err != nil {
+		return nil, err
+	}
 
 	// Build URL
 	apiURL := fmt.Sprintf("%s/%d/acs/acs5", baseURL, year)
 
 	params := url.Values{}
 	params.Set("get", "NAME,"+strings.Join(vars, ","))
-	params.Set("for", fmt.Sprintf("county:%s", countyFIPS))
-	params.Set("in", "state:48") // Texas FIPS code
+	params.Set("for", forGeo)
+	if inGeo != "" {
+		params.Set("in", inGeo)
+	}
 	if c.apiKey != "" {
 		params.Set("key", c.apiKey)
 	}
@@ -86,41 +236,62 @@ func (c *Client) GetCountyDemographics(ctx context.Context, countyFIPS string, y
 	}
 
 	var acsResp ACSResponse
-	if err := json.Unmarshal(body, &acsResp); err != nil {
+	if err := tracing.DecodeJSON(ctx, SourceName, body, &acsResp); err != nil {
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
-	if len(acsResp) < 2 {
-		return nil, fmt.Errorf("no data returned for county %s", countyFIPS)
-	}
+	return acsResp, nil
+}
 
-	return c.parseResponse(acsResp, countyFIPS, year)
+// parseResponse converts a single-county Census API response to a database record.
+func (c *Client) parseResponse(resp ACSResponse, stateFIPS, countyFIPS string, year int) (*db.CensusDemographic, error) {
+	key := geoKey{id: stateFIPS + countyFIPS, geoType: GeoCounty, stateFIPS: stateFIPS, countyFIPS: countyFIPS}
+	return c.parseRow(rowValues(resp[0], resp[1]), key, year), nil
 }
 
-// parseResponse converts the Census API response to a database record.
-func (c *Client) parseResponse(resp ACSResponse, countyFIPS string, year int) (*db.CensusDemographic, error) {
+// parseRows converts a multi-geography Census API response to database
+// records, one per data row. Each row identifies its geography through the
+// columns the API appends (e.g., "state" and "county").
+func (c *Client) parseRows(resp ACSResponse, level string, year int) ([]*db.CensusDemographic, error) {
 	headers := resp[0]
-	data := resp[1]
+	records := make([]*db.CensusDemographic, 0, len(resp)-1)
 
-	// Create a map for easier lookup
-	values := make(map[string]string)
+	for _, data := range resp[1:] {
+		values := rowValues(headers, data)
+		key, err := rowKey(level, values)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", err, data)
+		}
+		records = append(records, c.parseRow(values, key, year))
+	}
+
+	return records, nil
+}
+
+// rowValues maps each header to its value in a data row.
+func rowValues(headers, data []string) map[string]string {
+	values := make(map[string]string, len(headers))
 	for i, header := range headers {
 		if i < len(data) {
 			values[header] = data[i]
 		}
 	}
+	return values
+}
 
+// parseRow converts one row of ACS values to a database record.
+func (c *Client) parseRow(values map[string]string, key geoKey, year int) *db.CensusDemographic {
 	record := &db.CensusDemographic{
-		GeoID:      fmt.Sprintf("48%s", countyFIPS), // Texas FIPS + County FIPS
-		GeoType:    "county",
+		GeoID:      key.id,
+		GeoType:    key.geoType,
 		GeoName:    values["NAME"],
-		StateCode:  ptrString("48"),
-		CountyCode: ptrString(countyFIPS),
+		StateCode:  ptrString(key.stateFIPS),
+		CountyCode: ptrString(key.countyFIPS),
 		SurveyYear: year,
 	}
 
 	// Population metrics
-	record.TotalPopulation = parseInt(values["B01001_001E"])
+	record.TotalPopulation = parseInt(values[totalPopulationVar])
 	record.MedianAge = parseFloat(values["B01002_001E"])
 
 	// Income metrics
@@ -183,7 +354,7 @@ func (c *Client) parseResponse(resp ACSResponse, countyFIPS string, year int) (*
 		}
 	}
 
-	return record, nil
+	return record
 }
 
 // Helper functions
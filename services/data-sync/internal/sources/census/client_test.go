@@ -5,8 +5,11 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/dealforge/data-sync/internal/archive"
 )
 
 func TestClient_GetCountyDemographics(t *testing.T) {
@@ -60,7 +63,7 @@ func TestClient_GetCountyDemographics(t *testing.T) {
 	}
 
 	ctx := context.Background()
-	record, err := client.GetCountyDemographics(ctx, "029", 2023)
+	record, err := client.GetCountyDemographics(ctx, "48", "029", 2023)
 
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -96,6 +99,294 @@ func TestClient_GetCountyDemographics(t *testing.T) {
 	}
 }
 
+func TestClient_GetCountyDemographics_OtherState(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("for") != "county:109" {
+			t.Errorf("expected county:109, got %s", query.Get("for"))
+		}
+		if query.Get("in") != "state:40" {
+			t.Errorf("expected state:40, got %s", query.Get("in"))
+		}
+
+		response := ACSResponse{
+			{"NAME", "B01001_001E", "state", "county"},
+			{"Oklahoma County, Oklahoma", "796292", "40", "109"},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	client := &Client{
+		apiKey: "test-api-key",
+		httpClient: &http.Client{
+			Transport: &mockTransport{baseURL: server.URL},
+		},
+	}
+
+	record, err := client.GetCountyDemographics(context.Background(), "40", "109", 2023)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if record.GeoID != "40109" {
+		t.Errorf("expected GeoID '40109', got '%s'", record.GeoID)
+	}
+	if record.StateCode == nil || *record.StateCode != "40" {
+		t.Errorf("expected StateCode '40', got %v", record.StateCode)
+	}
+}
+
+func TestClient_GetStateCountyDemographics(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		query := r.URL.Query()
+		if query.Get("for") != "county:*" {
+			t.Errorf("expected county:*, got %s", query.Get("for"))
+		}
+		if query.Get("in") != "state:48" {
+			t.Errorf("expected state:48, got %s", query.Get("in"))
+		}
+		if !strings.HasSuffix(r.URL.Path, "/2023/acs/acs5") {
+			t.Errorf("expected path to end with /2023/acs/acs5, got %s", r.URL.Path)
+		}
+
+		response := ACSResponse{
+			{"NAME", "B01001_001E", "B25001_001E", "B25024_010E", "state", "county"},
+			{"Anderson County, Texas", "57922", "22000", "3300", "48", "001"},
+			{"Bexar County, Texas", "2009324", "800000", "50000", "48", "029"},
+			{"Loving County, Texas", "64", "-666666666", "null", "48", "301"},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	client := &Client{
+		apiKey: "test-api-key",
+		httpClient: &http.Client{
+			Transport: &mockTransport{baseURL: server.URL},
+		},
+	}
+
+	records, err := client.GetStateCountyDemographics(context.Background(), "48", 2023)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if requests != 1 {
+		t.Errorf("expected a single request, got %d", requests)
+	}
+	if len(records) != 3 {
+		t.Fatalf("expected 3 records, got %d", len(records))
+	}
+
+	expected := []struct {
+		geoID      string
+		name       string
+		population int
+	}{
+		{"48001", "Anderson County, Texas", 57922},
+		{"48029", "Bexar County, Texas", 2009324},
+		{"48301", "Loving County, Texas", 64},
+	}
+	for i, e := range expected {
+		r := records[i]
+		if r.GeoID != e.geoID || r.GeoName != e.name {
+			t.Errorf("record %d: expected %s %q, got %s %q", i, e.geoID, e.name, r.GeoID, r.GeoName)
+		}
+		if r.TotalPopulation == nil || *r.TotalPopulation != e.population {
+			t.Errorf("record %d: expected population %d, got %v", i, e.population, r.TotalPopulation)
+		}
+		if r.SurveyYear != 2023 || r.GeoType != "county" {
+			t.Errorf("record %d: unexpected year/type %d/%s", i, r.SurveyYear, r.GeoType)
+		}
+	}
+
+	// Bexar mobile homes: 50000 / 800000 * 100 = 6.25%
+	if p := records[1].MobileHomesPercent; p == nil || *p != 6.25 {
+		t.Errorf("expected Bexar MobileHomesPercent 6.25, got %v", p)
+	}
+
+	// Missing-data sentinels leave fields NULL
+	if records[2].TotalHousingUnits != nil || records[2].MobileHomesCount != nil {
+		t.Errorf("expected nil housing fields for Loving County, got %v / %v",
+			records[2].TotalHousingUnits, records[2].MobileHomesCount)
+	}
+}
+
+func TestClient_GetStateCountyDemographics_MissingGeoColumns(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response := ACSResponse{
+			{"NAME", "B01001_001E"},
+			{"Bexar County, Texas", "2009324"},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	client := &Client{
+		apiKey: "test-api-key",
+		httpClient: &http.Client{
+			Transport: &mockTransport{baseURL: server.URL},
+		},
+	}
+
+	if _, err := client.GetStateCountyDemographics(context.Background(), "48", 2023); err == nil {
+		t.Error("expected error for rows without state/county columns, got nil")
+	}
+}
+
+func TestClient_GetDemographics_Tracts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("for") != "tract:*" {
+			t.Errorf("expected tract:*, got %s", query.Get("for"))
+		}
+		if query.Get("in") != "state:48 county:029" {
+			t.Errorf("expected state:48 county:029, got %s", query.Get("in"))
+		}
+
+		response := ACSResponse{
+			{"NAME", "B01001_001E", "B25003_003E", "state", "county", "tract"},
+			{"Census Tract 1101; Bexar County; Texas", "3120", "900", "48", "029", "110100"},
+			{"Census Tract 1817.03; Bexar County; Texas", "6051", "2100", "48", "029", "181703"},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	client := &Client{
+		apiKey: "test-api-key",
+		httpClient: &http.Client{
+			Transport: &mockTransport{baseURL: server.URL},
+		},
+	}
+
+	q := GeoQuery{Level: GeoTract, StateFIPS: "48", CountyFIPS: "029"}
+	records, err := client.GetDemographics(context.Background(), q, 2023)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+	if records[1].GeoID != "48029181703" {
+		t.Errorf("expected GeoID '48029181703', got '%s'", records[1].GeoID)
+	}
+	if records[1].GeoType != "tract" {
+		t.Errorf("expected GeoType 'tract', got '%s'", records[1].GeoType)
+	}
+	if records[1].CountyCode == nil || *records[1].CountyCode != "029" {
+		t.Errorf("expected CountyCode '029', got %v", records[1].CountyCode)
+	}
+}
+
+func TestClient_GetDemographics_ZCTAs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("for") != "zip code tabulation area:78201,78259" {
+			t.Errorf("expected zip code tabulation area:78201,78259, got %s", query.Get("for"))
+		}
+		if query.Has("in") {
+			t.Errorf("expected no in predicate for ZCTAs, got %s", query.Get("in"))
+		}
+
+		response := ACSResponse{
+			{"NAME", "B01001_001E", "zip code tabulation area"},
+			{"ZCTA5 78201", "46210", "78201"},
+			{"ZCTA5 78259", "34101", "78259"},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	client := &Client{
+		apiKey: "test-api-key",
+		httpClient: &http.Client{
+			Transport: &mockTransport{baseURL: server.URL},
+		},
+	}
+
+	q := GeoQuery{Level: GeoZCTA, ZCTAs: []string{"78201", "78259"}}
+	records, err := client.GetDemographics(context.Background(), q, 2023)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+	if records[0].GeoID != "78201" || records[0].GeoType != "zcta" {
+		t.Errorf("expected zcta 78201, got %s %s", records[0].GeoType, records[0].GeoID)
+	}
+	if records[0].CountyCode != nil {
+		t.Errorf("expected nil CountyCode for a ZCTA, got %v", *records[0].CountyCode)
+	}
+}
+
+func TestClient_GetDemographics_InvalidQuery(t *testing.T) {
+	client := NewClient("test")
+
+	queries := []GeoQuery{
+		{Level: GeoTract, StateFIPS: "48"},
+		{Level: GeoZCTA},
+		{Level: "block"},
+	}
+	for _, q := range queries {
+		if _, err := client.GetDemographics(context.Background(), q, 2023); err == nil {
+			t.Errorf("expected error for query %+v, got nil", q)
+		}
+	}
+}
+
+func TestClient_GetStateCountyPopulation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("get") != "NAME,B01001_001E" {
+			t.Errorf("expected only NAME,B01001_001E, got %s", query.Get("get"))
+		}
+		if !strings.Contains(r.URL.Path, "/2018/") {
+			t.Errorf("expected 2018 vintage, got path %s", r.URL.Path)
+		}
+
+		response := ACSResponse{
+			{"NAME", "B01001_001E", "state", "county"},
+			{"Bexar County, Texas", "1925865", "48", "029"},
+			{"Loving County, Texas", "-666666666", "48", "301"},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	client := &Client{
+		apiKey: "test-api-key",
+		httpClient: &http.Client{
+			Transport: &mockTransport{baseURL: server.URL},
+		},
+	}
+
+	population, err := client.GetStateCountyPopulation(context.Background(), "48", 2018)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if population["48029"] != 1925865 {
+		t.Errorf("expected population 1925865 for 48029, got %d", population["48029"])
+	}
+	if _, ok := population["48301"]; ok {
+		t.Error("expected counties with missing estimates to be omitted")
+	}
+}
+
 func TestClient_GetCountyDemographics_NoAPIKey(t *testing.T) {
 	var receivedKey string
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -119,7 +410,7 @@ func TestClient_GetCountyDemographics_NoAPIKey(t *testing.T) {
 	}
 
 	ctx := context.Background()
-	client.GetCountyDemographics(ctx, "029", 2023)
+	client.GetCountyDemographics(ctx, "48", "029", 2023)
 
 	// Key should be empty when no API key provided
 	if receivedKey != "" {
@@ -142,7 +433,7 @@ func TestClient_GetCountyDemographics_APIError(t *testing.T) {
 	}
 
 	ctx := context.Background()
-	_, err := client.GetCountyDemographics(ctx, "999", 2023)
+	_, err := client.GetCountyDemographics(ctx, "48", "999", 2023)
 
 	if err == nil {
 		t.Error("expected error for 400 response, got nil")
@@ -168,7 +459,7 @@ func TestClient_GetCountyDemographics_NoData(t *testing.T) {
 	}
 
 	ctx := context.Background()
-	_, err := client.GetCountyDemographics(ctx, "029", 2023)
+	_, err := client.GetCountyDemographics(ctx, "48", "029", 2023)
 
 	if err == nil {
 		t.Error("expected error for empty data, got nil")
@@ -185,7 +476,7 @@ func TestParseResponse_CalculatesRates(t *testing.T) {
 			"200000", "160000", "20000"},
 	}
 
-	record, err := client.parseResponse(resp, "001", 2023)
+	record, err := client.parseResponse(resp, "48", "001", 2023)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -300,3 +591,72 @@ func (t *mockTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	req.URL.Host = strings.TrimPrefix(t.baseURL, "http://")
 	return http.DefaultTransport.RoundTrip(req)
 }
+
+func TestClient_Published(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("for") != "us:1" {
+			t.Errorf("expected for=us:1, got %s", r.URL.Query().Get("for"))
+		}
+		if strings.HasPrefix(r.URL.Path, "/data/2024/") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(ACSResponse{{"NAME", "us"}, {"United States", "1"}})
+	}))
+	defer server.Close()
+
+	client := &Client{
+		httpClient: &http.Client{
+			Transport: &mockTransport{baseURL: server.URL},
+		},
+	}
+
+	for year, want := range map[int]bool{2023: true, 2024: false} {
+		published, err := client.Published(context.Background(), year)
+		if err != nil {
+			t.Fatalf("year %d: unexpected error: %v", year, err)
+		}
+		if published != want {
+			t.Errorf("year %d: expected published=%v, got %v", year, want, published)
+		}
+	}
+}
+
+func TestClient_GetDemographics_SameArchiveKey(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response := ACSResponse{
+			{"NAME", "B01001_001E", "state", "county"},
+			{"Bexar County, Texas", "2009324", "48", "029"},
+		}
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	store, err := archive.NewDirStore(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	archive.Use(archive.New(store), archive.Record)
+	defer archive.Use(nil, archive.Record)
+
+	client := NewClientWithHTTPClient("test-api-key", &http.Client{
+		Transport: archive.Transport(SourceName, &mockTransport{baseURL: server.URL}),
+	})
+
+	// The same query must be archived under one key however often it is made
+	q := GeoQuery{Level: GeoCounty, StateFIPS: "48"}
+	for i := 0; i < 5; i++ {
+		if _, err := client.GetDemographics(context.Background(), q, 2023); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	entries, err := filepath.Glob(filepath.Join(dir, "requests", SourceName, "*.json"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected the repeated request to have 1 archive key, got %d", len(entries))
+	}
+}
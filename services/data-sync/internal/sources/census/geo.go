@@ -0,0 +1,104 @@
+package census
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Geography levels the client can query. They match the geo_type values
+// stored in census_demographics.
+const (
+	GeoCounty = "county"
+	GeoTract  = "tract"
+	GeoZCTA   = "zcta"
+)
+
+// zctaColumn is the geography column the Census API uses for ZCTAs.
+const zctaColumn = "zip code tabulation area"
+
+// GeoQuery selects the geographies of a single ACS request.
+type GeoQuery struct {
+	Level      string   // GeoCounty, GeoTract or GeoZCTA
+	StateFIPS  string   // Required for county and tract queries
+	CountyFIPS string   // Required for tract queries; limits county queries to one county
+	ZCTAs      []string // Required for ZCTA queries
+}
+
+// String describes the query for error messages.
+func (q GeoQuery) String() string {
+	switch q.Level {
+	case GeoTract:
+		return fmt.Sprintf("tracts in county %s%s", q.StateFIPS, q.CountyFIPS)
+	case GeoZCTA:
+		return fmt.Sprintf("ZCTAs %s", strings.Join(q.ZCTAs, ","))
+	default:
+		if q.CountyFIPS != "" {
+			return fmt.Sprintf("county %s%s", q.StateFIPS, q.CountyFIPS)
+		}
+		return fmt.Sprintf("state %s", q.StateFIPS)
+	}
+}
+
+// predicates returns the "for" and "in" parameters of the request. ZCTAs
+// are not nested in states or counties in current ACS vintages, so ZCTA
+// queries list the ZCTAs explicitly and have no "in" predicate.
+func (q GeoQuery) predicates() (forGeo, inGeo string, err error) {
+	switch q.Level {
+	case GeoCounty:
+		if q.StateFIPS == "" {
+			return "", "", fmt.Errorf("county query requires a state")
+		}
+		county := q.CountyFIPS
+		if county == "" {
+			county = "*"
+		}
+		return "county:" + county, "state:" + q.StateFIPS, nil
+	case GeoTract:
+		if q.StateFIPS == "" || q.CountyFIPS == "" {
+			return "", "", fmt.Errorf("tract query requires a state and county")
+		}
+		return "tract:*", fmt.Sprintf("state:%s county:%s", q.StateFIPS, q.CountyFIPS), nil
+	case GeoZCTA:
+		if len(q.ZCTAs) == 0 {
+			return "", "", fmt.Errorf("ZCTA query requires at least one ZCTA")
+		}
+		return zctaColumn + ":" + strings.Join(q.ZCTAs, ","), "", nil
+	default:
+		return "", "", fmt.Errorf("unknown geography level %q (expected %s, %s or %s)", q.Level, GeoCounty, GeoTract, GeoZCTA)
+	}
+}
+
+// geoKey identifies the geography a row of ACS values describes.
+type geoKey struct {
+	id         string // GEOID stored in census_demographics.geo_id
+	geoType    string
+	stateFIPS  string
+	countyFIPS string
+}
+
+// rowKey reads the geography columns the API appends to each row. GEOIDs
+// follow the Census convention: state+county for counties, state+county+tract
+// (11 digits) for tracts, and the 5-digit ZCTA for ZCTAs.
+func rowKey(level string, values map[string]string) (geoKey, error) {
+	stateFIPS, countyFIPS := values["state"], values["county"]
+
+	switch level {
+	case GeoTract:
+		tract := values["tract"]
+		if stateFIPS == "" || countyFIPS == "" || tract == "" {
+			return geoKey{}, fmt.Errorf("response row is missing state/county/tract columns")
+		}
+		return geoKey{id: stateFIPS + countyFIPS + tract, geoType: GeoTract, stateFIPS: stateFIPS, countyFIPS: countyFIPS}, nil
+	case GeoZCTA:
+		zcta := values[zctaColumn]
+		if zcta == "" {
+			return geoKey{}, fmt.Errorf("response row is missing the %s column", zctaColumn)
+		}
+		return geoKey{id: zcta, geoType: GeoZCTA, stateFIPS: stateFIPS}, nil
+	default:
+		if stateFIPS == "" || countyFIPS == "" {
+			return geoKey{}, fmt.Errorf("response row is missing state/county columns")
+		}
+		return geoKey{id: stateFIPS + countyFIPS, geoType: GeoCounty, stateFIPS: stateFIPS, countyFIPS: countyFIPS}, nil
+	}
+}
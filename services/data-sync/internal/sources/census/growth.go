@@ -0,0 +1,36 @@
+package census
+
+import (
+	"math"
+
+	"github.com/dealforge/data-sync/internal/db"
+)
+
+// ApplyPopulationGrowth sets the growth rates of records from the total
+// population of the same geographies in earlier vintages, keyed by GEOID.
+// PopulationGrowthRate is the change from the prior year's vintage and
+// PopulationGrowthRate5Yr the compound annual rate from five years earlier.
+// Geographies missing from a prior vintage keep a nil rate.
+func ApplyPopulationGrowth(records []*db.CensusDemographic, priorYear, fiveYearsPrior map[string]int) {
+	for _, r := range records {
+		if r.TotalPopulation == nil {
+			continue
+		}
+		if prior, ok := priorYear[r.GeoID]; ok {
+			r.PopulationGrowthRate = growthRate(*r.TotalPopulation, prior, 1)
+		}
+		if prior, ok := fiveYearsPrior[r.GeoID]; ok {
+			r.PopulationGrowthRate5Yr = growthRate(*r.TotalPopulation, prior, 5)
+		}
+	}
+}
+
+// growthRate returns the compound annual growth rate, as a percentage, from
+// prior to current over the given number of years.
+func growthRate(current, prior, years int) *float64 {
+	if prior <= 0 || current < 0 || years <= 0 {
+		return nil
+	}
+	rate := (math.Pow(float64(current)/float64(prior), 1/float64(years)) - 1) * 100
+	return &rate
+}
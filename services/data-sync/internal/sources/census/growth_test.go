@@ -0,0 +1,50 @@
+package census
+
+import (
+	"math"
+	"testing"
+
+	"github.com/dealforge/data-sync/internal/db"
+)
+
+func TestApplyPopulationGrowth(t *testing.T) {
+	records := []*db.CensusDemographic{
+		{GeoID: "48029", TotalPopulation: intPtr(2009324)},
+		{GeoID: "48301", TotalPopulation: intPtr(64)},
+		{GeoID: "48999"},
+	}
+	priorYear := map[string]int{"48029": 1986049, "48301": 80}
+	fiveYearsPrior := map[string]int{"48029": 1925865}
+
+	ApplyPopulationGrowth(records, priorYear, fiveYearsPrior)
+
+	// YoY: 2009324 / 1986049 - 1 ≈ 1.17%
+	bexar := records[0]
+	if bexar.PopulationGrowthRate == nil || math.Abs(*bexar.PopulationGrowthRate-1.172) > 0.01 {
+		t.Errorf("expected PopulationGrowthRate ~1.17%%, got %v", bexar.PopulationGrowthRate)
+	}
+	// CAGR: (2009324 / 1925865)^(1/5) - 1 ≈ 0.85%
+	if bexar.PopulationGrowthRate5Yr == nil || math.Abs(*bexar.PopulationGrowthRate5Yr-0.852) > 0.01 {
+		t.Errorf("expected PopulationGrowthRate5Yr ~0.85%%, got %v", bexar.PopulationGrowthRate5Yr)
+	}
+
+	// Declining population, no five-year-prior vintage
+	loving := records[1]
+	if loving.PopulationGrowthRate == nil || math.Abs(*loving.PopulationGrowthRate+20) > 0.01 {
+		t.Errorf("expected PopulationGrowthRate -20%%, got %v", loving.PopulationGrowthRate)
+	}
+	if loving.PopulationGrowthRate5Yr != nil {
+		t.Errorf("expected nil PopulationGrowthRate5Yr, got %v", *loving.PopulationGrowthRate5Yr)
+	}
+
+	// No current population
+	if records[2].PopulationGrowthRate != nil || records[2].PopulationGrowthRate5Yr != nil {
+		t.Error("expected nil growth rates for a record without population")
+	}
+}
+
+func TestGrowthRate_ZeroPrior(t *testing.T) {
+	if rate := growthRate(100, 0, 1); rate != nil {
+		t.Errorf("expected nil rate for zero prior population, got %v", *rate)
+	}
+}
@@ -0,0 +1,230 @@
+package census
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/dealforge/data-sync/internal/config"
+	"github.com/dealforge/data-sync/internal/db"
+	"github.com/dealforge/data-sync/internal/ratelimit"
+	"github.com/dealforge/data-sync/internal/sources"
+)
+
+// SourceName is the registry name of the Census ACS source.
+const SourceName = "census"
+
+// firstACS5Year is the first year the ACS 5-year estimates were published.
+const firstACS5Year = 2009
+
+func init() {
+	sources.Register(SourceName, func(cfg *config.Config) sources.Source {
+		return &Source{client: newClient(cfg.CensusAPIKey, ratelimit.FromConfig(cfg, SourceName, DefaultLimits(cfg.CensusAPIKey)), cfg.MaxRetries)}
+	})
+}
+
+// zctasPerRequest caps how many ZCTAs are listed in a single request, to
+// keep request URLs well within the API's limits.
+const zctasPerRequest = 50
+
+// Source syncs Census ACS 5-year demographics for every county in the
+// selected states, or for the tracts or ZCTAs of selected counties.
+type Source struct {
+	client *Client
+}
+
+// demographicRecords is the parsed output of a single unit fetch.
+type demographicRecords []*db.CensusDemographic
+
+// Len implements sources.Records.
+func (r demographicRecords) Len() int { return len(r) }
+
+// Vintage implements sources.Vintaged: the survey year of the records, as
+// LatestVintage reports it.
+func (r demographicRecords) Vintage() string {
+	year := 0
+	for _, rec := range r {
+		year = max(year, rec.SurveyYear)
+	}
+	if year == 0 {
+		return ""
+	}
+	return fmt.Sprintf("ACS5-%d", year)
+}
+
+// Name implements sources.Source.
+func (s *Source) Name() string { return SourceName }
+
+// RequiredConfig implements sources.Source.
+// The Census API works without a key but has rate limits; a key is
+// recommended for production use.
+func (s *Source) RequiredConfig() []string { return nil }
+
+// RelevantParams implements sources.Scoped. The geography level and survey
+// year are resolved to their defaults, so a session never mixes survey
+// years when a new ACS release lands before it is resumed. Only the
+// selection the level reads is kept: states for counties and the county
+// list for tracts and ZCTAs.
+func (s *Source) RelevantParams(p sources.Params) sources.Params {
+	scoped := sources.Params{GeoLevel: geoLevel(p), Year: surveyYear(p)}
+	switch scoped.GeoLevel {
+	case GeoTract, GeoZCTA:
+		scoped.Counties = p.Counties
+	default:
+		scoped.States = p.States
+	}
+	return scoped
+}
+
+// WorkUnits returns the units for the requested geography level:
+//   - county (default): one unit per state, fetching every county of the
+//     state in a single request, so no county table is needed.
+//   - tract: one unit per requested county, fetching all of its tracts.
+//   - zcta: one unit per requested county, fetching every ZCTA overlapping
+//     it. ZCTAs are not nested in counties in the ACS, so a county's ZCTAs
+//     are looked up in the ZCTA to county relationship file when fetching.
+//
+// Sub-county levels require an explicit county list so a run stays within
+// the API limits.
+func (s *Source) WorkUnits(ctx context.Context, p sources.Params) ([]sources.WorkUnit, error) {
+	switch level := geoLevel(p); level {
+	case GeoCounty:
+		states := p.SelectedStates()
+		units := make([]sources.WorkUnit, 0, len(states))
+		for _, state := range states {
+			units = append(units, sources.WorkUnit{Key: state.FIPS, Name: state.Name})
+		}
+		return units, nil
+
+	case GeoTract, GeoZCTA:
+		if len(p.Counties) == 0 {
+			return nil, fmt.Errorf("census %s geography requires a county list (--census-counties)", level)
+		}
+		return sources.CountyListWorkUnits(p.Counties)
+
+	default:
+		return nil, fmt.Errorf("unknown census geography %q (expected %s, %s or %s)", level, GeoCounty, GeoTract, GeoZCTA)
+	}
+}
+
+// Fetch retrieves the ACS estimates for every geography in the unit, along
+// with the population of the year-1 and year-5 vintages so growth rates can
+// be computed.
+func (s *Source) Fetch(ctx context.Context, unit sources.WorkUnit, p sources.Params) (sources.Records, error) {
+	queries, err := s.unitQueries(ctx, unit, geoLevel(p))
+	if err != nil {
+		return nil, err
+	}
+	year := surveyYear(p)
+
+	var records demographicRecords
+	for _, q := range queries {
+		fetched, err := s.client.GetDemographics(ctx, q, year)
+		if err != nil {
+			return nil, err
+		}
+
+		priorYear := s.priorPopulation(ctx, unit, q, year-1)
+		fiveYearsPrior := s.priorPopulation(ctx, unit, q, year-5)
+		ApplyPopulationGrowth(fetched, priorYear, fiveYearsPrior)
+
+		records = append(records, fetched...)
+	}
+
+	return records, nil
+}
+
+// Persist upserts the fetched demographic records in a single batch.
+func (s *Source) Persist(ctx context.Context, store *db.Client, records sources.Records) error {
+	return store.BatchUpsertCensusDemographic(ctx, records.(demographicRecords))
+}
+
+// LatestVintage implements sources.Versioned. The vintage is the survey
+// year a run would sync; ErrVintageUnavailable is returned until the Census
+// Bureau publishes it (usually in December of the following year).
+func (s *Source) LatestVintage(ctx context.Context, p sources.Params) (string, error) {
+	year := surveyYear(p)
+
+	published, err := s.client.Published(ctx, year)
+	if err != nil {
+		return "", err
+	}
+	if !published {
+		return "", fmt.Errorf("ACS 5-year %d: %w", year, sources.ErrVintageUnavailable)
+	}
+	return fmt.Sprintf("ACS5-%d", year), nil
+}
+
+// DefaultSchedule implements sources.Scheduled. ACS estimates are released
+// yearly, so a weekly check only syncs once per release.
+func (s *Source) DefaultSchedule() string { return "0 4 * * 1" }
+
+// priorPopulation returns the populations of an earlier vintage.
+// Growth rates are supplementary, so a vintage that cannot be fetched (or
+// predates the ACS 5-year series) only leaves the rates empty. Geographies
+// redrawn since that vintage, such as tracts after a decennial census, are
+// simply missing from it.
+func (s *Source) priorPopulation(ctx context.Context, unit sources.WorkUnit, q GeoQuery, year int) map[string]int {
+	if year < firstACS5Year {
+		return nil
+	}
+
+	population, err := s.client.GetPopulation(ctx, q, year)
+	if err != nil {
+		slog.Warn("failed to fetch prior census vintage, skipping population growth",
+			"unit", unit.Name,
+			"year", year,
+			"error", err,
+		)
+		return nil
+	}
+	return population
+}
+
+// unitQueries builds the ACS queries for a work unit of the given level. A
+// county's ZCTAs are listed in batches of zctasPerRequest; a county without
+// any has no queries.
+func (s *Source) unitQueries(ctx context.Context, unit sources.WorkUnit, level string) ([]GeoQuery, error) {
+	switch level {
+	case GeoTract:
+		stateFIPS, countyFIPS, err := sources.SplitCountyKey(unit.Key)
+		if err != nil {
+			return nil, err
+		}
+		return []GeoQuery{{Level: GeoTract, StateFIPS: stateFIPS, CountyFIPS: countyFIPS}}, nil
+	case GeoZCTA:
+		if _, _, err := sources.SplitCountyKey(unit.Key); err != nil {
+			return nil, err
+		}
+		zctas, err := s.client.CountyZCTAs(ctx, unit.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list ZCTAs of county %s: %w", unit.Key, err)
+		}
+
+		var queries []GeoQuery
+		for start := 0; start < len(zctas); start += zctasPerRequest {
+			end := min(start+zctasPerRequest, len(zctas))
+			queries = append(queries, GeoQuery{Level: GeoZCTA, ZCTAs: zctas[start:end]})
+		}
+		return queries, nil
+	default:
+		return []GeoQuery{{Level: GeoCounty, StateFIPS: unit.Key}}, nil
+	}
+}
+
+// geoLevel returns the requested geography level, defaulting to counties.
+func geoLevel(p sources.Params) string {
+	if p.GeoLevel != "" {
+		return p.GeoLevel
+	}
+	return GeoCounty
+}
+
+// surveyYear returns the requested ACS year, defaulting to the previous year.
+func surveyYear(p sources.Params) int {
+	if p.Year != 0 {
+		return p.Year
+	}
+	return time.Now().Year() - 1 // Use previous year's data
+}
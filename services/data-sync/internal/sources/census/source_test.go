@@ -0,0 +1,196 @@
+package census
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dealforge/data-sync/internal/archive"
+	"github.com/dealforge/data-sync/internal/geography"
+	"github.com/dealforge/data-sync/internal/sources"
+)
+
+func TestSource_WorkUnits_Levels(t *testing.T) {
+	src := &Source{client: NewClient("test")}
+	ctx := context.Background()
+
+	units, err := src.WorkUnits(ctx, sources.Params{States: []geography.State{*geography.GetState("OK")}})
+	if err != nil {
+		t.Fatalf("county: unexpected error: %v", err)
+	}
+	if len(units) != 1 || units[0].Key != "40" {
+		t.Errorf("county: expected a single unit for state 40, got %v", units)
+	}
+
+	units, err = src.WorkUnits(ctx, sources.Params{GeoLevel: GeoTract, Counties: []string{"48029", "40109"}})
+	if err != nil {
+		t.Fatalf("tract: unexpected error: %v", err)
+	}
+	if len(units) != 2 || units[0].Key != "48029" || units[0].Name != "Bexar, TX" {
+		t.Errorf("tract: expected units for 48029 and 40109, got %v", units)
+	}
+
+	// ZCTAs are selected by county too, and planning needs no relationship file
+	units, err = src.WorkUnits(ctx, sources.Params{GeoLevel: GeoZCTA, Counties: []string{"48029"}, ZIPs: []string{"78201"}})
+	if err != nil {
+		t.Fatalf("zcta: unexpected error: %v", err)
+	}
+	if len(units) != 1 || units[0].Key != "48029" {
+		t.Errorf("zcta: expected a single unit for county 48029, got %v", units)
+	}
+}
+
+func TestSource_Fetch_CountyZCTAs(t *testing.T) {
+	zctas := make([]string, 0, zctasPerRequest+2)
+	for i := 0; i < zctasPerRequest+2; i++ {
+		zctas = append(zctas, fmt.Sprintf("78%03d", i))
+	}
+	relationship := "\ufeffOID_ZCTA5_20|GEOID_ZCTA5_20|NAMELSAD_ZCTA5_20|OID_COUNTY_20|GEOID_COUNTY_20|NAMELSAD_COUNTY_20\n"
+	for _, zcta := range zctas {
+		relationship += fmt.Sprintf("1|%s|ZCTA5 %s|2|48029|Bexar County\n", zcta, zcta)
+	}
+	relationship += "1|78006|ZCTA5 78006|3|48259|Kendall County\n"
+	relationship += "|||2|48029|Bexar County\n" // Part of Bexar outside any ZCTA
+
+	downloads := 0
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, ".txt") {
+			downloads++
+			io.WriteString(w, relationship)
+			return
+		}
+
+		list := strings.TrimPrefix(r.URL.Query().Get("for"), zctaColumn+":")
+		if strings.HasPrefix(r.URL.Path, "/data/2023/") {
+			requested = append(requested, list)
+		}
+		response := ACSResponse{{"NAME", "B01001_001E", zctaColumn}}
+		for _, zcta := range strings.Split(list, ",") {
+			response = append(response, []string{"ZCTA5 " + zcta, "1000", zcta})
+		}
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	src := &Source{client: NewClientWithHTTPClient("", &http.Client{Transport: &mockTransport{baseURL: server.URL}})}
+	params := sources.Params{GeoLevel: GeoZCTA, Year: 2023}
+
+	records, err := src.Fetch(context.Background(), sources.WorkUnit{Key: "48029"}, params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if records.Len() != len(zctas) {
+		t.Errorf("expected %d records, got %d", len(zctas), records.Len())
+	}
+	if len(requested) != 2 || len(strings.Split(requested[0], ",")) != zctasPerRequest {
+		t.Errorf("expected the ZCTAs in 2 requests of at most %d, got %v", zctasPerRequest, requested)
+	}
+
+	records, err = src.Fetch(context.Background(), sources.WorkUnit{Key: "48259"}, params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fetched := records.(demographicRecords); len(fetched) != 1 || fetched[0].GeoID != "78006" {
+		t.Errorf("expected ZCTA 78006 for county 48259, got %v", fetched)
+	}
+
+	// A county with no ZCTAs fetches nothing
+	records, err = src.Fetch(context.Background(), sources.WorkUnit{Key: "48301"}, params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if records.Len() != 0 {
+		t.Errorf("expected no records for a county without ZCTAs, got %d", records.Len())
+	}
+
+	if downloads != 1 {
+		t.Errorf("expected the relationship file to be downloaded once, got %d", downloads)
+	}
+}
+
+func TestSource_WorkUnits_RequiresRestriction(t *testing.T) {
+	src := &Source{client: NewClient("test")}
+
+	for _, p := range []sources.Params{
+		{GeoLevel: GeoTract},
+		{GeoLevel: GeoZCTA},
+		{GeoLevel: "block"},
+	} {
+		if _, err := src.WorkUnits(context.Background(), p); err == nil {
+			t.Errorf("expected error for %+v, got nil", p)
+		}
+	}
+}
+
+func TestSource_Fetch_ReplaysRecordedFixtures(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Echo every requested variable for two counties
+		headers := append(strings.Split(r.URL.Query().Get("get"), ","), "state", "county")
+		response := ACSResponse{headers}
+		for _, county := range []string{"029", "091"} {
+			row := make([]string, len(headers))
+			for i := range row {
+				row[i] = fmt.Sprintf("%d", 1000+i)
+			}
+			row[0] = "County " + county
+			row[len(row)-2], row[len(row)-1] = "48", county
+			response = append(response, row)
+		}
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	store, err := archive.NewDirStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fixtures := archive.New(store)
+	defer archive.Use(nil, archive.Record)
+
+	unit := sources.WorkUnit{Key: "48", Name: "Texas"}
+	params := sources.Params{Year: 2023}
+
+	// Record against the fake API
+	src := &Source{client: NewClientWithHTTPClient("test-api-key", &http.Client{
+		Transport: archive.Transport(SourceName, &mockTransport{baseURL: server.URL}),
+	})}
+	archive.Use(fixtures, archive.RecordAll)
+	recorded, err := src.Fetch(context.Background(), unit, params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Replay with no network and no API key, with every request, including
+	// the prior vintages, served from the archive
+	offline := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		t.Errorf("unexpected request to %s", r.URL)
+		return nil, errors.New("network access during replay")
+	})
+	src = &Source{client: NewClientWithHTTPClient("", &http.Client{Transport: archive.Transport(SourceName, offline)})}
+	archive.Use(fixtures, archive.Offline)
+	replayed, err := src.Fetch(context.Background(), unit, params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if recorded.Len() != 2 || !reflect.DeepEqual(recorded, replayed) {
+		t.Errorf("expected the replay to parse the recorded records, got %+v and %+v", recorded, replayed)
+	}
+	for _, r := range replayed.(demographicRecords) {
+		if r.PopulationGrowthRate == nil {
+			t.Errorf("expected %s to have a growth rate from the replayed prior vintage", r.GeoID)
+		}
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
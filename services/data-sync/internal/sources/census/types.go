@@ -1,6 +1,10 @@
 // Package census provides a client for the Census Bureau ACS API.
 package census
 
+// totalPopulationVar is the ACS variable for total population, also read
+// from prior vintages to compute population growth.
+const totalPopulationVar = "B01001_001E"
+
 // ACSVariables defines the Census ACS variables we query.
 // These are from the ACS 5-Year Estimates (acs/acs5).
 var ACSVariables = map[string]string{
@@ -0,0 +1,108 @@
+package census
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// zctaCountyURL is the Census Bureau's 2020 ZCTA to county relationship
+// file, listing every pair of a ZCTA and a county it overlaps.
+const zctaCountyURL = "https://www2.census.gov/geo/docs/maps-data/data/rel2020/zcta520/tab20_zcta520_county20_natl.txt"
+
+// Columns of the relationship file read by parseZCTACounties.
+const (
+	relZCTAColumn   = "GEOID_ZCTA5_20"
+	relCountyColumn = "GEOID_COUNTY_20"
+)
+
+// CountyZCTAs returns the ZCTAs that overlap a county, given its five-digit
+// GEOID. A ZCTA crossing a county line is listed for each county. The
+// relationship file is downloaded on first use and kept for the life of the
+// client.
+func (c *Client) CountyZCTAs(ctx context.Context, countyGEOID string) ([]string, error) {
+	c.zctaMu.Lock()
+	defer c.zctaMu.Unlock()
+
+	if c.countyZCTAs == nil {
+		countyZCTAs, err := c.fetchZCTACounties(ctx)
+		if err != nil {
+			return nil, err
+		}
+		c.countyZCTAs = countyZCTAs
+	}
+	return c.countyZCTAs[countyGEOID], nil
+}
+
+// fetchZCTACounties downloads and parses the ZCTA to county relationship file.
+func (c *Client) fetchZCTACounties(ctx context.Context) (map[string][]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, zctaCountyURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch ZCTA relationship file: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("relationship file returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	countyZCTAs, err := parseZCTACounties(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ZCTA relationship file: %w", err)
+	}
+	return countyZCTAs, nil
+}
+
+// parseZCTACounties reads a pipe-delimited relationship file into the ZCTAs
+// of each county, in file order. Rows for the parts of a county outside any
+// ZCTA have no ZCTA and are skipped.
+func parseZCTACounties(r io.Reader) (map[string][]string, error) {
+	scanner := bufio.NewScanner(r)
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("empty file")
+	}
+
+	// The file starts with a byte order mark
+	header := strings.Split(strings.TrimPrefix(scanner.Text(), "\ufeff"), "|")
+	zctaCol, countyCol := -1, -1
+	for i, name := range header {
+		switch name {
+		case relZCTAColumn:
+			zctaCol = i
+		case relCountyColumn:
+			countyCol = i
+		}
+	}
+	if zctaCol < 0 || countyCol < 0 {
+		return nil, fmt.Errorf("header is missing the %s or %s column", relZCTAColumn, relCountyColumn)
+	}
+
+	countyZCTAs := make(map[string][]string)
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), "|")
+		if len(fields) <= max(zctaCol, countyCol) {
+			continue
+		}
+		zcta, county := fields[zctaCol], fields[countyCol]
+		if zcta == "" || county == "" {
+			continue
+		}
+		countyZCTAs[county] = append(countyZCTAs[county], zcta)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return countyZCTAs, nil
+}
@@ -2,36 +2,61 @@ package hud
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"sort"
 	"strconv"
 	"time"
 
 	"github.com/dealforge/data-sync/internal/db"
+	"github.com/dealforge/data-sync/internal/httpretry"
+	"github.com/dealforge/data-sync/internal/ratelimit"
+	"github.com/dealforge/data-sync/internal/tracing"
+	"github.com/dealforge/data-sync/internal/upstream"
 )
 
 const (
-	baseURL = "https://www.huduser.gov/hudapi/public/fmr"
+	baseURL      = "https://www.huduser.gov/hudapi/public/fmr"
+	crosswalkURL = "https://www.huduser.gov/hudapi/public/usps"
 )
 
+// crosswalkZIPCounty selects the ZIP-to-county USPS crosswalk.
+const crosswalkZIPCounty = 2
+
 // Client provides access to the HUD FMR API.
 type Client struct {
 	apiKey     string
 	httpClient *http.Client
+	limiter    *ratelimit.Limiter // Nil for clients built with NewClientWithHTTPClient
 }
 
-// NewClient creates a new HUD API client.
+// NewClient creates a new HUD API client, throttled to DefaultLimits and
+// retrying transient failures with the default policy.
 func NewClient(apiKey string) *Client {
+	return newClient(apiKey, ratelimit.New(SourceName, DefaultLimits(apiKey)), httpretry.DefaultMaxRetries)
+}
+
+// newClient creates a HUD API client whose requests wait for limiter and
+// are retried up to maxRetries times.
+func newClient(apiKey string, limiter *ratelimit.Limiter, maxRetries int) *Client {
+	policy := httpretry.DefaultPolicy()
+	policy.MaxRetries = maxRetries
+	policy.AttemptTimeout = 30 * time.Second
+
 	return &Client{
-		apiKey: apiKey,
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		apiKey:     apiKey,
+		limiter:    limiter,
+		httpClient: &http.Client{Transport: upstream.Transport(SourceName, limiter, policy)},
 	}
 }
 
+// DefaultLimits returns the published limit of the HUD User API, 60
+// queries a minute.
+func DefaultLimits(apiKey string) ratelimit.Limits {
+	return ratelimit.Limits{RequestsPerSecond: 1}
+}
+
 // NewClientWithHTTPClient creates a new HUD API client with a custom HTTP client.
 // This is primarily for testing purposes.
 func NewClientWithHTTPClient(apiKey string, httpClient *http.Client) *Client {
@@ -70,7 +95,7 @@ func (c *Client) GetStateData(ctx context.Context, stateCode string) (*StateData
 	}
 
 	var stateResp StateDataResponse
-	if err := json.Unmarshal(body, &stateResp); err != nil {
+	if err := tracing.DecodeJSON(ctx, SourceName, body, &stateResp); err != nil {
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
@@ -107,22 +132,79 @@ func (c *Client) GetEntityData(ctx context.Context, entityCode string) (*EntityD
 	}
 
 	var entityResp EntityDataResponse
-	if err := json.Unmarshal(body, &entityResp); err != nil {
+	if err := tracing.DecodeJSON(ctx, SourceName, body, &entityResp); err != nil {
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
 	return &entityResp, nil
 }
 
+// GetZIPCounties returns the GEOIDs of the counties a ZIP code lies in,
+// from the HUD USPS ZIP crosswalk, largest share of residences first.
+func (c *Client) GetZIPCounties(ctx context.Context, zip string) ([]string, error) {
+	url := fmt.Sprintf("%s?type=%d&query=%s", crosswalkURL, crosswalkZIPCounty, zip)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.apiKey))
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch ZIP crosswalk: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	var crosswalk CrosswalkResponse
+	if err := tracing.DecodeJSON(ctx, SourceName, body, &crosswalk); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	results := crosswalk.Data.Results
+	sort.SliceStable(results, func(i, j int) bool { return results[i].ResRatio > results[j].ResRatio })
+
+	counties := make([]string, 0, len(results))
+	for _, r := range results {
+		if r.GeoID != "" {
+			counties = append(counties, r.GeoID)
+		}
+	}
+	return counties, nil
+}
+
+// CountyEntityCode returns the FMR entity code of a county: its five-digit
+// GEOID followed by 99999 (e.g., "4802999999" for Bexar County, TX).
+func CountyEntityCode(geoid string) string {
+	return geoid + "99999"
+}
+
 // GetFMRRecordsForState fetches all FMR data for a state and converts to DB records.
-// This includes metro area and county-level data. For areas with Small Area FMRs,
-// it also fetches ZIP-level data.
+// This includes metro area and county-level data only; ZIP-level data for areas with
+// Small Area FMRs is fetched per metro with GetZIPLevelFMR.
 func (c *Client) GetFMRRecordsForState(ctx context.Context, stateCode string) ([]*db.HUDFairMarketRent, error) {
 	stateData, err := c.GetStateData(ctx, stateCode)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get state data: %w", err)
 	}
+	return stateRecords(stateCode, stateData), nil
+}
 
+// stateRecords converts the metro areas and non-metro counties of a state
+// data response to DB records.
+func stateRecords(stateCode string, stateData *StateDataResponse) []*db.HUDFairMarketRent {
 	var records []*db.HUDFairMarketRent
 	fiscalYear, _ := strconv.Atoi(stateData.Data.Year)
 
@@ -162,10 +244,13 @@ func (c *Client) GetFMRRecordsForState(ctx context.Context, stateCode string) ([
 		records = append(records, record)
 	}
 
-	return records, nil
+	return records
 }
 
 // GetZIPLevelFMR fetches ZIP-level FMR data for an entity with Small Area FMRs.
+// The entity may be a metro or one of its counties; either way HUD returns
+// every ZIP of the metro's Small Area FMRs, and the records are keyed by the
+// FMR area HUD reports, so a ZIP is the same row whichever entity fetched it.
 func (c *Client) GetZIPLevelFMR(ctx context.Context, entityCode string) ([]*db.HUDFairMarketRent, error) {
 	entityData, err := c.GetEntityData(ctx, entityCode)
 	if err != nil {
@@ -178,12 +263,16 @@ func (c *Client) GetZIPLevelFMR(ctx context.Context, entityCode string) ([]*db.H
 	}
 
 	fiscalYear, _ := strconv.Atoi(entityData.Data.Year)
+	area := entityCode
+	if entityData.Data.EntityID != "" {
+		area = entityData.Data.EntityID
+	}
 	var records []*db.HUDFairMarketRent
 
 	for _, sa := range entityData.Data.SmallAreas {
 		record := &db.HUDFairMarketRent{
 			ZipCode:         sa.ZipCode,
-			EntityCode:      ptrString(entityCode),
+			EntityCode:      ptrString(area),
 			FiscalYear:      fiscalYear,
 			MetroName:       ptrString(entityData.Data.MetroName),
 			CountyName:      ptrString(entityData.Data.CountyName),
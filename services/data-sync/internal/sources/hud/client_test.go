@@ -242,6 +242,93 @@ func TestClient_GetFMRRecordsForState(t *testing.T) {
 	}
 }
 
+func TestClient_GetZIPLevelFMR(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/data/METRO41700M41700") {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+
+		response := EntityDataResponse{
+			Data: EntityData{
+				Year:            "2025",
+				MetroName:       "San Antonio-New Braunfels",
+				StateName:       "Texas",
+				StateCode:       "TX",
+				SmallAreaStatus: "1",
+				SmallAreas: []SmallAreaData{
+					{ZipCode: "78201", TwoBedroom: 1250},
+					{ZipCode: "78259", TwoBedroom: 1710},
+				},
+			},
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	client := &Client{
+		apiKey: "test-api-key",
+		httpClient: &http.Client{
+			Transport: &mockTransport{baseURL: server.URL},
+		},
+	}
+
+	records, err := client.GetZIPLevelFMR(context.Background(), "METRO41700M41700")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+
+	// Each ZIP gets its own row under the metro's entity code
+	for i, zip := range []string{"78201", "78259"} {
+		r := records[i]
+		if r.ZipCode != zip {
+			t.Errorf("expected ZIP %s, got %s", zip, r.ZipCode)
+		}
+		if r.EntityCode == nil || *r.EntityCode != "METRO41700M41700" {
+			t.Errorf("expected entity code METRO41700M41700, got %v", r.EntityCode)
+		}
+		if r.SmallAreaStatus == nil || *r.SmallAreaStatus != "1" {
+			t.Errorf("expected small area status 1, got %v", r.SmallAreaStatus)
+		}
+	}
+	if records[1].TwoBedroom == nil || *records[1].TwoBedroom != 1710 {
+		t.Errorf("expected two bedroom 1710, got %v", records[1].TwoBedroom)
+	}
+}
+
+func TestClient_GetZIPLevelFMR_NoSmallAreas(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response := EntityDataResponse{
+			Data: EntityData{Year: "2025", SmallAreaStatus: "0"},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	client := &Client{
+		apiKey: "test-api-key",
+		httpClient: &http.Client{
+			Transport: &mockTransport{baseURL: server.URL},
+		},
+	}
+
+	records, err := client.GetZIPLevelFMR(context.Background(), "METRO12420M12420")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(records) != 0 {
+		t.Errorf("expected no records for a metro without Small Area FMRs, got %d", len(records))
+	}
+}
+
 func TestClient_GetStateData_APIError(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusInternalServerError)
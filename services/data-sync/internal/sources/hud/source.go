@@ -0,0 +1,205 @@
+package hud
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/dealforge/data-sync/internal/config"
+	"github.com/dealforge/data-sync/internal/db"
+	"github.com/dealforge/data-sync/internal/ratelimit"
+	"github.com/dealforge/data-sync/internal/sources"
+)
+
+// SourceName is the registry name of the HUD FMR source.
+const SourceName = "hud"
+
+// metroCodePrefix identifies metro entity codes (e.g., "METRO10180M10180"),
+// which are the only entities HUD publishes Small Area FMRs for.
+const metroCodePrefix = "METRO"
+
+// zipsPerUnit caps how many requested ZIPs a work unit covers. Each ZIP
+// costs a crosswalk request, so this keeps units (and resumes) small.
+const zipsPerUnit = 25
+
+func init() {
+	sources.Register(SourceName, func(cfg *config.Config) sources.Source {
+		return &Source{client: newClient(cfg.HUDAPIKey, ratelimit.FromConfig(cfg, SourceName, DefaultLimits(cfg.HUDAPIKey)), cfg.MaxRetries)}
+	})
+}
+
+// Source syncs HUD Fair Market Rents for the selected states: entity-level
+// rents for every metro and non-metro county, plus ZIP-level Small Area FMRs
+// for metros that publish them.
+type Source struct {
+	client *Client
+}
+
+// fmrRecords is the parsed output of a single state or metro fetch.
+type fmrRecords []*db.HUDFairMarketRent
+
+// Len implements sources.Records.
+func (r fmrRecords) Len() int { return len(r) }
+
+// Vintage implements sources.Vintaged: the latest fiscal year of the records,
+// as LatestVintage reports it.
+func (r fmrRecords) Vintage() string {
+	year := 0
+	for _, rec := range r {
+		year = max(year, rec.FiscalYear)
+	}
+	if year == 0 {
+		return ""
+	}
+	return fmt.Sprintf("FY%d", year)
+}
+
+// Name implements sources.Source.
+func (s *Source) Name() string { return SourceName }
+
+// RequiredConfig implements sources.Source.
+func (s *Source) RequiredConfig() []string { return []string{"HUD_API_KEY"} }
+
+// RelevantParams implements sources.Scoped: HUD rents are selected by state,
+// or by ZIP alone when ZIPs are given.
+func (s *Source) RelevantParams(p sources.Params) sources.Params {
+	if len(p.ZIPs) > 0 {
+		return sources.Params{ZIPs: p.ZIPs}
+	}
+	return sources.Params{States: p.States}
+}
+
+// WorkUnits returns one unit per selected state, or, when ZIPs are given,
+// the ZIPs in batches of zipsPerUnit. Units are planned from the parameters
+// alone, so dry runs and resumes make no requests before fetching.
+func (s *Source) WorkUnits(ctx context.Context, p sources.Params) ([]sources.WorkUnit, error) {
+	if len(p.ZIPs) > 0 {
+		units := make([]sources.WorkUnit, 0, len(p.ZIPs))
+		for _, zip := range p.ZIPs {
+			units = append(units, sources.WorkUnit{Key: zip, Name: "ZIP " + zip})
+		}
+		return sources.BatchWorkUnits(units, zipsPerUnit), nil
+	}
+
+	states := p.SelectedStates()
+	units := make([]sources.WorkUnit, 0, len(states))
+	for _, state := range states {
+		units = append(units, sources.WorkUnit{Key: state.Code, Name: state.Name})
+	}
+	return units, nil
+}
+
+// Fetch retrieves the records of a unit. A state unit gets the entity-level
+// rents of every metro and non-metro county of the state, since the HUD
+// statedata endpoint returns them all at once, plus the ZIP-level Small Area
+// FMRs of each of its metros that publish them. A ZIP unit gets the Small
+// Area FMRs of just its ZIPs, found through the counties each ZIP lies in.
+func (s *Source) Fetch(ctx context.Context, unit sources.WorkUnit, p sources.Params) (sources.Records, error) {
+	if len(unit.Members) > 0 {
+		return s.fetchZIPs(ctx, unit.Members)
+	}
+	return s.fetchState(ctx, unit.Key)
+}
+
+// fetchState retrieves a state's entity-level rents and its metros' Small
+// Area FMRs.
+func (s *Source) fetchState(ctx context.Context, stateCode string) (fmrRecords, error) {
+	stateData, err := s.client.GetStateData(ctx, stateCode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch state FMR data: %w", err)
+	}
+	records := stateRecords(stateCode, stateData)
+
+	for _, metro := range stateData.Data.MetroAreas {
+		if !strings.HasPrefix(metro.Code, metroCodePrefix) {
+			continue
+		}
+		zipRecords, err := s.client.GetZIPLevelFMR(ctx, metro.Code)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch Small Area FMR data for %s: %w", metro.MetroName, err)
+		}
+		records = append(records, zipRecords...)
+	}
+	return records, nil
+}
+
+// fetchZIPs retrieves the Small Area FMRs of the given ZIPs. Each county is
+// queried once, since it returns every ZIP of its metro. ZIPs outside Small
+// Area FMR metros have no records.
+func (s *Source) fetchZIPs(ctx context.Context, zips []string) (fmrRecords, error) {
+	var records fmrRecords
+	queried := make(map[string]bool)
+	seen := make(map[string]bool)
+
+	for _, zip := range zips {
+		counties, err := s.client.GetZIPCounties(ctx, zip)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up counties of ZIP %s: %w", zip, err)
+		}
+
+		for _, county := range counties {
+			if queried[county] {
+				continue
+			}
+			queried[county] = true
+
+			zipRecords, err := s.client.GetZIPLevelFMR(ctx, CountyEntityCode(county))
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch Small Area FMR data for county %s: %w", county, err)
+			}
+			for _, r := range filterZIPs(zipRecords, zips) {
+				key := *r.EntityCode + "/" + r.ZipCode
+				if !seen[key] {
+					seen[key] = true
+					records = append(records, r)
+				}
+			}
+		}
+	}
+	return records, nil
+}
+
+// Persist upserts the fetched FMR records.
+func (s *Source) Persist(ctx context.Context, store *db.Client, records sources.Records) error {
+	return store.BatchUpsertHUDFMR(ctx, records.(fmrRecords))
+}
+
+// LatestVintage implements sources.Versioned. The vintage is the fiscal year
+// HUD currently publishes for the first selected state.
+func (s *Source) LatestVintage(ctx context.Context, p sources.Params) (string, error) {
+	state := p.SelectedStates()[0]
+
+	stateData, err := s.client.GetStateData(ctx, state.Code)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch %s FMR vintage: %w", state.Code, err)
+	}
+	if stateData.Data.Year == "" {
+		return "", fmt.Errorf("HUD returned no fiscal year for %s", state.Code)
+	}
+	return "FY" + stateData.Data.Year, nil
+}
+
+// DefaultSchedule implements sources.Scheduled. FMRs are published once per
+// fiscal year, so a monthly check only syncs once per release.
+func (s *Source) DefaultSchedule() string { return "0 3 1 * *" }
+
+// filterZIPs keeps only the records for the given ZIP codes. An empty list
+// keeps every record.
+func filterZIPs(records []*db.HUDFairMarketRent, zips []string) []*db.HUDFairMarketRent {
+	if len(zips) == 0 {
+		return records
+	}
+
+	wanted := make(map[string]bool, len(zips))
+	for _, zip := range zips {
+		wanted[zip] = true
+	}
+
+	filtered := make([]*db.HUDFairMarketRent, 0, len(records))
+	for _, r := range records {
+		if wanted[r.ZipCode] {
+			filtered = append(filtered, r)
+		}
+	}
+	return filtered
+}
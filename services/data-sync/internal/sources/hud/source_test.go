@@ -0,0 +1,229 @@
+package hud
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dealforge/data-sync/internal/archive"
+	"github.com/dealforge/data-sync/internal/geography"
+	"github.com/dealforge/data-sync/internal/sources"
+)
+
+// newTestSource returns a Source backed by a fake HUD API with one Small
+// Area FMR metro in Texas, reachable through two of its counties.
+func newTestSource(t *testing.T) *Source {
+	sanAntonio := EntityDataResponse{
+		Data: EntityData{
+			EntityID:        "METRO41700M41700",
+			Year:            "2025",
+			SmallAreaStatus: "1",
+			SmallAreas: []SmallAreaData{
+				{ZipCode: "78201", TwoBedroom: 1250},
+				{ZipCode: "78259", TwoBedroom: 1710},
+				{ZipCode: "78006", TwoBedroom: 1640},
+			},
+		},
+	}
+	crosswalk := map[string][]CrosswalkResult{
+		"78259": {{GeoID: "48029", ResRatio: 1}},
+		"78006": {{GeoID: "48029", ResRatio: 0.3}, {GeoID: "48259", ResRatio: 0.7}},
+		"10001": {{GeoID: "36061", ResRatio: 1}},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response interface{}
+
+		switch {
+		case strings.Contains(r.URL.Path, "/statedata/"):
+			response = StateDataResponse{
+				Data: StateData{
+					Year: "2025",
+					MetroAreas: []MetroArea{
+						{MetroName: "San Antonio-New Braunfels", Code: "METRO41700M41700"},
+						{MetroName: "Texarkana", Code: "METRO45500M45500"},
+					},
+					Counties: []CountyArea{
+						{CountyName: "Hidalgo County", Code: "COUNTY48215"},
+					},
+				},
+			}
+		case strings.HasSuffix(r.URL.Path, "/usps"):
+			zip := r.URL.Query().Get("query")
+			response = CrosswalkResponse{Data: CrosswalkData{Input: zip, Results: crosswalk[zip]}}
+		case strings.HasSuffix(r.URL.Path, "/data/METRO41700M41700"),
+			strings.HasSuffix(r.URL.Path, "/data/4802999999"),
+			strings.HasSuffix(r.URL.Path, "/data/4825999999"):
+			response = sanAntonio
+		case strings.HasSuffix(r.URL.Path, "/data/METRO45500M45500"),
+			strings.HasSuffix(r.URL.Path, "/data/3606199999"):
+			response = EntityDataResponse{Data: EntityData{Year: "2025", SmallAreaStatus: "0"}}
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(server.Close)
+
+	return &Source{
+		client: &Client{
+			apiKey: "test-api-key",
+			httpClient: &http.Client{
+				Transport: &mockTransport{baseURL: server.URL},
+			},
+		},
+	}
+}
+
+// newOfflineSource returns a Source whose every request fails the test.
+func newOfflineSource(t *testing.T) *Source {
+	offline := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		t.Errorf("unexpected request to %s", r.URL)
+		return nil, errors.New("network access")
+	})
+	return &Source{client: &Client{httpClient: &http.Client{Transport: offline}}}
+}
+
+func TestSource_WorkUnits(t *testing.T) {
+	src := newOfflineSource(t)
+	params := sources.Params{States: []geography.State{*geography.GetState("TX"), *geography.GetState("OK")}}
+
+	units, err := src.WorkUnits(context.Background(), params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{"TX", "OK"}
+	if len(units) != len(expected) {
+		t.Fatalf("expected %d units, got %d", len(expected), len(units))
+	}
+	for i, key := range expected {
+		if units[i].Key != key {
+			t.Errorf("units[%d] = %s, expected %s", i, units[i].Key, key)
+		}
+	}
+}
+
+func TestSource_WorkUnits_BatchesZIPs(t *testing.T) {
+	src := newOfflineSource(t)
+	zips := make([]string, zipsPerUnit+5)
+	for i := range zips {
+		zips[i] = fmt.Sprintf("%05d", 78000+i)
+	}
+	params := sources.Params{States: []geography.State{*geography.GetState("TX")}, ZIPs: zips}
+
+	units, err := src.WorkUnits(context.Background(), params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Only ZIP units, with no state unit
+	if len(units) != 2 {
+		t.Fatalf("expected 2 units, got %d", len(units))
+	}
+	if len(units[0].Members) != zipsPerUnit || len(units[1].Members) != 5 {
+		t.Errorf("expected batches of %d and 5 ZIPs, got %d and %d", zipsPerUnit, len(units[0].Members), len(units[1].Members))
+	}
+	if units[1].Members[4] != zips[len(zips)-1] {
+		t.Errorf("expected the last batch to end with %s, got %s", zips[len(zips)-1], units[1].Members[4])
+	}
+}
+
+func TestSource_Fetch_State(t *testing.T) {
+	src := newTestSource(t)
+
+	records, err := src.Fetch(context.Background(), sources.WorkUnit{Key: "TX", Name: "Texas"}, sources.Params{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Two metros and a county, plus the San Antonio ZIPs; Texarkana has none
+	fmr := records.(fmrRecords)
+	if len(fmr) != 6 {
+		t.Fatalf("expected 6 records, got %d", len(fmr))
+	}
+	zips := 0
+	for _, r := range fmr {
+		if r.ZipCode != "" {
+			zips++
+		}
+	}
+	if zips != 3 {
+		t.Errorf("expected 3 ZIP-level records, got %d", zips)
+	}
+}
+
+func TestSource_Fetch_ZIPs(t *testing.T) {
+	src := newTestSource(t)
+	unit := sources.BatchWorkUnits([]sources.WorkUnit{{Key: "78259"}, {Key: "78006"}, {Key: "10001"}}, zipsPerUnit)[0]
+
+	records, err := src.Fetch(context.Background(), unit, sources.Params{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// 78006 is reached through both of its counties but loaded once, and
+	// 10001 lies outside any Small Area FMR metro
+	fmr := records.(fmrRecords)
+	if len(fmr) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(fmr))
+	}
+	if fmr[0].ZipCode != "78259" || fmr[1].ZipCode != "78006" {
+		t.Errorf("expected ZIPs 78259 and 78006, got %s and %s", fmr[0].ZipCode, fmr[1].ZipCode)
+	}
+	for _, r := range fmr {
+		if *r.EntityCode != "METRO41700M41700" {
+			t.Errorf("expected ZIP %s to be keyed by its metro, got %s", r.ZipCode, *r.EntityCode)
+		}
+	}
+}
+
+func TestSource_Fetch_ReplaysRecordedFixtures(t *testing.T) {
+	store, err := archive.NewDirStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fixtures := archive.New(store)
+	defer archive.Use(nil, archive.Record)
+
+	unit := sources.BatchWorkUnits([]sources.WorkUnit{{Key: "78259"}, {Key: "78006"}}, zipsPerUnit)[0]
+
+	// Record against the fake API
+	src := newTestSource(t)
+	src.client.httpClient.Transport = archive.Transport(SourceName, src.client.httpClient.Transport)
+	archive.Use(fixtures, archive.RecordAll)
+	recorded, err := src.Fetch(context.Background(), unit, sources.Params{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Replay with no network and no API key
+	offline := roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return nil, errors.New("network access during replay")
+	})
+	src = &Source{client: &Client{httpClient: &http.Client{Transport: archive.Transport(SourceName, offline)}}}
+	archive.Use(fixtures, archive.Offline)
+	replayed, err := src.Fetch(context.Background(), unit, sources.Params{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(recorded, replayed) {
+		t.Errorf("expected the replay to parse the recorded records, got %+v and %+v", recorded, replayed)
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
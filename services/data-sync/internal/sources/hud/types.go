@@ -68,6 +68,25 @@ type SmallAreaData struct {
 	FourBedroom  int    `json:"Four-Bedroom"`
 }
 
+// CrosswalkResponse represents the HUD USPS ZIP crosswalk API response.
+type CrosswalkResponse struct {
+	Data CrosswalkData `json:"data"`
+}
+
+// CrosswalkData lists the geographies a ZIP code maps to.
+type CrosswalkData struct {
+	Input         string            `json:"input"`
+	CrosswalkType string            `json:"crosswalk_type"` // e.g., "zip-county"
+	Results       []CrosswalkResult `json:"results"`
+}
+
+// CrosswalkResult is one geography a ZIP code lies in, with its share of
+// the ZIP's residential addresses.
+type CrosswalkResult struct {
+	GeoID    string  `json:"geoid"` // County GEOID for ZIP-to-county crosswalks
+	ResRatio float64 `json:"res_ratio"`
+}
+
 // FMRResponse represents the legacy HUD FMR API response (kept for compatibility).
 type FMRResponse struct {
 	Status  string  `json:"status"`
@@ -0,0 +1,98 @@
+package sources
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"github.com/dealforge/data-sync/internal/geography"
+)
+
+// paramsJSON is the encoded form of Params, recorded with each checkpoint.
+type paramsJSON struct {
+	States    []string `json:"states,omitempty"` // USPS codes
+	ZIPs      []string `json:"zips,omitempty"`
+	Counties  []string `json:"counties,omitempty"`
+	GeoLevel  string   `json:"geo_level,omitempty"`
+	Year      int      `json:"year,omitempty"`
+	StartYear int      `json:"start_year,omitempty"`
+	EndYear   int      `json:"end_year,omitempty"`
+}
+
+// MarshalJSON encodes the parameters with states as USPS codes.
+func (p Params) MarshalJSON() ([]byte, error) {
+	return json.Marshal(paramsJSON{
+		States:    stateCodes(p.States),
+		ZIPs:      p.ZIPs,
+		Counties:  p.Counties,
+		GeoLevel:  p.GeoLevel,
+		Year:      p.Year,
+		StartYear: p.StartYear,
+		EndYear:   p.EndYear,
+	})
+}
+
+// UnmarshalJSON decodes parameters encoded by MarshalJSON.
+func (p *Params) UnmarshalJSON(data []byte) error {
+	var raw paramsJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	var states []geography.State
+	if len(raw.States) > 0 {
+		parsed, err := geography.ParseStateList(strings.Join(raw.States, ","))
+		if err != nil {
+			return err
+		}
+		states = parsed
+	}
+
+	*p = Params{
+		States:    states,
+		ZIPs:      raw.ZIPs,
+		Counties:  raw.Counties,
+		GeoLevel:  raw.GeoLevel,
+		Year:      raw.Year,
+		StartYear: raw.StartYear,
+		EndYear:   raw.EndYear,
+	}
+	return nil
+}
+
+// Differences returns the JSON names of the parameters that differ between
+// p and other (e.g., "states", "start_year"), in field order. No states
+// selects Texas, so it equals an explicit Texas selection.
+func (p Params) Differences(other Params) []string {
+	var diffs []string
+	if !slices.Equal(stateCodes(p.SelectedStates()), stateCodes(other.SelectedStates())) {
+		diffs = append(diffs, "states")
+	}
+	if !slices.Equal(p.ZIPs, other.ZIPs) {
+		diffs = append(diffs, "zips")
+	}
+	if !slices.Equal(p.Counties, other.Counties) {
+		diffs = append(diffs, "counties")
+	}
+	if p.GeoLevel != other.GeoLevel {
+		diffs = append(diffs, "geo_level")
+	}
+	if p.Year != other.Year {
+		diffs = append(diffs, "year")
+	}
+	if p.StartYear != other.StartYear {
+		diffs = append(diffs, "start_year")
+	}
+	if p.EndYear != other.EndYear {
+		diffs = append(diffs, "end_year")
+	}
+	return diffs
+}
+
+func stateCodes(states []geography.State) []string {
+	codes := make([]string, 0, len(states))
+	for _, state := range states {
+		codes = append(codes, state.Code)
+	}
+	return codes
+}
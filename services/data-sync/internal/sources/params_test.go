@@ -0,0 +1,79 @@
+package sources
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/dealforge/data-sync/internal/geography"
+)
+
+func TestParams_JSONRoundTrip(t *testing.T) {
+	states, err := geography.ParseStateList("TX,OK")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	p := Params{
+		States:    states,
+		ZIPs:      []string{"78201"},
+		Counties:  []string{"48029"},
+		GeoLevel:  "tract",
+		Year:      2023,
+		StartYear: 2021,
+		EndYear:   2024,
+	}
+
+	data, err := json.Marshal(p)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `{"states":["TX","OK"],"zips":["78201"],"counties":["48029"],"geo_level":"tract","year":2023,"start_year":2021,"end_year":2024}`
+	if string(data) != want {
+		t.Errorf("expected %s, got %s", want, data)
+	}
+
+	var decoded Params
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diffs := p.Differences(decoded); len(diffs) > 0 {
+		t.Errorf("expected decoded params to equal the original, differences: %v", diffs)
+	}
+	if decoded.States[1].FIPS != "40" {
+		t.Errorf("expected Oklahoma to be decoded with its FIPS code, got %+v", decoded.States[1])
+	}
+}
+
+func TestParams_UnmarshalInvalidState(t *testing.T) {
+	var p Params
+	if err := json.Unmarshal([]byte(`{"states":["ZZ"]}`), &p); err == nil {
+		t.Error("expected an error for an unknown state")
+	}
+}
+
+func TestParams_Differences(t *testing.T) {
+	texas, err := geography.ParseStateList("TX")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	oklahoma, err := geography.ParseStateList("OK")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// No states selects Texas
+	if diffs := (Params{}).Differences(Params{States: texas}); len(diffs) > 0 {
+		t.Errorf("expected no differences, got %v", diffs)
+	}
+
+	diffs := Params{States: texas, StartYear: 2022}.Differences(Params{States: oklahoma, StartYear: 2020, GeoLevel: "county"})
+	want := []string{"states", "geo_level", "start_year"}
+	if len(diffs) != len(want) {
+		t.Fatalf("expected differences %v, got %v", want, diffs)
+	}
+	for i := range want {
+		if diffs[i] != want[i] {
+			t.Errorf("expected differences %v, got %v", want, diffs)
+			break
+		}
+	}
+}
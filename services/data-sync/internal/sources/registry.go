@@ -0,0 +1,109 @@
+package sources
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/dealforge/data-sync/internal/config"
+)
+
+// Factory builds a Source from the service configuration.
+type Factory func(cfg *config.Config) Source
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Factory)
+)
+
+// Register makes a source available under the given name.
+// It panics if the name is empty or already registered, since both indicate
+// a programming error in a source package's init function.
+func Register(name string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if name == "" {
+		panic("sources: Register called with empty name")
+	}
+	if factory == nil {
+		panic("sources: Register factory is nil for " + name)
+	}
+	if _, dup := registry[name]; dup {
+		panic("sources: Register called twice for " + name)
+	}
+	registry[name] = factory
+}
+
+// Names returns the names of all registered sources in sorted order.
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	return sortedNames()
+}
+
+// New builds the source registered under name.
+func New(name string, cfg *config.Config) (Source, error) {
+	registryMu.RLock()
+	factory, ok := registry[name]
+	if !ok {
+		err := unknownSourceError(name)
+		registryMu.RUnlock()
+		return nil, err
+	}
+	registryMu.RUnlock()
+
+	return factory(cfg), nil
+}
+
+// ParseList parses a comma-separated list of source names as given to the
+// --sources flag. An empty list or "all" selects every registered source.
+// Unknown names are an error that lists the registered sources.
+func ParseList(list string) ([]string, error) {
+	list = strings.TrimSpace(strings.ToLower(list))
+	if list == "" || list == "all" {
+		return Names(), nil
+	}
+
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	parts := strings.Split(list, ",")
+	names := make([]string, 0, len(parts))
+	seen := make(map[string]bool, len(parts))
+	for _, p := range parts {
+		name := strings.TrimSpace(p)
+		if name == "" || seen[name] {
+			continue
+		}
+		if _, ok := registry[name]; !ok {
+			return nil, unknownSourceError(name)
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no sources selected")
+	}
+	return names, nil
+}
+
+// unknownSourceError reports an unregistered name along with the valid ones.
+// Callers must hold registryMu for reading.
+func unknownSourceError(name string) error {
+	return fmt.Errorf("unknown source %q (registered sources: %s)", name, strings.Join(sortedNames(), ", "))
+}
+
+// sortedNames returns the registered names in sorted order.
+// Callers must hold registryMu for reading.
+func sortedNames() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,93 @@
+package sources
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/dealforge/data-sync/internal/config"
+	"github.com/dealforge/data-sync/internal/db"
+)
+
+type fakeSource struct{ name string }
+
+func (f *fakeSource) Name() string             { return f.name }
+func (f *fakeSource) RequiredConfig() []string { return nil }
+func (f *fakeSource) WorkUnits(ctx context.Context, p Params) ([]WorkUnit, error) {
+	return nil, nil
+}
+func (f *fakeSource) Fetch(ctx context.Context, unit WorkUnit, p Params) (Records, error) {
+	return nil, nil
+}
+func (f *fakeSource) Persist(ctx context.Context, store *db.Client, records Records) error {
+	return nil
+}
+
+func init() {
+	for _, name := range []string{"alpha", "beta"} {
+		name := name
+		Register(name, func(cfg *config.Config) Source { return &fakeSource{name: name} })
+	}
+}
+
+func TestParseList(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"", []string{"alpha", "beta"}},
+		{"all", []string{"alpha", "beta"}},
+		{"ALL", []string{"alpha", "beta"}},
+		{"beta", []string{"beta"}},
+		{" beta , alpha ", []string{"beta", "alpha"}},
+		{"alpha,alpha", []string{"alpha"}},
+	}
+
+	for _, tt := range tests {
+		result, err := ParseList(tt.input)
+		if err != nil {
+			t.Errorf("ParseList(%q) unexpected error: %v", tt.input, err)
+			continue
+		}
+		if strings.Join(result, ",") != strings.Join(tt.expected, ",") {
+			t.Errorf("ParseList(%q) = %v, expected %v", tt.input, result, tt.expected)
+		}
+	}
+}
+
+func TestParseList_UnknownSource(t *testing.T) {
+	_, err := ParseList("alpha,fema")
+	if err == nil {
+		t.Fatal("expected error for unknown source, got nil")
+	}
+
+	if !strings.Contains(err.Error(), `"fema"`) {
+		t.Errorf("expected error to name the unknown source, got: %v", err)
+	}
+	if !strings.Contains(err.Error(), "alpha, beta") {
+		t.Errorf("expected error to list registered sources, got: %v", err)
+	}
+}
+
+func TestNew(t *testing.T) {
+	src, err := New("beta", &config.Config{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if src.Name() != "beta" {
+		t.Errorf("expected source beta, got %s", src.Name())
+	}
+
+	if _, err := New("gamma", &config.Config{}); err == nil {
+		t.Error("expected error for unregistered source, got nil")
+	}
+}
+
+func TestRegister_PanicsOnDuplicate(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic on duplicate registration")
+		}
+	}()
+	Register("alpha", func(cfg *config.Config) Source { return &fakeSource{name: "alpha"} })
+}
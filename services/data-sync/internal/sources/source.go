@@ -0,0 +1,131 @@
+// Package sources defines the interface implemented by every upstream dataset
+// and the registry the orchestrator uses to look sources up by name.
+//
+// Each dataset lives in its own package under internal/sources/ and registers
+// itself from an init function. The orchestrator only ever talks to the
+// Source interface, so adding a dataset never requires touching the
+// orchestrator, the CLI, or configuration validation.
+package sources
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dealforge/data-sync/internal/db"
+	"github.com/dealforge/data-sync/internal/geography"
+)
+
+// ErrQuotaExhausted is returned (or wrapped) by a source when the upstream API
+// refuses further requests for the day. The orchestrator stops the run early
+// and marks the checkpoint as rate limited so it can be resumed later.
+var ErrQuotaExhausted = errors.New("upstream request quota exhausted")
+
+// ErrUpstreamUnavailable is returned (or wrapped) by a source when its
+// upstream API has failed repeatedly and its circuit breaker is open. The
+// orchestrator stops the run, defers the remaining units, and marks the
+// checkpoint as upstream_unavailable so it can be resumed once the API
+// recovers.
+var ErrUpstreamUnavailable = errors.New("upstream unavailable")
+
+// ErrVintageUnavailable is returned (or wrapped) by Versioned sources when
+// the vintage a run would sync has not been published upstream yet.
+var ErrVintageUnavailable = errors.New("vintage not yet published upstream")
+
+// Params holds the run parameters shared by all sources. Each source reads
+// the fields that apply to it and fills in its own defaults for zero values.
+type Params struct {
+	States    []geography.State // States to sync (defaults to Texas)
+	ZIPs      []string          // Restricts ZIP-level data to these ZIP codes (all when empty)
+	Counties  []string          // County GEOIDs that sub-county geographies are restricted to
+	GeoLevel  string            // Geography level for sources that support several (e.g., "tract")
+	Year      int               // Survey year for annual datasets
+	StartYear int               // First year of a time-series range
+	EndYear   int               // Last year of a time-series range
+}
+
+// DefaultState is synced when no states are requested.
+const DefaultState = "TX"
+
+// SelectedStates returns the requested states, or Texas if none were given.
+func (p Params) SelectedStates() []geography.State {
+	if len(p.States) > 0 {
+		return p.States
+	}
+	return []geography.State{*geography.GetState(DefaultState)}
+}
+
+// WorkUnit is one independently fetchable piece of a sync run, such as a
+// single county or a whole state.
+type WorkUnit struct {
+	Key     string   // Stable identifier recorded in checkpoints (e.g., county FIPS)
+	Name    string   // Human-readable label used in logs and error messages
+	Members []string // Keys of the individual geographies in a batched unit
+}
+
+// Records is the parsed output of fetching a single work unit.
+type Records interface {
+	// Len reports how many rows Persist will write, so dry runs can report
+	// counts without touching the database.
+	Len() int
+}
+
+// Vintaged is implemented by Records that know the upstream vintage they
+// were published in, in the format of Versioned.LatestVintage. A run reports
+// the latest vintage of the records it fetched, without asking the upstream
+// again.
+type Vintaged interface {
+	Records
+	Vintage() string
+}
+
+// Source is implemented by every upstream dataset.
+type Source interface {
+	// Name returns the registry name used on the command line (e.g., "bls").
+	Name() string
+
+	// RequiredConfig lists the environment variables that must be set for
+	// this source to run.
+	RequiredConfig() []string
+
+	// WorkUnits enumerates the units of work for a run, in a stable order.
+	WorkUnits(ctx context.Context, p Params) ([]WorkUnit, error)
+
+	// Fetch retrieves and parses the data for a single work unit.
+	Fetch(ctx context.Context, unit WorkUnit, p Params) (Records, error)
+
+	// Persist writes records previously returned by Fetch.
+	Persist(ctx context.Context, store *db.Client, records Records) error
+}
+
+// Versioned is implemented by sources that can cheaply report which vintage
+// of their dataset a run would sync (e.g., "FY2025" or "2024-08"), so that
+// scheduled runs can be skipped when nothing has changed upstream.
+type Versioned interface {
+	Source
+	LatestVintage(ctx context.Context, p Params) (string, error)
+}
+
+// Scheduled is implemented by sources with a default cadence for the
+// built-in scheduler, as a standard five-field cron expression.
+type Scheduled interface {
+	Source
+	DefaultSchedule() string
+}
+
+// Scoped is implemented by sources that read only some of the run
+// parameters. RelevantParams returns p with the parameters the source
+// ignores cleared and its own defaults filled in (e.g., the survey year a
+// zero Year selects), so that sessions are recorded and compared on what
+// actually determines their data, whichever entry point started them.
+type Scoped interface {
+	Source
+	RelevantParams(p Params) Params
+}
+
+// YearRanged is implemented by time-series sources that sync a range of
+// years. The resolved range is recorded with each checkpoint so that a
+// session is never resumed with a different one.
+type YearRanged interface {
+	Source
+	YearRange(p Params) (startYear, endYear int)
+}
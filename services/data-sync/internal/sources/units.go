@@ -0,0 +1,104 @@
+package sources
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dealforge/data-sync/internal/geography"
+)
+
+// CountyWorkUnits returns one work unit per county in the given states, in
+// state then county order. Each unit is keyed by the 5-digit county GEOID
+// (state FIPS + county FIPS) so keys stay unique across states.
+func CountyWorkUnits(states []geography.State) ([]WorkUnit, error) {
+	var units []WorkUnit
+	for _, state := range states {
+		counties, err := state.Counties()
+		if err != nil {
+			return nil, err
+		}
+		for _, county := range counties {
+			units = append(units, WorkUnit{
+				Key:  state.FIPS + county.FIPS,
+				Name: fmt.Sprintf("%s, %s", county.Name, state.Code),
+			})
+		}
+	}
+	return units, nil
+}
+
+// CountyListWorkUnits returns one work unit per county GEOID, in the given
+// order, keyed like CountyWorkUnits. Counties without a bundled table entry
+// are labelled with their GEOID.
+func CountyListWorkUnits(geoids []string) ([]WorkUnit, error) {
+	units := make([]WorkUnit, 0, len(geoids))
+	for _, geoid := range geoids {
+		stateFIPS, countyFIPS, err := SplitCountyKey(geoid)
+		if err != nil {
+			return nil, err
+		}
+
+		name := geoid
+		if state := geography.GetState(stateFIPS); state != nil {
+			if county := state.GetCountyByFIPS(countyFIPS); county != nil {
+				name = fmt.Sprintf("%s, %s", county.Name, state.Code)
+			}
+		}
+		units = append(units, WorkUnit{Key: geoid, Name: name})
+	}
+	return units, nil
+}
+
+// SplitCountyKey splits a county work unit key into its state and county
+// FIPS codes.
+func SplitCountyKey(key string) (stateFIPS, countyFIPS string, err error) {
+	if len(key) != 5 {
+		return "", "", fmt.Errorf("invalid county key %q: expected 5-digit GEOID", key)
+	}
+	return key[:2], key[2:], nil
+}
+
+// BatchWorkUnits groups units into batches of at most size units each, for
+// sources whose upstream API accepts several geographies per request. Each
+// batch is keyed by its first and last member keys (e.g., "48001-48023")
+// and lists every member key in Members.
+func BatchWorkUnits(units []WorkUnit, size int) []WorkUnit {
+	if size < 1 {
+		size = 1
+	}
+
+	batches := make([]WorkUnit, 0, (len(units)+size-1)/size)
+	for start := 0; start < len(units); start += size {
+		end := min(start+size, len(units))
+		first, last := units[start], units[end-1]
+
+		batch := WorkUnit{
+			Key:     first.Key,
+			Name:    first.Name,
+			Members: make([]string, 0, end-start),
+		}
+		if end-start > 1 {
+			batch.Key = first.Key + "-" + last.Key
+			batch.Name = fmt.Sprintf("%s to %s (%d)", first.Name, last.Name, end-start)
+		}
+		for _, u := range units[start:end] {
+			batch.Members = append(batch.Members, u.Key)
+		}
+		batches = append(batches, batch)
+	}
+	return batches
+}
+
+type unitContextKey struct{}
+
+// WithUnit returns a copy of ctx carrying the work unit being fetched, so
+// the HTTP layer can tell which unit a request belongs to.
+func WithUnit(ctx context.Context, unit WorkUnit) context.Context {
+	return context.WithValue(ctx, unitContextKey{}, unit)
+}
+
+// UnitFromContext returns the work unit ctx carries, if any.
+func UnitFromContext(ctx context.Context) (WorkUnit, bool) {
+	unit, ok := ctx.Value(unitContextKey{}).(WorkUnit)
+	return unit, ok
+}
@@ -0,0 +1,69 @@
+package sources
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dealforge/data-sync/internal/geography"
+)
+
+func TestCountyWorkUnits(t *testing.T) {
+	states, err := geography.ParseStateList("NM,OK")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	units, err := CountyWorkUnits(states)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(units) != 33+77 {
+		t.Fatalf("expected %d units, got %d", 33+77, len(units))
+	}
+	if units[0].Key != "35001" || units[0].Name != "Bernalillo, NM" {
+		t.Errorf("unexpected first unit: %+v", units[0])
+	}
+	if units[33].Key != "40001" || units[33].Name != "Adair, OK" {
+		t.Errorf("unexpected first Oklahoma unit: %+v", units[33])
+	}
+
+	if _, err := CountyWorkUnits([]geography.State{*geography.GetState("CA")}); err == nil {
+		t.Error("expected error for state without county table, got nil")
+	}
+}
+
+func TestSplitCountyKey(t *testing.T) {
+	stateFIPS, countyFIPS, err := SplitCountyKey("48029")
+	if err != nil || stateFIPS != "48" || countyFIPS != "029" {
+		t.Errorf("SplitCountyKey(48029) = %q, %q, %v", stateFIPS, countyFIPS, err)
+	}
+
+	if _, _, err := SplitCountyKey("029"); err == nil {
+		t.Error("expected error for 3-digit key, got nil")
+	}
+}
+
+func TestBatchWorkUnits(t *testing.T) {
+	units := make([]WorkUnit, 0, 25)
+	for _, key := range strings.Fields("a b c d e f g h i j k l m n o p q r s t u v w x y") {
+		units = append(units, WorkUnit{Key: key, Name: strings.ToUpper(key)})
+	}
+
+	batches := BatchWorkUnits(units, 12)
+	if len(batches) != 3 {
+		t.Fatalf("expected 3 batches, got %d", len(batches))
+	}
+
+	if batches[0].Key != "a-l" || len(batches[0].Members) != 12 {
+		t.Errorf("unexpected first batch: %+v", batches[0])
+	}
+	if batches[1].Key != "m-x" || batches[1].Members[0] != "m" {
+		t.Errorf("unexpected second batch: %+v", batches[1])
+	}
+
+	// A single-member batch keeps the member's own key and name
+	if batches[2].Key != "y" || batches[2].Name != "Y" || len(batches[2].Members) != 1 {
+		t.Errorf("unexpected last batch: %+v", batches[2])
+	}
+}
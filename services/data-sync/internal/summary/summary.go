@@ -0,0 +1,249 @@
+// Package summary reports the outcome of a one-shot sync run, as text for
+// people, or as JSON or Markdown (e.g., a GitHub step summary) for CI.
+package summary
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/dealforge/data-sync/internal/db"
+	datasync "github.com/dealforge/data-sync/internal/sync"
+)
+
+// Output formats.
+const (
+	FormatText     = "text"
+	FormatJSON     = "json"
+	FormatMarkdown = "markdown"
+)
+
+// StatusSkipped marks a source that did not run because another sync of it
+// held the lock. Other sources have one of the db.Run* statuses.
+const StatusSkipped = "skipped"
+
+// Overall outcomes of a run, from best to worst.
+const (
+	OutcomeSuccess     = "success"              // Every source completed
+	OutcomePartial     = "partial"              // Some units failed or a source was skipped
+	OutcomeRateLimited = "rate_limited"         // A source stopped at the upstream quota
+	OutcomeUnavailable = "upstream_unavailable" // A source stopped because its upstream was down
+	OutcomeFailed      = "failed"               // A source failed
+)
+
+// Process exit codes by outcome. Exit code 2 is left to flag usage errors.
+const (
+	ExitSuccess     = 0
+	ExitFailed      = 1
+	ExitPartial     = 3
+	ExitRateLimited = 4
+	ExitUnavailable = 5
+)
+
+// maxTextErrors limits the errors listed per source in the text format.
+const maxTextErrors = 10
+
+// Source is the outcome of one source.
+type Source struct {
+	Source          string   `json:"source"`
+	Status          string   `json:"status"`
+	SessionID       string   `json:"session_id,omitempty"`
+	Vintage         string   `json:"vintage,omitempty"`
+	Successful      int      `json:"successful"`
+	Failed          int      `json:"failed"`
+	Skipped         int      `json:"skipped"`
+	Deferred        int      `json:"deferred,omitempty"` // Units left for a resume by an upstream outage
+	DurationSeconds float64  `json:"duration_seconds"`
+	Resume          string   `json:"resume,omitempty"` // Flag that resumes a rate-limited or upstream-unavailable session
+	Errors          []string `json:"errors"`
+}
+
+// Summary collects the outcome of each source of a run.
+type Summary struct {
+	DryRun  bool
+	Sources []Source
+}
+
+// Add records the outcome of syncing a source. result may be nil if err is
+// set; status is a db.Run* status or StatusSkipped.
+func (s *Summary) Add(source, status string, result *datasync.SyncResult, err error) {
+	entry := Source{Source: source, Status: status, Errors: []string{}}
+	if result != nil {
+		entry.SessionID = result.SessionID
+		entry.Vintage = result.Vintage
+		entry.Successful = result.Successful
+		entry.Failed = result.Failed
+		entry.Skipped = result.Skipped
+		entry.Deferred = result.Deferred
+		entry.DurationSeconds = result.Duration.Seconds()
+		entry.Errors = append(entry.Errors, result.Errors...)
+		if (result.RateLimited || result.Unavailable) && result.SessionID != "" {
+			entry.Resume = "--resume=" + result.SessionID
+		}
+	}
+	if err != nil {
+		entry.Errors = append(entry.Errors, err.Error())
+	}
+	s.Sources = append(s.Sources, entry)
+}
+
+// Outcome returns the worst outcome of any source.
+func (s *Summary) Outcome() string {
+	outcome := OutcomeSuccess
+	for _, src := range s.Sources {
+		outcome = worse(outcome, sourceOutcome(src.Status))
+	}
+	return outcome
+}
+
+// ExitCode returns the process exit code for the run's outcome.
+func (s *Summary) ExitCode() int {
+	switch s.Outcome() {
+	case OutcomeFailed:
+		return ExitFailed
+	case OutcomeUnavailable:
+		return ExitUnavailable
+	case OutcomeRateLimited:
+		return ExitRateLimited
+	case OutcomePartial:
+		return ExitPartial
+	default:
+		return ExitSuccess
+	}
+}
+
+// Write writes the summary in the given format.
+func (s *Summary) Write(w io.Writer, format string) error {
+	switch format {
+	case FormatText:
+		return s.writeText(w)
+	case FormatJSON:
+		return s.writeJSON(w)
+	case FormatMarkdown:
+		return s.writeMarkdown(w)
+	default:
+		return fmt.Errorf("unknown summary format %q (expected %s, %s or %s)", format, FormatText, FormatJSON, FormatMarkdown)
+	}
+}
+
+func (s *Summary) writeJSON(w io.Writer) error {
+	sources := s.Sources
+	if sources == nil {
+		sources = []Source{}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		Outcome  string   `json:"outcome"`
+		ExitCode int      `json:"exit_code"`
+		DryRun   bool     `json:"dry_run"`
+		Sources  []Source `json:"sources"`
+	}{s.Outcome(), s.ExitCode(), s.DryRun, sources})
+}
+
+func (s *Summary) writeText(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("\n=== Sync Summary ===\n")
+	for _, src := range s.Sources {
+		fmt.Fprintf(&b, "\n%s (%s):\n", src.Source, src.Status)
+		fmt.Fprintf(&b, "  Successful: %d\n", src.Successful)
+		fmt.Fprintf(&b, "  Failed: %d\n", src.Failed)
+		if src.Deferred > 0 {
+			fmt.Fprintf(&b, "  Deferred: %d\n", src.Deferred)
+		}
+		fmt.Fprintf(&b, "  Duration: %s\n", formatDuration(src.DurationSeconds))
+		if src.Resume != "" {
+			fmt.Fprintf(&b, "  Resume with: %s\n", src.Resume)
+		}
+		if len(src.Errors) > 0 && len(src.Errors) <= maxTextErrors {
+			b.WriteString("  Errors:\n")
+			for _, e := range src.Errors {
+				fmt.Fprintf(&b, "    - %s\n", e)
+			}
+		} else if len(src.Errors) > maxTextErrors {
+			fmt.Fprintf(&b, "  First %d errors (of %d):\n", maxTextErrors, len(src.Errors))
+			for _, e := range src.Errors[:maxTextErrors] {
+				fmt.Fprintf(&b, "    - %s\n", e)
+			}
+		}
+	}
+	fmt.Fprintf(&b, "\nOutcome: %s\n", s.Outcome())
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (s *Summary) writeMarkdown(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## Data Sync: %s\n\n", s.Outcome())
+	if s.DryRun {
+		b.WriteString("_Dry run: nothing was written to the database._\n\n")
+	}
+
+	b.WriteString("| Source | Status | Records | Failed units | Duration | Vintage |\n")
+	b.WriteString("|--------|--------|---------|--------------|----------|---------|\n")
+	for _, src := range s.Sources {
+		vintage := src.Vintage
+		if vintage == "" {
+			vintage = "-"
+		}
+		fmt.Fprintf(&b, "| %s | %s | %d | %d | %s | %s |\n",
+			src.Source, src.Status, src.Successful, src.Failed, formatDuration(src.DurationSeconds), vintage)
+	}
+
+	for _, src := range s.Sources {
+		switch {
+		case src.Resume == "":
+		case src.Status == db.RunUnavailable:
+			fmt.Fprintf(&b, "\n> **%s** stopped because its upstream was unavailable, deferring %d units. Resume with `%s`.\n", src.Source, src.Deferred, src.Resume)
+		default:
+			fmt.Fprintf(&b, "\n> **%s** stopped at the upstream quota. Resume with `%s`.\n", src.Source, src.Resume)
+		}
+	}
+
+	for _, src := range s.Sources {
+		if len(src.Errors) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n<details><summary>%s: %d error(s)</summary>\n\n", src.Source, len(src.Errors))
+		for _, e := range src.Errors {
+			fmt.Fprintf(&b, "- %s\n", strings.ReplaceAll(e, "\n", " "))
+		}
+		b.WriteString("\n</details>\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// sourceOutcome maps a source's status to the outcome it contributes.
+func sourceOutcome(status string) string {
+	switch status {
+	case db.RunCompleted:
+		return OutcomeSuccess
+	case db.RunPartial, StatusSkipped:
+		return OutcomePartial
+	case db.RunRateLimited:
+		return OutcomeRateLimited
+	case db.RunUnavailable:
+		return OutcomeUnavailable
+	default:
+		return OutcomeFailed
+	}
+}
+
+// worse returns the worse of two outcomes.
+func worse(a, b string) string {
+	rank := map[string]int{OutcomeSuccess: 0, OutcomePartial: 1, OutcomeRateLimited: 2, OutcomeUnavailable: 3, OutcomeFailed: 4}
+	if rank[b] > rank[a] {
+		return b
+	}
+	return a
+}
+
+func formatDuration(seconds float64) string {
+	return (time.Duration(seconds * float64(time.Second))).Round(time.Millisecond).String()
+}
@@ -0,0 +1,181 @@
+package summary
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dealforge/data-sync/internal/db"
+	datasync "github.com/dealforge/data-sync/internal/sync"
+)
+
+func TestSummary_Outcome(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []string
+		outcome  string
+		exitCode int
+	}{
+		{"no sources", nil, OutcomeSuccess, ExitSuccess},
+		{"all completed", []string{db.RunCompleted, db.RunCompleted}, OutcomeSuccess, ExitSuccess},
+		{"failed units", []string{db.RunCompleted, db.RunPartial}, OutcomePartial, ExitPartial},
+		{"locked source", []string{StatusSkipped, db.RunCompleted}, OutcomePartial, ExitPartial},
+		{"rate limited", []string{db.RunPartial, db.RunRateLimited}, OutcomeRateLimited, ExitRateLimited},
+		{"upstream unavailable", []string{db.RunRateLimited, db.RunUnavailable}, OutcomeUnavailable, ExitUnavailable},
+		{"failed", []string{db.RunRateLimited, db.RunFailed, db.RunCompleted}, OutcomeFailed, ExitFailed},
+		{"cancelled", []string{db.RunCancelled}, OutcomeFailed, ExitFailed},
+	}
+
+	for _, tt := range tests {
+		sum := &Summary{}
+		for i, status := range tt.statuses {
+			sum.Add(fmt.Sprintf("source%d", i), status, &datasync.SyncResult{}, nil)
+		}
+		if got := sum.Outcome(); got != tt.outcome {
+			t.Errorf("%s: expected outcome %q, got %q", tt.name, tt.outcome, got)
+		}
+		if got := sum.ExitCode(); got != tt.exitCode {
+			t.Errorf("%s: expected exit code %d, got %d", tt.name, tt.exitCode, got)
+		}
+	}
+}
+
+func testSummary() *Summary {
+	sum := &Summary{}
+	sum.Add("hud", db.RunCompleted, &datasync.SyncResult{
+		Source:     "hud",
+		SessionID:  "hud_1736467200",
+		Vintage:    "FY2025",
+		Successful: 254,
+		Duration:   90 * time.Second,
+	}, nil)
+
+	errs := make([]string, 12)
+	for i := range errs {
+		errs[i] = fmt.Sprintf("county %d: timeout", i)
+	}
+	sum.Add("bls", db.RunRateLimited, &datasync.SyncResult{
+		Source:      "bls",
+		SessionID:   "bls_1736467200",
+		Successful:  100,
+		Failed:      12,
+		RateLimited: true,
+		Duration:    time.Minute,
+		Errors:      errs,
+	}, nil)
+
+	sum.Add("census", db.RunFailed, nil, errors.New("failed to enumerate census work units"))
+	return sum
+}
+
+func TestSummary_WriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := testSummary().Write(&buf, FormatJSON); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var decoded struct {
+		Outcome  string   `json:"outcome"`
+		ExitCode int      `json:"exit_code"`
+		Sources  []Source `json:"sources"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+
+	if decoded.Outcome != OutcomeFailed || decoded.ExitCode != ExitFailed {
+		t.Errorf("expected failed outcome, got %q (exit code %d)", decoded.Outcome, decoded.ExitCode)
+	}
+	if len(decoded.Sources) != 3 {
+		t.Fatalf("expected 3 sources, got %d", len(decoded.Sources))
+	}
+
+	bls := decoded.Sources[1]
+	if bls.Resume != "--resume=bls_1736467200" {
+		t.Errorf("expected resume hint for the rate-limited source, got %q", bls.Resume)
+	}
+	if len(bls.Errors) != 12 {
+		t.Errorf("expected the full error list of 12, got %d", len(bls.Errors))
+	}
+	if bls.DurationSeconds != 60 {
+		t.Errorf("expected duration of 60 seconds, got %v", bls.DurationSeconds)
+	}
+	if decoded.Sources[0].Resume != "" {
+		t.Errorf("expected no resume hint for a completed source, got %q", decoded.Sources[0].Resume)
+	}
+
+	census := decoded.Sources[2]
+	if census.Status != db.RunFailed || len(census.Errors) != 1 {
+		t.Errorf("expected the census failure to be reported, got %+v", census)
+	}
+}
+
+func TestSummary_WriteMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	if err := testSummary().Write(&buf, FormatMarkdown); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"## Data Sync: failed",
+		"| hud | completed | 254 | 0 | 1m30s | FY2025 |",
+		"| bls | rate_limited | 100 | 12 | 1m0s | - |",
+		"Resume with `--resume=bls_1736467200`",
+		"<details><summary>bls: 12 error(s)</summary>",
+		"- county 11: timeout",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected markdown to contain %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestSummary_UpstreamUnavailable(t *testing.T) {
+	sum := &Summary{}
+	sum.Add("census", db.RunUnavailable, &datasync.SyncResult{
+		Source:      "census",
+		SessionID:   "census_1736467200",
+		Successful:  40,
+		Unavailable: true,
+		Deferred:    214,
+		Errors:      []string{"census: upstream unavailable - 214 units deferred. Resume with: --resume=census_1736467200"},
+	}, nil)
+
+	var buf bytes.Buffer
+	if err := sum.Write(&buf, FormatMarkdown); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "**census** stopped because its upstream was unavailable, deferring 214 units. Resume with `--resume=census_1736467200`."
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("expected markdown to contain %q, got:\n%s", want, buf.String())
+	}
+	if sum.ExitCode() != ExitUnavailable {
+		t.Errorf("expected exit code %d, got %d", ExitUnavailable, sum.ExitCode())
+	}
+}
+
+func TestSummary_WriteText_TruncatesErrors(t *testing.T) {
+	var buf bytes.Buffer
+	if err := testSummary().Write(&buf, FormatText); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+
+	if !strings.Contains(out, "First 10 errors (of 12)") {
+		t.Errorf("expected truncated error list, got:\n%s", out)
+	}
+	if strings.Contains(out, "county 11") {
+		t.Errorf("expected errors past the tenth to be omitted, got:\n%s", out)
+	}
+}
+
+func TestSummary_WriteUnknownFormat(t *testing.T) {
+	if err := testSummary().Write(&bytes.Buffer{}, "xml"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,64 @@
+package sync
+
+import (
+	"github.com/dealforge/data-sync/internal/db"
+	"github.com/dealforge/data-sync/internal/sources"
+)
+
+// resumePlan splits a resumed session's units into those still to process
+// and those already persisted.
+type resumePlan struct {
+	pending []sources.WorkUnit // Pending or failed units, in work unit order
+	done    []sources.WorkUnit // Units persisted by an earlier run
+
+	// untracked lists units without a status row, which must be recorded
+	// before they are processed.
+	untracked []sources.WorkUnit
+}
+
+// planResume decides which units a resumed session must process from the
+// recorded unit statuses. Units without a status (because the work units
+// changed, or the session predates per-unit tracking) are processed, except
+// that a legacy session with no unit rows at all treats every unit up to its
+// last completed entity as done.
+func planResume(units []sources.WorkUnit, recorded []*db.CheckpointUnit, lastCompleted *string) resumePlan {
+	status := make(map[string]string, len(recorded))
+	for _, unit := range recorded {
+		status[unit.UnitKey] = unit.Status
+	}
+
+	legacyDone := 0
+	if len(recorded) == 0 && lastCompleted != nil {
+		for i, unit := range units {
+			if unit.Key == *lastCompleted {
+				legacyDone = i + 1
+				break
+			}
+		}
+	}
+
+	var plan resumePlan
+	for i, unit := range units {
+		switch s, ok := status[unit.Key]; {
+		case i < legacyDone:
+			plan.done = append(plan.done, unit)
+		case !ok:
+			plan.pending = append(plan.pending, unit)
+			plan.untracked = append(plan.untracked, unit)
+		case s == db.UnitCompleted:
+			plan.done = append(plan.done, unit)
+		default:
+			plan.pending = append(plan.pending, unit)
+		}
+	}
+	return plan
+}
+
+// unitKeys returns the keys of the given units.
+func unitKeys(units []sources.WorkUnit) []string {
+	keys := make([]string, len(units))
+	for i, unit := range units {
+		keys[i] = unit.Key
+	}
+	return keys
+}
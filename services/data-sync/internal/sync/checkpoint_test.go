@@ -0,0 +1,106 @@
+package sync
+
+import (
+	"testing"
+
+	"github.com/dealforge/data-sync/internal/db"
+	"github.com/dealforge/data-sync/internal/sources"
+)
+
+func testUnits(keys ...string) []sources.WorkUnit {
+	units := make([]sources.WorkUnit, 0, len(keys))
+	for _, key := range keys {
+		units = append(units, sources.WorkUnit{Key: key, Name: key})
+	}
+	return units
+}
+
+func recordedUnits(statuses ...string) []*db.CheckpointUnit {
+	var units []*db.CheckpointUnit
+	for i := 0; i+1 < len(statuses); i += 2 {
+		units = append(units, &db.CheckpointUnit{UnitKey: statuses[i], Status: statuses[i+1]})
+	}
+	return units
+}
+
+func assertKeys(t *testing.T, label string, got []sources.WorkUnit, want ...string) {
+	t.Helper()
+
+	keys := unitKeys(got)
+	if len(keys) != len(want) {
+		t.Errorf("expected %s units %v, got %v", label, want, keys)
+		return
+	}
+	for i := range want {
+		if keys[i] != want[i] {
+			t.Errorf("expected %s units %v, got %v", label, want, keys)
+			return
+		}
+	}
+}
+
+func TestPlanResume_CompletedOutOfOrder(t *testing.T) {
+	// Concurrent workers finished the first and third units, but not the second
+	plan := planResume(
+		testUnits("48001", "48003", "48005"),
+		recordedUnits("48001", db.UnitCompleted, "48003", db.UnitPending, "48005", db.UnitCompleted),
+		ptr("48005"),
+	)
+
+	assertKeys(t, "pending", plan.pending, "48003")
+	assertKeys(t, "done", plan.done, "48001", "48005")
+	assertKeys(t, "untracked", plan.untracked)
+}
+
+func TestPlanResume_RetriesFailedUnits(t *testing.T) {
+	plan := planResume(
+		testUnits("48001", "48003", "48005", "48007"),
+		recordedUnits("48001", db.UnitFailed, "48003", db.UnitCompleted, "48005", db.UnitPending, "48007", db.UnitFailed),
+		nil,
+	)
+
+	assertKeys(t, "pending", plan.pending, "48001", "48005", "48007")
+	assertKeys(t, "done", plan.done, "48003")
+}
+
+func TestPlanResume_RetriesDeferredUnits(t *testing.T) {
+	// Units deferred by an upstream outage are picked up like pending ones
+	plan := planResume(
+		testUnits("48001", "48003", "48005"),
+		recordedUnits("48001", db.UnitCompleted, "48003", db.UnitDeferred, "48005", db.UnitDeferred),
+		nil,
+	)
+
+	assertKeys(t, "pending", plan.pending, "48003", "48005")
+	assertKeys(t, "done", plan.done, "48001")
+}
+
+func TestPlanResume_UntrackedUnits(t *testing.T) {
+	// A unit added since the session started has no status row yet
+	plan := planResume(
+		testUnits("48001", "48003", "48005"),
+		recordedUnits("48001", db.UnitCompleted, "48003", db.UnitCompleted),
+		ptr("48003"),
+	)
+
+	assertKeys(t, "pending", plan.pending, "48005")
+	assertKeys(t, "untracked", plan.untracked, "48005")
+}
+
+func TestPlanResume_LegacySession(t *testing.T) {
+	// Sessions from before per-unit tracking only recorded the last key
+	plan := planResume(testUnits("48001", "48003", "48005", "48007"), nil, ptr("48003"))
+
+	assertKeys(t, "pending", plan.pending, "48005", "48007")
+	assertKeys(t, "done", plan.done, "48001", "48003")
+	assertKeys(t, "untracked", plan.untracked, "48005", "48007")
+}
+
+func TestPlanResume_LegacySessionUnknownKey(t *testing.T) {
+	plan := planResume(testUnits("48001", "48003"), nil, ptr("99999"))
+
+	assertKeys(t, "pending", plan.pending, "48001", "48003")
+	assertKeys(t, "done", plan.done)
+}
+
+func ptr(s string) *string { return &s }
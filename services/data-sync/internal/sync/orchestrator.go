@@ -1,7 +1,9 @@
+// Package sync provides the orchestrator that drives registered data sources.
 package sync
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -11,316 +13,319 @@ import (
 	"golang.org/x/sync/semaphore"
 
 	"github.com/dealforge/data-sync/internal/db"
-	"github.com/dealforge/data-sync/internal/sources/bls"
-	"github.com/dealforge/data-sync/internal/sources/census"
-	"github.com/dealforge/data-sync/internal/sources/hud"
+	"github.com/dealforge/data-sync/internal/metrics"
+	"github.com/dealforge/data-sync/internal/ratelimit"
+	"github.com/dealforge/data-sync/internal/sources"
+	"github.com/dealforge/data-sync/internal/tracing"
 )
 
+// recordRunTimeout bounds writing a run's final checkpoint status and its
+// ledger entry, which happens even after the run's context has been
+// cancelled.
+const recordRunTimeout = 10 * time.Second
+
 // Orchestrator coordinates data syncing from multiple sources.
 type Orchestrator struct {
 	db            *db.Client
-	hudClient     *hud.Client
-	censusClient  *census.Client
-	blsClient     *bls.Client
 	maxConcurrent int64
-	maxRetries    int
 	dryRun        bool
+	waitForLock   bool
+	maxResumeAge  time.Duration
+	progress      ProgressFunc
 }
 
 // SyncResult contains statistics from a sync operation.
 type SyncResult struct {
-	Source       string
-	Successful   int
-	Failed       int
-	Skipped      int
-	Duration     time.Duration
-	Errors       []string
+	Source      string         `json:"source"`
+	SessionID   string         `json:"session_id,omitempty"` // Checkpoint session, for resumable sources
+	Params      sources.Params `json:"params"`               // Parameters the run used, after restoring a resumed session's
+	Vintage     string         `json:"vintage,omitempty"`    // Latest upstream vintage of the fetched records (see sources.Vintaged)
+	Successful  int            `json:"successful"`
+	Failed      int            `json:"failed"`
+	Skipped     int            `json:"skipped"`
+	RateLimited bool           `json:"rate_limited,omitempty"`         // Stopped early by the upstream quota
+	Unavailable bool           `json:"upstream_unavailable,omitempty"` // Stopped early by an upstream outage
+	Deferred    int            `json:"deferred,omitempty"`             // Units left for a resume by an outage
+	StartedAt   time.Time      `json:"started_at"`
+	Duration    time.Duration  `json:"duration"`
+	Errors      []string       `json:"errors,omitempty"`
 }
 
 // NewOrchestrator creates a new sync orchestrator.
-func NewOrchestrator(dbClient *db.Client, hudAPIKey, censusAPIKey, blsAPIKey string, maxConcurrent, maxRetries int, dryRun bool) *Orchestrator {
+func NewOrchestrator(dbClient *db.Client, maxConcurrent int, dryRun bool) *Orchestrator {
 	return &Orchestrator{
 		db:            dbClient,
-		hudClient:     hud.NewClient(hudAPIKey),
-		censusClient:  census.NewClient(censusAPIKey),
-		blsClient:     bls.NewClient(blsAPIKey),
 		maxConcurrent: int64(maxConcurrent),
-		maxRetries:    maxRetries,
 		dryRun:        dryRun,
+		maxResumeAge:  DefaultMaxResumeAge,
 	}
 }
 
-// SyncHUD syncs HUD Fair Market Rent data for the given state.
-// It fetches state-level data (all metro areas and non-metro counties) and upserts each record.
-func (o *Orchestrator) SyncHUD(ctx context.Context, stateCode string) (*SyncResult, error) {
-	start := time.Now()
-	result := &SyncResult{Source: "HUD FMR"}
-
-	if stateCode == "" {
-		stateCode = "TX" // Default to Texas
-	}
-
-	slog.Info("starting HUD FMR sync", "state", stateCode)
-
-	// Fetch state-level data (includes all metro areas and non-metro counties)
-	records, err := o.hudClient.GetFMRRecordsForState(ctx, stateCode)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch state FMR data: %w", err)
-	}
-
-	slog.Info("fetched HUD FMR records", "count", len(records))
-
-	if o.dryRun {
-		result.Successful = len(records)
-		result.Duration = time.Since(start)
-		slog.Info("dry run - skipping database upserts", "record_count", len(records))
-		return result, nil
-	}
+// OnProgress registers a function that receives progress snapshots from
+// subsequent Sync calls.
+func (o *Orchestrator) OnProgress(fn ProgressFunc) {
+	o.progress = fn
+}
 
-	// Upsert records concurrently
-	sem := semaphore.NewWeighted(o.maxConcurrent)
-	g, ctx := errgroup.WithContext(ctx)
+// WaitForLock makes subsequent Sync calls wait for another sync of the same
+// source to finish, instead of failing straight away with a *db.LockedError.
+func (o *Orchestrator) WaitForLock(wait bool) {
+	o.waitForLock = wait
+}
 
-	successCh := make(chan int, len(records))
-	failCh := make(chan string, len(records))
+// MaxResumeAge sets how old a session may be for ResumeLatest to continue
+// it. Zero disables the limit.
+func (o *Orchestrator) MaxResumeAge(maxAge time.Duration) {
+	o.maxResumeAge = maxAge
+}
 
-	for _, record := range records {
-		record := record // capture loop var
-		g.Go(func() error {
-			if err := sem.Acquire(ctx, 1); err != nil {
-				return err
-			}
-			defer sem.Release(1)
+// Sync runs a single source: it enumerates the source's work units, then
+// fetches and persists each one, bounded by the orchestrator's concurrency.
+//
+// Successful counts persisted records and Failed counts failed work units.
+// The status of every unit is tracked in the checkpoint. An exhausted quota
+// stops the run early and marks the checkpoint rate_limited; an open circuit
+// breaker stops it, defers the units not yet attempted and marks the
+// checkpoint upstream_unavailable.
+//
+// Pass resumeSessionID to continue an interrupted session: it processes
+// exactly the pending, failed and deferred units, with the parameters the
+// session was started with rather than p. Pass ResumeLatest to continue the
+// source's latest interrupted session, if it can be resumed, or start a
+// fresh session otherwise.
+//
+// Unless this is a dry run, the source's sync lock is held for the whole
+// run, so two processes never sync the same source against one database.
+func (o *Orchestrator) Sync(ctx context.Context, src sources.Source, p sources.Params, resumeSessionID string) (*SyncResult, error) {
+	name := src.Name()
+	result := &SyncResult{Source: name, StartedAt: time.Now()}
+
+	ctx, span := tracing.Start(ctx, "sync.run", tracing.Source.String(name))
+	defer span.End()
 
-			if err := o.db.UpsertHUDFMR(ctx, record); err != nil {
-				name := ""
-				if record.MetroName != nil {
-					name = *record.MetroName
-				} else if record.CountyName != nil {
-					name = *record.CountyName
-				}
-				slog.Warn("failed to upsert HUD FMR", "entity", name, "error", err)
-				failCh <- fmt.Sprintf("%s: %v", name, err)
-				return nil
+	if !o.dryRun {
+		lock, err := o.lock(ctx, name)
+		if err != nil {
+			tracing.Fail(span, err)
+			return nil, err
+		}
+		defer func() {
+			if err := lock.Release(context.Background()); err != nil {
+				slog.Warn("failed to release sync lock", "source", name, "error", err)
 			}
-
-			successCh <- 1
-			return nil
-		})
+		}()
 	}
 
-	if err := g.Wait(); err != nil {
-		return nil, err
-	}
-
-	close(successCh)
-	close(failCh)
-
-	for range successCh {
-		result.Successful++
-	}
-	for errMsg := range failCh {
-		result.Failed++
-		result.Errors = append(result.Errors, errMsg)
+	err := o.sync(ctx, src, p, resumeSessionID, result)
+	status := RunStatus(ctx, result, err)
+	if !o.dryRun {
+		o.recordRun(ctx, result, status, err)
+		observeRun(result, status)
 	}
 
-	result.Duration = time.Since(start)
-	slog.Info("completed HUD FMR sync",
-		"successful", result.Successful,
-		"failed", result.Failed,
-		"duration", result.Duration,
+	span.SetAttributes(
+		tracing.SessionID.String(result.SessionID),
+		tracing.Records.Int(result.Successful),
+		tracing.Status.String(status),
 	)
-
-	return result, nil
-}
-
-// SyncCensus syncs Census ACS data for all Texas counties.
-func (o *Orchestrator) SyncCensus(ctx context.Context, year int) (*SyncResult, error) {
-	start := time.Now()
-	result := &SyncResult{Source: "Census ACS"}
-
-	if year == 0 {
-		year = time.Now().Year() - 1 // Use previous year's data
-	}
-
-	counties := TexasCounties
-	slog.Info("starting Census ACS sync", "county_count", len(counties), "year", year)
-
-	sem := semaphore.NewWeighted(o.maxConcurrent)
-	g, ctx := errgroup.WithContext(ctx)
-
-	successCh := make(chan int, len(counties))
-	failCh := make(chan string, len(counties))
-
-	for _, county := range counties {
-		county := county // capture loop var
-		g.Go(func() error {
-			if err := sem.Acquire(ctx, 1); err != nil {
-				return err
-			}
-			defer sem.Release(1)
-
-			record, err := o.censusClient.GetCountyDemographics(ctx, county.FIPS, year)
-			if err != nil {
-				slog.Warn("failed to fetch Census data", "county", county.Name, "error", err)
-				failCh <- fmt.Sprintf("%s County: %v", county.Name, err)
-				return nil
-			}
-
-			if !o.dryRun {
-				if err := o.db.UpsertCensusDemographic(ctx, record); err != nil {
-					slog.Warn("failed to upsert Census data", "county", county.Name, "error", err)
-					failCh <- fmt.Sprintf("%s County DB: %v", county.Name, err)
-					return nil
-				}
-			}
-
-			successCh <- 1
-			return nil
-		})
-	}
-
-	if err := g.Wait(); err != nil {
+	if err != nil {
+		tracing.Fail(span, err)
 		return nil, err
 	}
-
-	close(successCh)
-	close(failCh)
-
-	for range successCh {
-		result.Successful++
-	}
-	for errMsg := range failCh {
-		result.Failed++
-		result.Errors = append(result.Errors, errMsg)
-	}
-
-	result.Duration = time.Since(start)
-	slog.Info("completed Census ACS sync",
-		"successful", result.Successful,
-		"failed", result.Failed,
-		"duration", result.Duration,
-	)
-
 	return result, nil
 }
 
-// SyncBLS syncs BLS LAUS data for all Texas counties with checkpoint support.
-// If the daily rate limit is reached, it will stop early and save a checkpoint.
-// Pass an optional resumeSessionID to continue from a previous checkpoint.
-func (o *Orchestrator) SyncBLS(ctx context.Context, startYear, endYear int, resumeSessionID string) (*SyncResult, error) {
-	start := time.Now()
-	result := &SyncResult{Source: "BLS LAUS"}
+// sync runs a source while its lock is held, filling in result as it goes.
+func (o *Orchestrator) sync(ctx context.Context, src sources.Source, p sources.Params, resumeSessionID string, result *SyncResult) error {
+	name := src.Name()
 
-	currentYear := time.Now().Year()
-	if endYear == 0 {
-		endYear = currentYear
-	}
-	if startYear == 0 {
-		startYear = endYear - 2 // Last 3 years by default
+	if resumeSessionID == ResumeLatest {
+		latest, err := o.latestSession(ctx, src, p)
+		if err != nil {
+			return err
+		}
+		resumeSessionID = latest
 	}
 
-	counties := TexasCounties
-
-	// Determine starting point based on resume session
-	startIdx := 0
-	var sessionID string
+	// A resumed session runs with the parameters it was started with
 	var checkpoint *db.SyncCheckpoint
-
 	if resumeSessionID != "" {
-		// Resuming from previous session
 		var err error
 		checkpoint, err = o.db.GetCheckpointBySession(ctx, resumeSessionID)
 		if err != nil {
-			return nil, fmt.Errorf("failed to load checkpoint: %w", err)
+			return fmt.Errorf("failed to load checkpoint: %w", err)
+		}
+		if checkpoint.Source != name {
+			return fmt.Errorf("checkpoint %s belongs to source %q, not %q", resumeSessionID, checkpoint.Source, name)
 		}
 
-		sessionID = resumeSessionID
-
-		// Find the index of the last completed county
-		if checkpoint.LastCompletedEntity != nil {
-			for i, county := range counties {
-				if county.FIPS == *checkpoint.LastCompletedEntity {
-					startIdx = i + 1 // Start from next county
-					break
-				}
+		restored, err := SessionParams(checkpoint)
+		if err != nil {
+			return err
+		}
+		if restored != nil {
+			if diffs := ResolveParams(src, p).Differences(ResolveParams(src, *restored)); len(diffs) > 0 {
+				slog.Info("restoring parameters of resumed session", "source", name, "session_id", resumeSessionID, "differences", diffs)
 			}
+			p = *restored
+		} else if checkpoint.StartYear != nil && checkpoint.EndYear != nil {
+			// Sessions that predate recorded parameters still keep their years
+			p.StartYear, p.EndYear = *checkpoint.StartYear, *checkpoint.EndYear
 		}
+	}
+	p = ResolveParams(src, p)
+	result.Params = p
 
-		slog.Info("resuming BLS LAUS sync from checkpoint",
-			"session_id", sessionID,
-			"last_completed", checkpoint.LastCompletedEntity,
-			"starting_at_index", startIdx,
-			"counties_remaining", len(counties)-startIdx,
-		)
-	} else {
-		// Starting new session
-		sessionID = fmt.Sprintf("bls_%d", time.Now().Unix())
+	units, err := src.WorkUnits(ctx, p)
+	if err != nil {
+		return fmt.Errorf("failed to enumerate %s work units: %w", name, err)
+	}
+
+	// Determine which units to process based on the resume session
+	pending := units
+	var sessionID string
+
+	if checkpoint != nil {
+		sessionID = checkpoint.SyncSessionID
+
+		recorded, err := o.db.GetCheckpointUnits(ctx, sessionID)
+		if err != nil {
+			return err
+		}
+		plan := planResume(units, recorded, checkpoint.LastCompletedEntity)
+		pending = plan.pending
 
 		if !o.dryRun {
-			var err error
-			checkpoint, err = o.db.CreateCheckpoint(ctx, sessionID, "bls")
-			if err != nil {
-				return nil, fmt.Errorf("failed to create checkpoint: %w", err)
+			// Record units the session has no status for yet, and reopen the
+			// session so progress is recorded against it again
+			if len(recorded) == 0 {
+				if err := o.db.AddCheckpointUnits(ctx, sessionID, unitKeys(plan.done), db.UnitCompleted); err != nil {
+					return err
+				}
+			}
+			if err := o.db.AddCheckpointUnits(ctx, sessionID, unitKeys(plan.untracked), db.UnitPending); err != nil {
+				return err
+			}
+			if checkpoint.Status != "in_progress" {
+				if err := o.db.UpdateCheckpointStatus(ctx, sessionID, "in_progress"); err != nil {
+					return fmt.Errorf("failed to reopen checkpoint: %w", err)
+				}
 			}
 		}
 
-		slog.Info("starting BLS LAUS sync",
+		slog.Info("resuming sync from checkpoint",
+			"source", name,
 			"session_id", sessionID,
-			"county_count", len(counties),
-			"start_year", startYear,
-			"end_year", endYear,
+			"units_completed", len(plan.done),
+			"units_remaining", len(pending),
 		)
+	} else if !o.dryRun {
+		sessionID = fmt.Sprintf("%s_%d", name, time.Now().Unix())
+		startYear, endYear := yearRange(src, p)
+		if _, err := o.db.CreateCheckpoint(ctx, sessionID, name, p, startYear, endYear); err != nil {
+			return fmt.Errorf("failed to create checkpoint: %w", err)
+		}
+		if err := o.db.AddCheckpointUnits(ctx, sessionID, unitKeys(units), db.UnitPending); err != nil {
+			return err
+		}
+	}
+
+	// Unit statuses are only written for real runs with a session
+	trackUnits := sessionID != "" && !o.dryRun
+
+	lag := metrics.CheckpointLag.WithLabelValues(name)
+	if trackUnits {
+		lag.Set(float64(len(pending)))
 	}
 
+	slog.Info("starting sync",
+		"source", name,
+		"session_id", sessionID,
+		"unit_count", len(pending),
+		"params", p,
+	)
+
+	o.logQuota(ctx, name, len(pending))
+
 	sem := semaphore.NewWeighted(o.maxConcurrent)
-	g, ctx := errgroup.WithContext(ctx)
+	g, gctx := errgroup.WithContext(ctx)
 
-	successCh := make(chan struct {
-		fips  string
-		count int
-	}, len(counties)*36) // ~36 months per county
-	failCh := make(chan string, len(counties))
+	successCh := make(chan fetched, len(pending))
+	failCh := make(chan string, len(pending))
+	tracker := newProgressTracker(o.progress, name, sessionID, len(pending))
 
-	// Only process counties from startIdx onwards
-	for i := startIdx; i < len(counties); i++ {
-		county := counties[i] // capture loop var
+	for _, unit := range pending {
 		g.Go(func() error {
-			if err := sem.Acquire(ctx, 1); err != nil {
+			if err := sem.Acquire(gctx, 1); err != nil {
 				return err
 			}
 			defer sem.Release(1)
 
-			records, err := o.blsClient.GetCountyEmploymentWithRetry(ctx, county.FIPS, county.Name, startYear, endYear, o.maxRetries)
+			// Requests made for the unit carry it, for logging
+			gctx, span := tracing.Start(sources.WithUnit(gctx, unit), "sync.unit",
+				tracing.Source.String(name),
+				tracing.Unit.String(unit.Key),
+				tracing.Entity.String(unit.Name),
+			)
+			defer span.End()
+
+			records, err := src.Fetch(gctx, unit, p)
 			if err != nil {
-				// If daily rate limit reached, return the error to cancel all goroutines
-				if errors.Is(err, bls.ErrDailyLimitReached) {
-					slog.Warn("BLS daily rate limit reached, stopping sync", "county", county.Name)
-					return bls.ErrDailyLimitReached
+				// If the upstream quota is exhausted, return the error to cancel all goroutines.
+				// The unit stays pending so a resume retries it.
+				tracing.Fail(span, err)
+				if errors.Is(err, sources.ErrQuotaExhausted) {
+					metrics.QuotaExhausted.WithLabelValues(name).Inc()
+					slog.Warn("upstream quota exhausted, stopping sync", "source", name, "unit", unit.Name)
+					return err
+				}
+				// Likewise when the upstream's circuit breaker opens: the remaining
+				// units are deferred rather than each failing against an outage
+				if errors.Is(err, sources.ErrUpstreamUnavailable) {
+					slog.Warn("upstream unavailable, stopping sync", "source", name, "unit", unit.Name, "error", err)
+					return err
+				}
+				// A unit cut off because the run is stopping stays pending
+				if gctx.Err() != nil && ctx.Err() == nil {
+					return err
+				}
+				slog.Warn("failed to fetch data", "source", name, "unit", unit.Name, "error", err)
+				failCh <- fmt.Sprintf("%s: %v", unit.Name, err)
+				tracker.unitDone(0, true)
+				if trackUnits {
+					o.failUnit(gctx, sessionID, unit, err)
 				}
-				slog.Warn("failed to fetch BLS data after retries", "county", county.Name, "error", err)
-				failCh <- fmt.Sprintf("%s County: %v", county.Name, err)
 				return nil
 			}
 
 			if !o.dryRun {
-				if err := o.db.BatchUpsertBLSEmployment(ctx, records); err != nil {
-					slog.Warn("failed to upsert BLS data", "county", county.Name, "error", err)
-					failCh <- fmt.Sprintf("%s County DB: %v", county.Name, err)
+				if err := src.Persist(gctx, o.db, records); err != nil {
+					slog.Warn("failed to persist data", "source", name, "unit", unit.Name, "error", err)
+					tracing.Fail(span, err)
+					failCh <- fmt.Sprintf("%s DB: %v", unit.Name, err)
+					tracker.unitDone(0, true)
+					if trackUnits {
+						o.failUnit(gctx, sessionID, unit, err)
+					}
 					return nil
 				}
 
-				// Save checkpoint after successful county
-				if err := o.db.UpdateCheckpoint(ctx, sessionID, county.FIPS, len(records)); err != nil {
-					slog.Warn("failed to update checkpoint", "county", county.Name, "error", err)
-					// Don't fail the sync for checkpoint errors
+				if trackUnits {
+					// Save checkpoint after successful unit
+					if err := o.db.CompleteCheckpointUnit(gctx, sessionID, unit.Key, records.Len()); err != nil {
+						slog.Warn("failed to update checkpoint unit", "source", name, "unit", unit.Name, "error", err)
+					}
+					if err := o.db.UpdateCheckpoint(gctx, sessionID, unit.Key, records.Len()); err != nil {
+						slog.Warn("failed to update checkpoint", "source", name, "unit", unit.Name, "error", err)
+						// Don't fail the sync for checkpoint errors
+					}
+					lag.Dec()
 				}
 			}
 
-			successCh <- struct {
-				fips  string
-				count int
-			}{county.FIPS, len(records)}
+			span.SetAttributes(tracing.Records.Int(records.Len()))
+			successCh <- fetched{records: records.Len(), vintage: vintageOf(records)}
+			tracker.unitDone(records.Len(), false)
 			return nil
 		})
 	}
@@ -330,79 +335,243 @@ func (o *Orchestrator) SyncBLS(ctx context.Context, startYear, endYear int, resu
 	close(successCh)
 	close(failCh)
 
-	for data := range successCh {
-		result.Successful += data.count
+	attempted := 0
+	for unit := range successCh {
+		result.Successful += unit.records
+		result.Vintage = max(result.Vintage, unit.vintage)
+		attempted++
 	}
 	for errMsg := range failCh {
 		result.Failed++
 		result.Errors = append(result.Errors, errMsg)
+		attempted++
 	}
 
-	result.Duration = time.Since(start)
+	result.Duration = time.Since(result.StartedAt)
+	result.SessionID = sessionID
 
-	// Update checkpoint status based on outcome
-	if !o.dryRun {
-		if errors.Is(waitErr, bls.ErrDailyLimitReached) {
-			// Rate limit hit - mark as rate_limited for easy resumption
-			if err := o.db.UpdateCheckpointStatus(ctx, sessionID, "rate_limited"); err != nil {
-				slog.Warn("failed to update checkpoint status", "error", err)
-			}
-			result.Errors = append(result.Errors, fmt.Sprintf("BLS API daily rate limit reached - sync stopped early. Resume with: --resume=%s", sessionID))
-			slog.Warn("BLS LAUS sync stopped early due to daily rate limit",
-				"session_id", sessionID,
-				"successful_records", result.Successful,
-				"failed_counties", result.Failed,
-				"duration", result.Duration,
-			)
-			return result, nil // Return partial results, not an error
-		} else if waitErr != nil {
-			// Some other error occurred
-			if err := o.db.UpdateCheckpointStatus(ctx, sessionID, "failed"); err != nil {
-				slog.Warn("failed to update checkpoint status", "error", err)
-			}
-			return nil, waitErr
+	switch {
+	case errors.Is(waitErr, sources.ErrQuotaExhausted):
+		result.RateLimited = true
+		if sessionID != "" {
+			// Quota hit - mark as rate_limited for easy resumption
+			o.setCheckpointStatus(ctx, sessionID, "rate_limited")
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v - sync stopped early. Resume with: --resume=%s", name, waitErr, sessionID))
 		} else {
-			// Sync completed successfully
-			if err := o.db.UpdateCheckpointStatus(ctx, sessionID, "completed"); err != nil {
-				slog.Warn("failed to update checkpoint status", "error", err)
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v - sync stopped early", name, waitErr))
+		}
+		slog.Warn("sync stopped early due to upstream quota",
+			"source", name,
+			"session_id", sessionID,
+			"successful_records", result.Successful,
+			"failed_units", result.Failed,
+			"duration", result.Duration,
+		)
+		return nil // Return partial results, not an error
+	case errors.Is(waitErr, sources.ErrUpstreamUnavailable):
+		// One summarising error instead of a failure for every remaining unit
+		result.Unavailable = true
+		result.Deferred = len(pending) - attempted
+		if trackUnits {
+			if err := o.db.DeferCheckpointUnits(ctx, sessionID); err != nil {
+				slog.Warn("failed to defer checkpoint units", "source", name, "session_id", sessionID, "error", err)
 			}
 		}
+		if sessionID != "" {
+			o.setCheckpointStatus(ctx, sessionID, "upstream_unavailable")
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v - %d units deferred. Resume with: --resume=%s", name, waitErr, result.Deferred, sessionID))
+		} else {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v - %d units deferred", name, waitErr, result.Deferred))
+		}
+		slog.Warn("sync stopped early, upstream unavailable",
+			"source", name,
+			"session_id", sessionID,
+			"successful_records", result.Successful,
+			"failed_units", result.Failed,
+			"deferred_units", result.Deferred,
+			"duration", result.Duration,
+		)
+		return nil
+	case waitErr != nil:
+		o.setCheckpointStatus(ctx, sessionID, "failed")
+		return waitErr
+	default:
+		o.setCheckpointStatus(ctx, sessionID, "completed")
 	}
 
-	slog.Info("completed BLS LAUS sync",
+	slog.Info("completed sync",
+		"source", name,
 		"session_id", sessionID,
 		"successful_records", result.Successful,
-		"failed_counties", result.Failed,
+		"failed_units", result.Failed,
 		"duration", result.Duration,
 	)
 
-	return result, nil
+	return nil
 }
 
-// SyncAll runs sync for all data sources.
-func (o *Orchestrator) SyncAll(ctx context.Context, stateCode string, censusYear, blsStartYear, blsEndYear int) ([]*SyncResult, error) {
-	var results []*SyncResult
+// logQuota reports how much of the source's daily upstream quota is left
+// for a run of the given number of units.
+func (o *Orchestrator) logQuota(ctx context.Context, source string, units int) {
+	limiter := ratelimit.Lookup(source)
+	if limiter == nil {
+		return
+	}
 
-	// Run HUD sync
-	hudResult, err := o.SyncHUD(ctx, stateCode)
+	remaining, err := limiter.Remaining(ctx)
 	if err != nil {
-		return results, fmt.Errorf("HUD sync failed: %w", err)
+		slog.Warn("failed to check upstream daily quota", "source", source, "error", err)
+		return
+	}
+	if remaining == ratelimit.Unlimited {
+		return
+	}
+
+	if remaining < units {
+		slog.Warn("upstream daily quota is smaller than the run, it will stop rate limited and can be resumed",
+			"source", source,
+			"quota_remaining", remaining,
+			"unit_count", units,
+		)
+		return
+	}
+	slog.Info("upstream daily quota", "source", source, "quota_remaining", remaining, "unit_count", units)
+}
+
+// fetched is the outcome of a successfully synced unit.
+type fetched struct {
+	records int
+	vintage string
+}
+
+// vintageOf returns the upstream vintage of a unit's records, or "" if they
+// do not report one.
+func vintageOf(records sources.Records) string {
+	if v, ok := records.(sources.Vintaged); ok {
+		return v.Vintage()
 	}
-	results = append(results, hudResult)
+	return ""
+}
 
-	// Run Census sync
-	censusResult, err := o.SyncCensus(ctx, censusYear)
+// recordRun writes the outcome of a run to the sync run ledger. Failing to
+// record it does not fail the run.
+func (o *Orchestrator) recordRun(ctx context.Context, result *SyncResult, status string, syncErr error) {
+	run := &db.SyncRun{
+		Source:     result.Source,
+		Status:     status,
+		Successful: result.Successful,
+		Failed:     result.Failed,
+		Skipped:    result.Skipped,
+		Errors:     result.Errors,
+		StartedAt:  result.StartedAt,
+		FinishedAt: time.Now(),
+	}
+	if result.SessionID != "" {
+		run.SyncSessionID = &result.SessionID
+	}
+	if result.Vintage != "" {
+		run.Vintage = &result.Vintage
+	}
+	if syncErr != nil {
+		msg := syncErr.Error()
+		run.ErrorMessage = &msg
+	}
+
+	params, err := json.Marshal(result.Params)
 	if err != nil {
-		return results, fmt.Errorf("Census sync failed: %w", err)
+		slog.Warn("failed to encode sync run params", "source", result.Source, "error", err)
+	}
+	run.Params = params
+
+	// The run's context may already be cancelled; record the outcome anyway
+	recordCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), recordRunTimeout)
+	defer cancel()
+
+	if err := o.db.RecordSyncRun(recordCtx, run); err != nil {
+		slog.Warn("failed to record sync run", "source", result.Source, "error", err)
 	}
-	results = append(results, censusResult)
+}
+
+// observeRun records the outcome of a run in the run metrics.
+func observeRun(result *SyncResult, status string) {
+	metrics.Runs.WithLabelValues(result.Source, status).Inc()
+	metrics.RunDuration.WithLabelValues(result.Source).Observe(time.Since(result.StartedAt).Seconds())
+	if status == db.RunCompleted {
+		metrics.LastSuccess.WithLabelValues(result.Source).SetToCurrentTime()
+	}
+}
 
-	// Run BLS sync (no resume - fresh start)
-	blsResult, err := o.SyncBLS(ctx, blsStartYear, blsEndYear, "")
+// RunStatus classifies the outcome of a Sync call as one of the db.Run*
+// statuses recorded in the sync run ledger. result may be nil if err is set.
+func RunStatus(ctx context.Context, result *SyncResult, syncErr error) string {
+	switch {
+	case syncErr != nil && ctx.Err() != nil:
+		return db.RunCancelled
+	case syncErr != nil:
+		return db.RunFailed
+	case result.RateLimited:
+		return db.RunRateLimited
+	case result.Unavailable:
+		return db.RunUnavailable
+	case result.Failed > 0:
+		return db.RunPartial
+	default:
+		return db.RunCompleted
+	}
+}
+
+// latestSession returns the session ResumeLatest continues, or "" to start
+// a fresh one.
+func (o *Orchestrator) latestSession(ctx context.Context, src sources.Source, p sources.Params) (string, error) {
+	checkpoint, err := LatestResumable(ctx, o.db, src, p, o.maxResumeAge)
+	if errors.Is(err, ErrResumeRefused) {
+		slog.Warn("not resuming latest session, starting a fresh one", "source", src.Name(), "reason", err)
+		return "", nil
+	}
 	if err != nil {
-		return results, fmt.Errorf("BLS sync failed: %w", err)
+		return "", fmt.Errorf("failed to find latest session: %w", err)
+	}
+	if checkpoint == nil {
+		slog.Info("no interrupted session to resume, starting a fresh one", "source", src.Name())
+		return "", nil
+	}
+	return checkpoint.SyncSessionID, nil
+}
+
+// failUnit records a failed unit so that a resume retries it.
+func (o *Orchestrator) failUnit(ctx context.Context, sessionID string, unit sources.WorkUnit, unitErr error) {
+	if err := o.db.FailCheckpointUnit(ctx, sessionID, unit.Key, unitErr.Error()); err != nil {
+		slog.Warn("failed to update checkpoint unit", "unit", unit.Name, "error", err)
+	}
+}
+
+// setCheckpointStatus records the final status of a checkpointed session.
+// It is a no-op for runs without a session or in dry-run mode.
+func (o *Orchestrator) setCheckpointStatus(ctx context.Context, sessionID, status string) {
+	if sessionID == "" || o.dryRun {
+		return
+	}
+
+	// A failed run's context is usually cancelled (Ctrl-C, a server cancel);
+	// record the status anyway so the session does not stay in progress
+	statusCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), recordRunTimeout)
+	defer cancel()
+
+	if err := o.db.UpdateCheckpointStatus(statusCtx, sessionID, status); err != nil {
+		slog.Warn("failed to update checkpoint status", "session_id", sessionID, "error", err)
+	}
+}
+
+// lock takes the sync lock for a source, waiting for the current holder if
+// the orchestrator is configured to.
+func (o *Orchestrator) lock(ctx context.Context, source string) (*db.SyncLock, error) {
+	lock, err := o.db.TryLockSource(ctx, source)
+
+	var locked *db.LockedError
+	if !o.waitForLock || !errors.As(err, &locked) {
+		return lock, err
 	}
-	results = append(results, blsResult)
 
-	return results, nil
+	slog.Info("waiting for another sync of this source to finish", "source", source, "holder", locked.Holder)
+	return o.db.LockSource(ctx, source)
 }
@@ -0,0 +1,127 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/dealforge/data-sync/internal/db"
+	"github.com/dealforge/data-sync/internal/sources"
+)
+
+func TestRunStatus(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		result *SyncResult
+		err    error
+		want   string
+	}{
+		{"completed", context.Background(), &SyncResult{Successful: 10}, nil, db.RunCompleted},
+		{"some units failed", context.Background(), &SyncResult{Successful: 10, Failed: 1}, nil, db.RunPartial},
+		{"rate limited", context.Background(), &SyncResult{Failed: 1, RateLimited: true}, nil, db.RunRateLimited},
+		{"upstream unavailable", context.Background(), &SyncResult{Failed: 1, Unavailable: true}, nil, db.RunUnavailable},
+		{"failed", context.Background(), &SyncResult{}, errors.New("boom"), db.RunFailed},
+		{"cancelled", cancelled, &SyncResult{}, context.Canceled, db.RunCancelled},
+	}
+
+	for _, tt := range tests {
+		if got := RunStatus(tt.ctx, tt.result, tt.err); got != tt.want {
+			t.Errorf("%s: expected status %q, got %q", tt.name, tt.want, got)
+		}
+	}
+}
+
+// outageSource fetches its first units, after which its upstream goes down.
+type outageSource struct {
+	stubSource
+	units, healthy int
+	fetched        int
+}
+
+type countRecords int
+
+func (r countRecords) Len() int { return int(r) }
+
+func (s *outageSource) WorkUnits(ctx context.Context, p sources.Params) ([]sources.WorkUnit, error) {
+	units := make([]sources.WorkUnit, s.units)
+	for i := range units {
+		units[i] = sources.WorkUnit{Key: fmt.Sprint(i), Name: fmt.Sprintf("unit %d", i)}
+	}
+	return units, nil
+}
+
+func (s *outageSource) Fetch(ctx context.Context, unit sources.WorkUnit, p sources.Params) (sources.Records, error) {
+	s.fetched++
+	if s.fetched > s.healthy {
+		return nil, fmt.Errorf("outage circuit open: %w", sources.ErrUpstreamUnavailable)
+	}
+	return countRecords(3), nil
+}
+
+func TestSync_UpstreamUnavailableDefersRemainingUnits(t *testing.T) {
+	src := &outageSource{stubSource: stubSource{name: "outage"}, units: 200, healthy: 2}
+
+	result, err := NewOrchestrator(nil, 1, true).Sync(context.Background(), src, sources.Params{}, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !result.Unavailable {
+		t.Error("expected the run to be marked upstream unavailable")
+	}
+	if result.Successful != 6 || result.Failed != 0 {
+		t.Errorf("expected 6 records and no failed units, got %d and %d", result.Successful, result.Failed)
+	}
+	if result.Deferred != 198 {
+		t.Errorf("expected 198 deferred units, got %d", result.Deferred)
+	}
+	// The outage is reported once, not once per remaining unit
+	if len(result.Errors) != 1 {
+		t.Errorf("expected a single error, got %d: %v", len(result.Errors), result.Errors)
+	}
+	if src.fetched > 10 {
+		t.Errorf("expected fetching to stop at the open circuit, got %d fetches", src.fetched)
+	}
+}
+
+// vintagedSource returns records of a different vintage for each unit, and
+// fails the test if its upstream vintage is checked.
+type vintagedSource struct {
+	stubSource
+	t *testing.T
+}
+
+type vintagedRecords string
+
+func (r vintagedRecords) Len() int        { return 1 }
+func (r vintagedRecords) Vintage() string { return string(r) }
+
+func (s *vintagedSource) WorkUnits(ctx context.Context, p sources.Params) ([]sources.WorkUnit, error) {
+	return []sources.WorkUnit{{Key: "a"}, {Key: "b"}, {Key: "c"}}, nil
+}
+
+func (s *vintagedSource) Fetch(ctx context.Context, unit sources.WorkUnit, p sources.Params) (sources.Records, error) {
+	return vintagedRecords(map[string]string{"a": "2024-07", "b": "2024-08", "c": ""}[unit.Key]), nil
+}
+
+func (s *vintagedSource) LatestVintage(ctx context.Context, p sources.Params) (string, error) {
+	s.t.Error("expected the vintage to come from the fetched records, not another upstream request")
+	return "", nil
+}
+
+func TestSync_VintageFromFetchedRecords(t *testing.T) {
+	src := &vintagedSource{stubSource: stubSource{name: "vintaged"}, t: t}
+
+	result, err := NewOrchestrator(nil, 2, true).Sync(context.Background(), src, sources.Params{}, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Vintage != "2024-08" {
+		t.Errorf("expected the latest fetched vintage 2024-08, got %q", result.Vintage)
+	}
+}
@@ -0,0 +1,54 @@
+package sync
+
+import "sync"
+
+// Progress is a snapshot of a source sync in progress.
+type Progress struct {
+	Source      string `json:"source"`
+	SessionID   string `json:"session_id,omitempty"`
+	TotalUnits  int    `json:"total_units"`
+	DoneUnits   int    `json:"done_units"`
+	FailedUnits int    `json:"failed_units"`
+	Records     int    `json:"records"`
+}
+
+// ProgressFunc receives a progress snapshot when a sync starts and after
+// every work unit finishes. It may be called from several goroutines, but
+// never concurrently.
+type ProgressFunc func(Progress)
+
+// progressTracker counts finished units and reports them to a ProgressFunc.
+type progressTracker struct {
+	mu       sync.Mutex
+	progress Progress
+	fn       ProgressFunc
+}
+
+func newProgressTracker(fn ProgressFunc, source, sessionID string, totalUnits int) *progressTracker {
+	t := &progressTracker{
+		progress: Progress{Source: source, SessionID: sessionID, TotalUnits: totalUnits},
+		fn:       fn,
+	}
+	t.report()
+	return t
+}
+
+// unitDone records a finished unit and reports the new totals.
+func (t *progressTracker) unitDone(records int, failed bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.progress.DoneUnits++
+	if failed {
+		t.progress.FailedUnits++
+	}
+	t.progress.Records += records
+	t.report()
+}
+
+// report sends the current totals to the ProgressFunc, if any.
+func (t *progressTracker) report() {
+	if t.fn != nil {
+		t.fn(t.progress)
+	}
+}
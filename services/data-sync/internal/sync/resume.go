@@ -0,0 +1,144 @@
+package sync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dealforge/data-sync/internal/db"
+	"github.com/dealforge/data-sync/internal/sources"
+)
+
+// ResumeLatest is passed as the resume session to continue a source's most
+// recent interrupted session, if it has one.
+const ResumeLatest = "latest"
+
+// DefaultMaxResumeAge is how old a session may be before ResumeLatest
+// refuses it and starts a fresh one instead.
+const DefaultMaxResumeAge = 7 * 24 * time.Hour
+
+// ErrResumeRefused is returned (wrapped) by LatestResumable when the latest
+// interrupted session cannot be continued with the requested parameters.
+var ErrResumeRefused = errors.New("session cannot be resumed")
+
+// LatestResumable returns the latest session of a source if it was
+// interrupted (rate_limited, upstream_unavailable or in_progress), or nil if
+// it finished or there is none. Sessions started more than maxAge ago, or
+// with different parameters or a different year range than p, are refused
+// with ErrResumeRefused.
+func LatestResumable(ctx context.Context, store *db.Client, src sources.Source, p sources.Params, maxAge time.Duration) (*db.SyncCheckpoint, error) {
+	checkpoint, err := store.GetLastCheckpointForSource(ctx, src.Name())
+	if err != nil {
+		return nil, err
+	}
+	if checkpoint == nil || !interrupted(checkpoint) {
+		return nil, nil
+	}
+
+	if err := checkResumable(checkpoint, src, p, maxAge, time.Now()); err != nil {
+		return nil, err
+	}
+	return checkpoint, nil
+}
+
+// SessionParams decodes the parameters a session was started with, or
+// returns nil for sessions that predate recording them.
+func SessionParams(checkpoint *db.SyncCheckpoint) (*sources.Params, error) {
+	if len(checkpoint.Params) == 0 || string(checkpoint.Params) == "null" {
+		return nil, nil
+	}
+
+	var p sources.Params
+	if err := json.Unmarshal(checkpoint.Params, &p); err != nil {
+		return nil, fmt.Errorf("failed to decode parameters of session %s: %w", checkpoint.SyncSessionID, err)
+	}
+	return &p, nil
+}
+
+// interrupted reports whether a session stopped before finishing.
+func interrupted(checkpoint *db.SyncCheckpoint) bool {
+	switch checkpoint.Status {
+	case "rate_limited", "upstream_unavailable", "in_progress":
+		return true
+	default:
+		return false
+	}
+}
+
+// checkResumable refuses sessions that are too old, that were started with
+// different parameters (of those the source uses), or whose year range
+// differs from the one p resolves to. Sessions that predate recorded
+// parameters only have their year range checked.
+func checkResumable(checkpoint *db.SyncCheckpoint, src sources.Source, p sources.Params, maxAge time.Duration, now time.Time) error {
+	if age := now.Sub(checkpoint.StartedAt); maxAge > 0 && age > maxAge {
+		return fmt.Errorf("%w: %s started %s ago, more than the maximum of %s",
+			ErrResumeRefused, checkpoint.SyncSessionID, age.Round(time.Minute), maxAge)
+	}
+
+	p = ResolveParams(src, p)
+
+	stored, err := SessionParams(checkpoint)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrResumeRefused, err)
+	}
+	if stored != nil {
+		if diffs := p.Differences(ResolveParams(src, *stored)); len(diffs) > 0 {
+			return fmt.Errorf("%w: %s was started with different %s",
+				ErrResumeRefused, checkpoint.SyncSessionID, strings.Join(diffs, ", "))
+		}
+	}
+
+	startYear, endYear := yearRange(src, p)
+	if !sameYear(checkpoint.StartYear, startYear) || !sameYear(checkpoint.EndYear, endYear) {
+		return fmt.Errorf("%w: %s covers years %s-%s, not the requested %s-%s",
+			ErrResumeRefused, checkpoint.SyncSessionID,
+			formatYear(checkpoint.StartYear), formatYear(checkpoint.EndYear),
+			formatYear(startYear), formatYear(endYear))
+	}
+
+	return nil
+}
+
+// ResolveParams returns the parameters a run of src with p actually uses:
+// the parameters a sources.Scoped source ignores are cleared, and its
+// defaults and the year range of a sources.YearRanged source are filled in.
+// A session records (and a resume restores and compares) the resolved
+// parameters, so neither a default that moves with the calendar nor a flag
+// the source ignores sets two entry points' runs apart.
+func ResolveParams(src sources.Source, p sources.Params) sources.Params {
+	if scoped, ok := src.(sources.Scoped); ok {
+		p = scoped.RelevantParams(p)
+	}
+	if ranged, ok := src.(sources.YearRanged); ok {
+		p.StartYear, p.EndYear = ranged.YearRange(p)
+	}
+	return p
+}
+
+// yearRange returns the year range a run of src with p covers, or nils if
+// the source does not sync a range of years.
+func yearRange(src sources.Source, p sources.Params) (startYear, endYear *int) {
+	ranged, ok := src.(sources.YearRanged)
+	if !ok {
+		return nil, nil
+	}
+	start, end := ranged.YearRange(p)
+	return &start, &end
+}
+
+func sameYear(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func formatYear(year *int) string {
+	if year == nil {
+		return "?"
+	}
+	return fmt.Sprint(*year)
+}
@@ -0,0 +1,201 @@
+package sync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dealforge/data-sync/internal/db"
+	"github.com/dealforge/data-sync/internal/geography"
+	"github.com/dealforge/data-sync/internal/sources"
+	"github.com/dealforge/data-sync/internal/sources/bls"
+	"github.com/dealforge/data-sync/internal/sources/census"
+	"github.com/dealforge/data-sync/internal/sources/hud"
+)
+
+type stubSource struct{ name string }
+
+func (s *stubSource) Name() string             { return s.name }
+func (s *stubSource) RequiredConfig() []string { return nil }
+func (s *stubSource) WorkUnits(ctx context.Context, p sources.Params) ([]sources.WorkUnit, error) {
+	return nil, nil
+}
+func (s *stubSource) Fetch(ctx context.Context, unit sources.WorkUnit, p sources.Params) (sources.Records, error) {
+	return nil, nil
+}
+func (s *stubSource) Persist(ctx context.Context, store *db.Client, records sources.Records) error {
+	return nil
+}
+
+// rangedSource syncs the requested years, defaulting to 2022-2024.
+type rangedSource struct{ stubSource }
+
+func (s *rangedSource) YearRange(p sources.Params) (int, int) {
+	start, end := p.StartYear, p.EndYear
+	if start == 0 {
+		start = 2022
+	}
+	if end == 0 {
+		end = 2024
+	}
+	return start, end
+}
+
+func intPtr(n int) *int { return &n }
+
+func TestSessionParams(t *testing.T) {
+	p, err := SessionParams(&db.SyncCheckpoint{})
+	if err != nil || p != nil {
+		t.Errorf("expected no params for a legacy session, got %+v (error %v)", p, err)
+	}
+
+	p, err = SessionParams(&db.SyncCheckpoint{Params: []byte(`{"states":["OK"],"start_year":2021,"end_year":2023}`)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(p.States) != 1 || p.States[0].Code != "OK" || p.StartYear != 2021 || p.EndYear != 2023 {
+		t.Errorf("unexpected params: %+v", p)
+	}
+}
+
+func TestCheckResumable(t *testing.T) {
+	now := time.Date(2024, 8, 14, 12, 0, 0, 0, time.UTC)
+	bls := &rangedSource{stubSource{"bls"}}
+	hud := &stubSource{"hud"}
+
+	tests := []struct {
+		name       string
+		checkpoint *db.SyncCheckpoint
+		src        sources.Source
+		params     sources.Params
+		maxAge     time.Duration
+		refused    bool
+	}{
+		{
+			name:       "same default range",
+			checkpoint: &db.SyncCheckpoint{StartYear: intPtr(2022), EndYear: intPtr(2024), StartedAt: now.Add(-time.Hour)},
+			src:        bls,
+		},
+		{
+			name:       "different range",
+			checkpoint: &db.SyncCheckpoint{StartYear: intPtr(2022), EndYear: intPtr(2024), StartedAt: now.Add(-time.Hour)},
+			src:        bls,
+			params:     sources.Params{StartYear: 2020},
+			refused:    true,
+		},
+		{
+			name:       "session without a range",
+			checkpoint: &db.SyncCheckpoint{StartedAt: now.Add(-time.Hour)},
+			src:        bls,
+			refused:    true,
+		},
+		{
+			name:       "source without a range",
+			checkpoint: &db.SyncCheckpoint{StartedAt: now.Add(-time.Hour)},
+			src:        hud,
+		},
+		{
+			name:       "same recorded params",
+			checkpoint: &db.SyncCheckpoint{Params: []byte(`{"states":["TX"],"start_year":2022,"end_year":2024}`), StartYear: intPtr(2022), EndYear: intPtr(2024), StartedAt: now.Add(-time.Hour)},
+			src:        bls,
+		},
+		{
+			name:       "different recorded states",
+			checkpoint: &db.SyncCheckpoint{Params: []byte(`{"states":["OK"],"start_year":2022,"end_year":2024}`), StartYear: intPtr(2022), EndYear: intPtr(2024), StartedAt: now.Add(-time.Hour)},
+			src:        bls,
+			refused:    true,
+		},
+		{
+			name:       "too old",
+			checkpoint: &db.SyncCheckpoint{StartedAt: now.Add(-72 * time.Hour)},
+			src:        hud,
+			maxAge:     48 * time.Hour,
+			refused:    true,
+		},
+		{
+			name:       "no age limit",
+			checkpoint: &db.SyncCheckpoint{StartedAt: now.Add(-720 * time.Hour)},
+			src:        hud,
+		},
+	}
+
+	for _, tt := range tests {
+		err := checkResumable(tt.checkpoint, tt.src, tt.params, tt.maxAge, now)
+		if tt.refused && !errors.Is(err, ErrResumeRefused) {
+			t.Errorf("%s: expected ErrResumeRefused, got %v", tt.name, err)
+		}
+		if !tt.refused && err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+		}
+	}
+}
+
+// startedSession returns the checkpoint a run of src with p would record.
+func startedSession(t *testing.T, src sources.Source, p sources.Params, now time.Time) *db.SyncCheckpoint {
+	t.Helper()
+	resolved := ResolveParams(src, p)
+	data, err := json.Marshal(resolved)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	startYear, endYear := yearRange(src, resolved)
+	return &db.SyncCheckpoint{SyncSessionID: src.Name() + "_1", Params: data, StartYear: startYear, EndYear: endYear, StartedAt: now.Add(-time.Hour)}
+}
+
+func TestCheckResumable_AcrossEntryPoints(t *testing.T) {
+	now := time.Now()
+	texas, err := geography.ParseStateList("TX")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// What each entry point passes for a default run of Texas
+	scheduler := sources.Params{States: texas}
+	server := sources.Params{States: texas}
+	cli := sources.Params{States: texas, GeoLevel: "county"}
+	cliWithOtherFlags := sources.Params{States: texas, GeoLevel: "county", Year: 2019, ZIPs: []string{"78201"}, Counties: []string{"48029"}}
+
+	tests := []struct {
+		name    string
+		src     sources.Source
+		started sources.Params
+		resumed sources.Params
+		refused bool
+	}{
+		{"bls from scheduler to CLI", &bls.Source{}, scheduler, cli, false},
+		{"bls from CLI to server", &bls.Source{}, cli, server, false},
+		{"bls ignores census and ZIP flags", &bls.Source{}, scheduler, cliWithOtherFlags, false},
+		{"hud ignores census flags", &hud.Source{}, scheduler, sources.Params{States: texas, GeoLevel: "county", Year: 2019}, false},
+		{"hud still compares ZIPs", &hud.Source{}, scheduler, cliWithOtherFlags, true},
+		{"census from server to CLI", &census.Source{}, server, cli, false},
+		{"census from CLI to scheduler", &census.Source{}, cli, scheduler, false},
+		{"census still compares its year", &census.Source{}, scheduler, cliWithOtherFlags, true},
+	}
+
+	for _, tt := range tests {
+		checkpoint := startedSession(t, tt.src, tt.started, now)
+		err := checkResumable(checkpoint, tt.src, tt.resumed, 0, now)
+		if tt.refused && !errors.Is(err, ErrResumeRefused) {
+			t.Errorf("%s: expected ErrResumeRefused, got %v", tt.name, err)
+		}
+		if !tt.refused && err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+		}
+	}
+}
+
+func TestResolveParams_ResolvesCensusYear(t *testing.T) {
+	resolved := ResolveParams(&census.Source{}, sources.Params{})
+	if resolved.Year == 0 {
+		t.Error("expected the default survey year to be resolved before it is recorded")
+	}
+	if resolved.GeoLevel != census.GeoCounty {
+		t.Errorf("expected the default geography level %q, got %q", census.GeoCounty, resolved.GeoLevel)
+	}
+
+	if resolved := ResolveParams(&census.Source{}, sources.Params{Year: 2021}); resolved.Year != 2021 {
+		t.Errorf("expected an explicit survey year to be kept, got %d", resolved.Year)
+	}
+}
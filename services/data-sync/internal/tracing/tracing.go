@@ -0,0 +1,152 @@
+// Package tracing sets up OpenTelemetry tracing for the data sync service
+// and provides the spans and attributes shared by its packages.
+//
+// Until Setup installs an exporter, spans are no-ops.
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Trace exporters.
+const (
+	ExporterNone = "none" // Tracing disabled
+	ExporterOTLP = "otlp" // OTLP over HTTP, configured by the standard OTEL_EXPORTER_OTLP_* variables
+	ExporterFile = "file" // Spans appended to a file as JSON, for offline debugging
+)
+
+const serviceName = "data-sync"
+
+var tracer = otel.Tracer("github.com/dealforge/data-sync")
+
+// Span attribute keys.
+const (
+	Source    = attribute.Key("datasync.source")
+	SessionID = attribute.Key("datasync.session_id")
+	Unit      = attribute.Key("datasync.unit")   // Work unit key, e.g. a state or county FIPS code
+	Entity    = attribute.Key("datasync.entity") // Work unit display name
+	Records   = attribute.Key("datasync.records")
+	Status    = attribute.Key("datasync.status")
+	Table     = attribute.Key("db.collection.name")
+	Bytes     = attribute.Key("datasync.bytes")
+)
+
+// Setup installs the global tracer provider for an exporter ("" or
+// ExporterNone disables tracing). path is the output file of ExporterFile.
+// The returned function flushes pending spans and must be called before the
+// process exits; calling it more than once is safe.
+func Setup(ctx context.Context, exporter, path string) (func(context.Context) error, error) {
+	var (
+		exp  sdktrace.SpanExporter
+		file *os.File
+		err  error
+	)
+
+	switch exporter {
+	case "", ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterOTLP:
+		exp, err = otlptracehttp.New(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OTLP trace exporter: %w", err)
+		}
+	case ExporterFile:
+		file, err = os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open trace file: %w", err)
+		}
+		exp, err = stdouttrace.New(stdouttrace.WithWriter(file))
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to create file trace exporter: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q (expected %s, %s or %s)", exporter, ExporterNone, ExporterOTLP, ExporterFile)
+	}
+
+	// OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES override the defaults
+	res, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceName(serviceName)),
+		resource.WithTelemetrySDK(),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build trace resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exp),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+
+	closed := false
+	return func(ctx context.Context) error {
+		if closed {
+			return nil
+		}
+		closed = true
+
+		err := provider.Shutdown(ctx)
+		if file != nil {
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+		}
+		return err
+	}, nil
+}
+
+// Start starts a span as a child of any span in ctx.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// Fail marks a span as failed with err.
+func Fail(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// Transport traces each request made through an HTTP transport with a
+// client span tagged with the source. A nil base uses http.DefaultTransport.
+// The span ends once the response body is read and closed.
+func Transport(source string, base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return otelhttp.NewTransport(base,
+		otelhttp.WithSpanOptions(trace.WithAttributes(Source.String(source))),
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return fmt.Sprintf("%s %s", r.Method, r.URL.Host)
+		}),
+	)
+}
+
+// DecodeJSON unmarshals an upstream response body in a "parse" span, so time
+// spent decoding shows up apart from the HTTP call.
+func DecodeJSON(ctx context.Context, source string, data []byte, v any) error {
+	_, span := Start(ctx, "parse", Source.String(source), Bytes.Int(len(data)))
+	defer span.End()
+
+	if err := json.Unmarshal(data, v); err != nil {
+		Fail(span, err)
+		return err
+	}
+	return nil
+}
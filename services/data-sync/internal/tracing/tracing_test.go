@@ -0,0 +1,76 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSetup_FileExporter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"ok"}`))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "traces.json")
+	shutdown, err := Setup(context.Background(), ExporterFile, path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, span := Start(context.Background(), "sync.run", Source.String("test"))
+	client := &http.Client{Transport: Transport("test", nil)}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	var decoded struct{ Status string }
+	if err := DecodeJSON(ctx, "test", []byte(`{"status":"ok"}`), &decoded); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	span.End()
+
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("failed to shut down: %v", err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("expected a second shutdown to be a no-op, got %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read trace file: %v", err)
+	}
+	out := string(data)
+
+	for _, want := range []string{`"Name":"sync.run"`, `"Name":"parse"`, `"Name":"GET 127.0.0.1`, `"Value":"data-sync"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected trace file to contain %s, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestSetup_Disabled(t *testing.T) {
+	for _, exporter := range []string{"", ExporterNone} {
+		shutdown, err := Setup(context.Background(), exporter, "")
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", exporter, err)
+			continue
+		}
+		if err := shutdown(context.Background()); err != nil {
+			t.Errorf("%q: unexpected shutdown error: %v", exporter, err)
+		}
+	}
+}
+
+func TestSetup_UnknownExporter(t *testing.T) {
+	if _, err := Setup(context.Background(), "jaeger", ""); err == nil {
+		t.Error("expected an error for an unknown exporter")
+	}
+}
@@ -0,0 +1,27 @@
+// Package upstream builds the HTTP transport the source API clients send
+// their requests through.
+package upstream
+
+import (
+	"net/http"
+
+	"github.com/dealforge/data-sync/internal/archive"
+	"github.com/dealforge/data-sync/internal/breaker"
+	"github.com/dealforge/data-sync/internal/httpretry"
+	"github.com/dealforge/data-sync/internal/metrics"
+	"github.com/dealforge/data-sync/internal/ratelimit"
+	"github.com/dealforge/data-sync/internal/tracing"
+)
+
+// Transport returns the transport of a source's API client. Every attempt
+// passes the source's circuit breaker, waits for the limiter, and is traced
+// and measured; failed attempts are retried under the policy. The final
+// response is archived, or served from the archive offline.
+func Transport(source string, limiter *ratelimit.Limiter, policy httpretry.Policy) http.RoundTripper {
+	var transport http.RoundTripper = metrics.Transport(source, nil)
+	transport = tracing.Transport(source, transport)
+	transport = ratelimit.Transport(limiter, transport)
+	transport = breaker.Transport(breaker.New(source, breaker.DefaultThreshold, breaker.DefaultCooldown), transport)
+	transport = httpretry.Transport(source, policy, transport)
+	return archive.Transport(source, transport)
+}
@@ -0,0 +1,304 @@
+// Package worker runs data syncs queued by the web app in the jobs table.
+//
+// Each registered source has a job type named after it (e.g., "bls_sync").
+// The worker claims pending jobs of those types, runs the matching source
+// through the orchestrator, and heartbeats progress into the job's result.
+// An admin cancelling a running job cancels the sync.
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dealforge/data-sync/internal/db"
+	"github.com/dealforge/data-sync/internal/geography"
+	"github.com/dealforge/data-sync/internal/sources"
+	datasync "github.com/dealforge/data-sync/internal/sync"
+)
+
+// Job statuses written by the worker. A sync that returns without an error
+// finishes with the status it records in the sync run ledger.
+const (
+	StatusCompleted   = db.RunCompleted
+	StatusPartial     = db.RunPartial
+	StatusRateLimited = db.RunRateLimited
+	StatusUnavailable = db.RunUnavailable
+	StatusFailed      = db.RunFailed
+	StatusCancelled   = db.RunCancelled
+)
+
+// Default intervals.
+const (
+	DefaultPollInterval      = 10 * time.Second
+	DefaultHeartbeatInterval = 5 * time.Second
+	DefaultStaleAfter        = 2 * time.Minute
+)
+
+// finishTimeout bounds the final job update, which runs even if the
+// worker's context has been cancelled.
+const finishTimeout = 10 * time.Second
+
+// JobType returns the job type that runs the named source.
+func JobType(source string) string {
+	return source + "_sync"
+}
+
+// JobParams is the parameters payload of a sync job. Zero values fall back to
+// the same defaults as the command line.
+type JobParams struct {
+	States          []string `json:"states"`     // Defaults to Texas
+	ZIPs            []string `json:"zips"`       // HUD Small Area FMRs
+	Counties        []string `json:"counties"`   // Census tracts and ZCTAs
+	GeoLevel        string   `json:"geo_level"`  // Census geography level
+	Year            int      `json:"year"`       // Census survey year
+	StartYear       int      `json:"start_year"` // BLS range start
+	EndYear         int      `json:"end_year"`   // BLS range end
+	DryRun          bool     `json:"dry_run"`
+	ResumeSessionID string   `json:"resume_session_id"`
+}
+
+// JobResult is the result payload of a sync job. Progress is heartbeated
+// while the job runs; Result is set when it finishes.
+type JobResult struct {
+	Progress datasync.Progress    `json:"progress"`
+	Result   *datasync.SyncResult `json:"result,omitempty"`
+}
+
+// ParseJobParams decodes a job's parameters into source parameters.
+func ParseJobParams(raw json.RawMessage) (JobParams, sources.Params, error) {
+	var jp JobParams
+	if len(raw) > 0 && string(raw) != "null" {
+		if err := json.Unmarshal(raw, &jp); err != nil {
+			return JobParams{}, sources.Params{}, fmt.Errorf("invalid job parameters: %w", err)
+		}
+	}
+
+	stateList := strings.Join(jp.States, ",")
+	if stateList == "" {
+		stateList = sources.DefaultState
+	}
+	states, err := geography.ParseStateList(stateList)
+	if err != nil {
+		return JobParams{}, sources.Params{}, err
+	}
+
+	zips, err := geography.ParseZIPList(strings.Join(jp.ZIPs, ","))
+	if err != nil {
+		return JobParams{}, sources.Params{}, err
+	}
+	if len(jp.ZIPs) > 0 && len(zips) == 0 {
+		return JobParams{}, sources.Params{}, fmt.Errorf("zips lists no ZIP codes")
+	}
+
+	counties, err := geography.ParseCountyList(strings.Join(jp.Counties, ","))
+	if err != nil {
+		return JobParams{}, sources.Params{}, err
+	}
+
+	geoLevel := jp.GeoLevel
+	if geoLevel == "" {
+		geoLevel = "county"
+	}
+
+	params := sources.Params{
+		States:    states,
+		ZIPs:      zips,
+		Counties:  counties,
+		GeoLevel:  geoLevel,
+		Year:      jp.Year,
+		StartYear: jp.StartYear,
+		EndYear:   jp.EndYear,
+	}
+	return jp, params, nil
+}
+
+// Worker claims and runs sync jobs, one at a time.
+type Worker struct {
+	db            *db.Client
+	sources       map[string]sources.Source // Keyed by job type
+	maxConcurrent int
+
+	PollInterval      time.Duration // How often to look for pending jobs when idle
+	HeartbeatInterval time.Duration // How often to write progress into a running job
+	StaleAfter        time.Duration // How long a running job goes without a heartbeat before it is reclaimed
+}
+
+// New creates a worker for the given sources. The sources are built once and
+// reused by every job.
+func New(dbClient *db.Client, srcs []sources.Source, maxConcurrent int) *Worker {
+	byType := make(map[string]sources.Source, len(srcs))
+	for _, src := range srcs {
+		byType[JobType(src.Name())] = src
+	}
+
+	return &Worker{
+		db:                dbClient,
+		sources:           byType,
+		maxConcurrent:     maxConcurrent,
+		PollInterval:      DefaultPollInterval,
+		HeartbeatInterval: DefaultHeartbeatInterval,
+		StaleAfter:        DefaultStaleAfter,
+	}
+}
+
+// JobTypes returns the job types this worker claims.
+func (w *Worker) JobTypes() []string {
+	types := make([]string, 0, len(w.sources))
+	for jobType := range w.sources {
+		types = append(types, jobType)
+	}
+	return types
+}
+
+// Run claims and runs jobs until ctx is cancelled.
+func (w *Worker) Run(ctx context.Context) error {
+	types := w.JobTypes()
+	slog.Info("worker started", "job_types", types, "poll_interval", w.PollInterval)
+
+	for {
+		job, err := w.db.ClaimJob(ctx, types, w.StaleAfter)
+		if err != nil && ctx.Err() == nil {
+			slog.Error("failed to claim job", "error", err)
+		}
+
+		if job != nil {
+			w.runJob(ctx, job)
+			continue // Look for the next job straight away
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(w.PollInterval):
+		}
+	}
+}
+
+// runJob runs a claimed job and records its outcome.
+func (w *Worker) runJob(ctx context.Context, job *db.Job) {
+	logger := slog.With("job_id", job.ID, "job_type", job.Type)
+	logger.Info("running job", "reclaimed", job.Reclaimed)
+
+	src, ok := w.sources[job.Type]
+	if !ok {
+		w.finish(logger, job, StatusFailed, JobResult{}, fmt.Errorf("no source configured for job type %q", job.Type))
+		return
+	}
+
+	jp, params, err := ParseJobParams(job.Parameters)
+	if err != nil {
+		w.finish(logger, job, StatusFailed, JobResult{}, err)
+		return
+	}
+	// Pick up where the dead worker left off
+	if job.Reclaimed && jp.ResumeSessionID == "" {
+		jp.ResumeSessionID = datasync.ResumeLatest
+	}
+
+	jobCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu     sync.Mutex
+		latest = datasync.Progress{Source: src.Name()}
+	)
+
+	orch := datasync.NewOrchestrator(w.db, w.maxConcurrent, jp.DryRun)
+	orch.OnProgress(func(p datasync.Progress) {
+		mu.Lock()
+		latest = p
+		mu.Unlock()
+	})
+
+	// Heartbeat progress until the sync returns, cancelling it if the job
+	// stops being running
+	done := make(chan struct{})
+	heartbeatDone := make(chan struct{})
+	cancelled := false // Written by the heartbeat goroutine before heartbeatDone closes
+	go func() {
+		defer close(heartbeatDone)
+
+		ticker := time.NewTicker(w.HeartbeatInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-jobCtx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			mu.Lock()
+			p := latest
+			mu.Unlock()
+
+			running, err := w.db.HeartbeatJob(jobCtx, job.ID, JobResult{Progress: p})
+			if err != nil {
+				logger.Warn("failed to heartbeat job", "error", err)
+				continue
+			}
+			if !running {
+				logger.Info("job is no longer running, cancelling sync")
+				cancelled = true
+				cancel()
+				return
+			}
+		}
+	}()
+
+	result, syncErr := orch.Sync(jobCtx, src, params, jp.ResumeSessionID)
+	close(done)
+	<-heartbeatDone
+
+	mu.Lock()
+	final := JobResult{Progress: latest, Result: result}
+	mu.Unlock()
+
+	status, jobErr := jobOutcome(ctx, cancelled, result, syncErr)
+	w.finish(logger, job, status, final, jobErr)
+}
+
+// jobOutcome returns the final status of a job and the error recorded with
+// it. A sync that returns without an error is classified like its sync run,
+// so a partial, rate-limited or interrupted sync is not reported as
+// completed.
+func jobOutcome(workerCtx context.Context, cancelled bool, result *datasync.SyncResult, syncErr error) (string, error) {
+	switch {
+	case cancelled:
+		return StatusCancelled, nil
+	case workerCtx.Err() != nil:
+		return StatusFailed, fmt.Errorf("worker stopped before the job finished")
+	case syncErr != nil:
+		return StatusFailed, syncErr
+	default:
+		return datasync.RunStatus(workerCtx, result, nil), nil
+	}
+}
+
+// finish records a job's final status. The update uses a fresh context so
+// the outcome is saved even when the worker is shutting down.
+func (w *Worker) finish(logger *slog.Logger, job *db.Job, status string, result JobResult, jobErr error) {
+	var errorMessage *string
+	if jobErr != nil {
+		msg := jobErr.Error()
+		errorMessage = &msg
+		logger.Error("job failed", "error", jobErr)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), finishTimeout)
+	defer cancel()
+
+	if err := w.db.FinishJob(ctx, job.ID, status, result, errorMessage); err != nil {
+		logger.Error("failed to record job outcome", "status", status, "error", err)
+		return
+	}
+
+	logger.Info("job finished", "status", status)
+}
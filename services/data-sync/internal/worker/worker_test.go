@@ -0,0 +1,142 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/dealforge/data-sync/internal/db"
+	"github.com/dealforge/data-sync/internal/sources"
+	datasync "github.com/dealforge/data-sync/internal/sync"
+)
+
+type stubSource struct{ name string }
+
+func (s stubSource) Name() string             { return s.name }
+func (s stubSource) RequiredConfig() []string { return nil }
+func (s stubSource) WorkUnits(ctx context.Context, p sources.Params) ([]sources.WorkUnit, error) {
+	return nil, nil
+}
+func (s stubSource) Fetch(ctx context.Context, unit sources.WorkUnit, p sources.Params) (sources.Records, error) {
+	return nil, nil
+}
+func (s stubSource) Persist(ctx context.Context, store *db.Client, records sources.Records) error {
+	return nil
+}
+
+func TestJobTypes(t *testing.T) {
+	w := New(nil, []sources.Source{stubSource{"hud"}, stubSource{"census"}, stubSource{"bls"}}, 1)
+
+	types := w.JobTypes()
+	sort.Strings(types)
+
+	want := []string{"bls_sync", "census_sync", "hud_sync"}
+	if len(types) != len(want) {
+		t.Fatalf("expected job types %v, got %v", want, types)
+	}
+	for i := range want {
+		if types[i] != want[i] {
+			t.Errorf("expected job types %v, got %v", want, types)
+			break
+		}
+	}
+}
+
+func TestParseJobParams_Defaults(t *testing.T) {
+	for _, raw := range []string{"", "null", "{}"} {
+		jp, params, err := ParseJobParams(json.RawMessage(raw))
+		if err != nil {
+			t.Fatalf("parameters %q: unexpected error: %v", raw, err)
+		}
+		if jp.DryRun || jp.ResumeSessionID != "" {
+			t.Errorf("parameters %q: expected zero job options, got %+v", raw, jp)
+		}
+		if len(params.States) != 1 || params.States[0].Code != sources.DefaultState {
+			t.Errorf("parameters %q: expected default state %s, got %v", raw, sources.DefaultState, params.States)
+		}
+		if params.GeoLevel != "county" {
+			t.Errorf("parameters %q: expected county geography, got %q", raw, params.GeoLevel)
+		}
+	}
+}
+
+func TestParseJobParams(t *testing.T) {
+	raw := `{
+		"states": ["tx", "OK"],
+		"zips": ["78201", "78259"],
+		"counties": ["48029"],
+		"geo_level": "tract",
+		"year": 2022,
+		"start_year": 2021,
+		"end_year": 2024,
+		"dry_run": true,
+		"resume_session_id": "bls_1700000000"
+	}`
+
+	jp, params, err := ParseJobParams(json.RawMessage(raw))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !jp.DryRun || jp.ResumeSessionID != "bls_1700000000" {
+		t.Errorf("unexpected job options: %+v", jp)
+	}
+	if len(params.States) != 2 || params.States[0].Code != "TX" || params.States[1].Code != "OK" {
+		t.Errorf("expected states TX,OK, got %v", params.States)
+	}
+	if len(params.ZIPs) != 2 || len(params.Counties) != 1 || params.GeoLevel != "tract" {
+		t.Errorf("unexpected geography params: %+v", params)
+	}
+	if params.Year != 2022 || params.StartYear != 2021 || params.EndYear != 2024 {
+		t.Errorf("unexpected year params: %+v", params)
+	}
+}
+
+func TestParseJobParams_Invalid(t *testing.T) {
+	for _, raw := range []string{
+		`{"states": ["ZZ"]}`,
+		`{"counties": ["480"]}`,
+		`{"zips": ["ABCDE"]}`,
+		`{"zips": [" "]}`,
+		`{"states": "TX"}`,
+	} {
+		if _, _, err := ParseJobParams(json.RawMessage(raw)); err == nil {
+			t.Errorf("parameters %s: expected an error", raw)
+		}
+	}
+}
+
+func TestJobOutcome(t *testing.T) {
+	running := context.Background()
+	stopped, stop := context.WithCancel(context.Background())
+	stop()
+
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		cancelled bool
+		result    *datasync.SyncResult
+		syncErr   error
+		expected  string
+	}{
+		{"completed", running, false, &datasync.SyncResult{}, nil, StatusCompleted},
+		{"failed units", running, false, &datasync.SyncResult{Failed: 2}, nil, StatusPartial},
+		{"rate limited", running, false, &datasync.SyncResult{RateLimited: true}, nil, StatusRateLimited},
+		{"upstream unavailable", running, false, &datasync.SyncResult{Unavailable: true}, nil, StatusUnavailable},
+		{"sync error", running, false, nil, errors.New("boom"), StatusFailed},
+		{"cancelled by admin", running, true, &datasync.SyncResult{}, nil, StatusCancelled},
+		{"worker stopped", stopped, false, &datasync.SyncResult{}, nil, StatusFailed},
+	}
+
+	for _, tt := range tests {
+		status, err := jobOutcome(tt.ctx, tt.cancelled, tt.result, tt.syncErr)
+		if status != tt.expected {
+			t.Errorf("%s: expected status %s, got %s", tt.name, tt.expected, status)
+		}
+		if (err != nil) != (tt.expected == StatusFailed) {
+			t.Errorf("%s: expected an error only for failed jobs, got %v", tt.name, err)
+		}
+	}
+}